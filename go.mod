@@ -6,6 +6,7 @@ require (
 	github.com/alecthomas/units v0.0.0-20201120081800-1786d5ef83d4 // indirect
 	github.com/go-logr/logr v0.4.0
 	github.com/google/go-cmp v0.5.6
+	github.com/google/go-containerregistry v0.4.1-0.20210128200529-19c2b639fab1
 	github.com/grpc-ecosystem/grpc-gateway v1.16.0 // indirect
 	github.com/manifestival/controller-runtime-client v0.4.0
 	github.com/manifestival/manifestival v0.7.0
@@ -20,6 +21,7 @@ require (
 	github.com/spf13/pflag v1.0.5
 	go.uber.org/zap v1.17.0
 	k8s.io/api v0.20.6
+	k8s.io/apiextensions-apiserver v0.20.6
 	k8s.io/apimachinery v0.20.6
 	k8s.io/client-go v12.0.0+incompatible
 	knative.dev/eventing v0.22.1
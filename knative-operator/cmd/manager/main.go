@@ -1,21 +1,29 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
 
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller"
+	kafkacontroller "github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/knativekafka"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/knativeserving/quickstart"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/certs"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/knativeeventing"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/knativekafka"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/knativeserving"
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -27,13 +35,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-// Change below variables to serve metrics on different host or port.
-var (
-	metricsHost       = "0.0.0.0"
-	metricsPort int32 = 8383
-	healthPort  int32 = 8687
-	log               = logf.Log.WithName("cmd")
-)
+// leaderElectionID is the name of the lock the replicas compete for.
+const leaderElectionID = "knative-serving-openshift-lock"
+
+var log = logf.Log.WithName("cmd")
 
 func init() {
 	prodConf := zap.NewProductionEncoderConfig()
@@ -42,11 +47,19 @@ func init() {
 }
 
 func main() {
+	opts := &options{}
+	opts.addFlags(pflag.CommandLine)
 	// Add flags registered by imported packages (e.g. glog and
 	// controller-runtime)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 
+	certSecret, err := opts.certSecret()
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
 	// Get a config to talk to the apiserver
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -54,23 +67,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	kube, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		log.Error(err, "failed to create a kube client")
+		os.Exit(1)
+	}
+
+	// The namespace of the pod, where the manager competes for the lock. Empty outside of
+	// the cluster.
+	namespace := os.Getenv(common.NamespaceEnvKey)
+
 	// Create a new Cmd to provide shared dependencies and start components
 	mgr, err := manager.New(cfg, manager.Options{
 		Namespace:              "", // The serverless operator always watches all namespaces.
 		LeaderElection:         true,
-		LeaderElectionID:       "knative-serving-openshift-lock",
-		MetricsBindAddress:     fmt.Sprintf("%s:%d", metricsHost, metricsPort),
-		HealthProbeBindAddress: fmt.Sprintf(":%d", healthPort),
+		LeaderElectionID:       leaderElectionID,
+		MetricsBindAddress:     opts.metricsAddr,
+		HealthProbeBindAddress: opts.healthAddr,
 	})
 	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
-	// Add readiness probe
-	if err := mgr.AddReadyzCheck("ready-ping", healthz.Ping); err != nil {
-		log.Error(err, "unable to add a readiness check")
-		os.Exit(1)
+	// Add readiness probes, OLM only marks the operator as succeeded once they pass
+	readyChecks := map[string]healthz.Checker{
+		"ready-ping": healthz.Ping,
+		"cache-sync": common.CacheSyncedCheck(mgr.GetCache()),
+		"webhook":    common.WebhookCheck(net.JoinHostPort("localhost", strconv.Itoa(opts.webhookPort))),
+		"manifests":  common.ManifestsCheck(kafkacontroller.ChannelManifestPathEnvKey, kafkacontroller.SourceManifestPathEnvKey, quickstart.EnvKey),
+	}
+	if namespace != "" {
+		// The check reads the lock the manager competes for, of the manager's default type.
+		lock, err := resourcelock.New(resourcelock.ConfigMapsLeasesResourceLock, namespace, leaderElectionID,
+			kube.CoreV1(), kube.CoordinationV1(), resourcelock.ResourceLockConfig{})
+		if err != nil {
+			log.Error(err, "failed to create the leader election lock")
+			os.Exit(1)
+		}
+		readyChecks["leader-lock"] = common.LeaderCheck(mgr.Elected(), lock)
+	}
+	for name, check := range readyChecks {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			log.Error(err, "unable to add a readiness check", "check", name)
+			os.Exit(1)
+		}
 	}
 
 	// Add liveness probe
@@ -79,6 +120,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Serve the health of the Knative components next to the metrics
+	if err := mgr.AddMetricsExtraHandler("/status", common.StatusHandler(mgr.GetClient())); err != nil {
+		log.Error(err, "unable to add the status endpoint")
+		os.Exit(1)
+	}
+
 	log.Info("Registering Components.")
 
 	// Setup Scheme for all resources
@@ -95,10 +142,36 @@ func main() {
 
 	// Setup all Webhooks
 	hookServer := mgr.GetWebhookServer()
-	hookServer.Port = 9876
-	hookServer.CertDir = "/apiserver.local.config/certificates"
-	hookServer.KeyName = "apiserver.key"
-	hookServer.CertName = "apiserver.crt"
+	hookServer.Port = opts.webhookPort
+	hookServer.CertDir = opts.webhookCertDir
+	hookServer.KeyName = opts.webhookKeyName
+	hookServer.CertName = opts.webhookCertName
+
+	// Keep the certificate of the Secret in the certificate directory, the webhook server
+	// reloads it from there.
+	if certSecret != nil {
+		writer := &certs.SecretWriter{
+			Kube:     kube,
+			Secret:   *certSecret,
+			Dir:      opts.webhookCertDir,
+			CertName: opts.webhookCertName,
+			KeyName:  opts.webhookKeyName,
+		}
+		if err := writer.Write(context.Background()); err != nil {
+			log.Error(err, "failed to write the webhook certificate")
+			os.Exit(1)
+		}
+		if err := mgr.Add(writer); err != nil {
+			log.Error(err, "failed to add the webhook certificate writer")
+			os.Exit(1)
+		}
+	}
+
+	catalog, err := common.ImageCatalogCache(mgr)
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
 
 	decoder, err := admission.NewDecoder(mgr.GetScheme())
 	if err != nil {
@@ -107,10 +180,10 @@ func main() {
 	}
 
 	// Serving Webhooks
-	hookServer.Register("/mutate-knativeservings", &webhook.Admission{Handler: knativeserving.NewConfigurator(mgr.GetClient(), decoder)})
+	hookServer.Register("/mutate-knativeservings", &webhook.Admission{Handler: knativeserving.NewConfigurator(mgr.GetClient(), catalog, decoder)})
 	hookServer.Register("/validate-knativeservings", &webhook.Admission{Handler: knativeserving.NewValidator(mgr.GetClient(), decoder)})
 	// Eventing Webhooks
-	hookServer.Register("/mutate-knativeeventings", &webhook.Admission{Handler: knativeeventing.NewConfigurator(decoder)})
+	hookServer.Register("/mutate-knativeeventings", &webhook.Admission{Handler: knativeeventing.NewConfigurator(catalog, decoder)})
 	hookServer.Register("/validate-knativeeventings", &webhook.Admission{Handler: knativeeventing.NewValidator(mgr.GetClient(), decoder)})
 	// Kafka Webhooks
 	hookServer.Register("/mutate-knativekafkas", &webhook.Admission{Handler: knativekafka.NewConfigurator(decoder)})
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// options configure the servers of the manager. Each flag defaults to an environment
+// variable, so that the deployment can be configured either way. The defaults match the
+// deployment OLM creates.
+type options struct {
+	metricsAddr string
+	healthAddr  string
+
+	webhookPort     int
+	webhookCertDir  string
+	webhookCertName string
+	webhookKeyName  string
+	// webhookCertSecret is the namespace/name of a TLS Secret whose certificate is written to
+	// webhookCertDir, if set.
+	webhookCertSecret string
+}
+
+func (o *options) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.metricsAddr, "metrics-bind-address", env("METRICS_BIND_ADDRESS", "0.0.0.0:8383"),
+		"The address the metrics endpoint binds to.")
+	fs.StringVar(&o.healthAddr, "health-probe-bind-address", env("HEALTH_PROBE_BIND_ADDRESS", ":8687"),
+		"The address the health probe endpoints bind to.")
+	fs.IntVar(&o.webhookPort, "webhook-port", envInt("WEBHOOK_PORT", 9876),
+		"The port the webhook server serves on.")
+	fs.StringVar(&o.webhookCertDir, "webhook-cert-dir", env("WEBHOOK_CERT_DIR", "/apiserver.local.config/certificates"),
+		"The directory of the webhook server's certificate and key. Changes to them are picked up without a restart.")
+	fs.StringVar(&o.webhookCertName, "webhook-cert-name", env("WEBHOOK_CERT_NAME", "apiserver.crt"),
+		"The file name of the webhook server's certificate.")
+	fs.StringVar(&o.webhookKeyName, "webhook-key-name", env("WEBHOOK_KEY_NAME", "apiserver.key"),
+		"The file name of the webhook server's key.")
+	fs.StringVar(&o.webhookCertSecret, "webhook-cert-secret", env("WEBHOOK_CERT_SECRET", ""),
+		"The namespace/name of a TLS Secret, like one of the service CA or cert-manager, to write the webhook certificate from. "+
+			"It's kept up to date in the certificate directory, which has to be writable.")
+}
+
+// certSecret returns the name of the webhook certificate's Secret, if any.
+func (o *options) certSecret() (*types.NamespacedName, error) {
+	if o.webhookCertSecret == "" {
+		return nil, nil
+	}
+	parts := strings.Split(o.webhookCertSecret, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("webhook certificate secret %q is not of the form namespace/name", o.webhookCertSecret)
+	}
+	return &types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// env returns the value of the environment variable or the fallback if it's unset.
+func env(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// envInt returns the numeric value of the environment variable or the fallback if it's unset
+// or not a number.
+func envInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,146 @@
+// Command validate dry-runs the validation of the operator's webhooks against the cluster.
+// It reads KnativeServing, KnativeEventing and KnativeKafka CRs from the given files and
+// prints why they would be denied and the warnings about them.
+//
+//	validate -f knative-serving.yaml [-f knative-eventing.yaml ...]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/knativeeventing"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/knativekafka"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/knativeserving"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/validation"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// rules returns the rules of the CRs by kind.
+func rules(c client.Reader) map[string][]validation.Rule {
+	return map[string][]validation.Rule{
+		"KnativeServing":  knativeserving.Rules(c),
+		"KnativeEventing": knativeeventing.Rules(c),
+		"KnativeKafka":    knativekafka.Rules(c),
+	}
+}
+
+func main() {
+	var files []string
+	pflag.StringArrayVarP(&files, "filename", "f", nil, "file containing the CRs to validate, - for stdin")
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "at least one file has to be given with -f")
+		os.Exit(2)
+	}
+
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to set up the scheme:", err)
+		os.Exit(1)
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to get the cluster's config:", err)
+		os.Exit(1)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create a client:", err)
+		os.Exit(1)
+	}
+
+	allowed := true
+	for _, file := range files {
+		ok, err := validateFile(context.Background(), file, rules(c), os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to validate %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		allowed = allowed && ok
+	}
+	if !allowed {
+		os.Exit(1)
+	}
+}
+
+// validateFile validates all CRs in the given file and returns true if all of them are allowed.
+func validateFile(ctx context.Context, file string, rules map[string][]validation.Rule, out io.Writer) (bool, error) {
+	in := os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return false, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	allowed := true
+	decoder := yaml.NewYAMLOrJSONDecoder(in, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); errors.Is(err, io.EOF) {
+			return allowed, nil
+		} else if err != nil {
+			return false, fmt.Errorf("failed to decode: %w", err)
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+
+		kindRules, ok := rules[u.GetKind()]
+		if !ok {
+			fmt.Fprintf(out, "%s %s/%s: skipped, not validated by the operator\n", u.GetKind(), u.GetNamespace(), u.GetName())
+			continue
+		}
+		obj, err := typed(u)
+		if err != nil {
+			return false, err
+		}
+		result, err := validation.Validate(ctx, obj, kindRules...)
+		if err != nil {
+			return false, fmt.Errorf("failed to validate %s %s/%s: %w", u.GetKind(), u.GetNamespace(), u.GetName(), err)
+		}
+		report(out, u, result)
+		allowed = allowed && result.Allowed()
+	}
+}
+
+// typed converts the CR to its typed object, which the rules expect.
+func typed(u *unstructured.Unstructured) (client.Object, error) {
+	obj, err := scheme.Scheme.New(u.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return nil, fmt.Errorf("failed to convert %s %s/%s: %w", u.GetKind(), u.GetNamespace(), u.GetName(), err)
+	}
+	return obj.(client.Object), nil
+}
+
+// report prints the verdict, the denials and the warnings of the CR.
+func report(out io.Writer, u *unstructured.Unstructured, result validation.Result) {
+	verdict := "allowed"
+	if !result.Allowed() {
+		verdict = "denied"
+	}
+	fmt.Fprintf(out, "%s %s/%s: %s\n", u.GetKind(), u.GetNamespace(), u.GetName(), verdict)
+	for _, denial := range result.Denials {
+		fmt.Fprintf(out, "  denied: %s\n", denial)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(out, "  warning: %s\n", warning)
+	}
+}
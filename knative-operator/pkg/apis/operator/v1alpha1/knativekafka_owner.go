@@ -0,0 +1,14 @@
+package v1alpha1
+
+// Annotation keys tagging the resources a KnativeKafka owns outside of its namespace, where
+// owner references can't be used.
+const (
+	OwnerNameAnnotation      = "knativekafkas.operator.serverless.openshift.io/ownerName"
+	OwnerNamespaceAnnotation = "knativekafkas.operator.serverless.openshift.io/ownerNamespace"
+)
+
+// OwnerAnnotationKeys returns the keys of the annotations referencing the KnativeKafka's name
+// and namespace.
+func (kk *KnativeKafka) OwnerAnnotationKeys() (name, namespace string) {
+	return OwnerNameAnnotation, OwnerNamespaceAnnotation
+}
@@ -1,5 +1,7 @@
 package common
 
+import "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+
 // Annotation keys being used to tag the owned resources by instance
 const (
 	ServingOwnerName                 = "serving.knative.openshift.io/ownerName"
@@ -8,8 +10,8 @@ const (
 	EventingOwnerNamespace           = "eventing.knative.openshift.io/ownerNamespace"
 	ServerlessOperatorOwnerName      = "operator.knative.openshift.io/ownerName"
 	ServerlessOperatorOwnerNamespace = "operator.knative.openshift.io/ownerNamespace"
-	KafkaOwnerName                   = "knativekafkas.operator.serverless.openshift.io/ownerName"
-	KafkaOwnerNamespace              = "knativekafkas.operator.serverless.openshift.io/ownerNamespace"
+	KafkaOwnerName                   = v1alpha1.OwnerNameAnnotation
+	KafkaOwnerNamespace              = v1alpha1.OwnerNamespaceAnnotation
 
 	// The namespace of the pod will be available through this key.
 	NamespaceEnvKey = "NAMESPACE"
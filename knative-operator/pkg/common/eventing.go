@@ -1,18 +1,29 @@
 package common
 
 import (
-	"os"
+	"context"
+	"fmt"
 
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
+	"github.com/openshift-knative/serverless-operator/pkg/images"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	eventingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 )
 
-func MutateEventing(ke *eventingv1alpha1.KnativeEventing) {
-	eventingImagesFromEnviron(ke)
+// MutateEventing applies the OpenShift specific defaults to the given KnativeEventing. The
+// image catalog is read through the given reader.
+func MutateEventing(ke *eventingv1alpha1.KnativeEventing, catalog client.Reader) error {
+	if err := eventingImagesFromCatalog(ke, catalog); err != nil {
+		return fmt.Errorf("failed to resolve images: %w", err)
+	}
 	ensureEventingWebhookMemoryLimit(ke)
 	ensureEventingWebhookInclusionMode(ke)
 	defaultToEventingHa(ke)
+	return nil
 }
 
 func defaultToEventingHa(ke *eventingv1alpha1.KnativeEventing) {
@@ -23,15 +34,20 @@ func defaultToEventingHa(ke *eventingv1alpha1.KnativeEventing) {
 	}
 }
 
-// eventingImagesFromEnviron overrides registry images
-func eventingImagesFromEnviron(ke *eventingv1alpha1.KnativeEventing) {
-	ke.Spec.Registry.Override = BuildImageOverrideMapFromEnviron(os.Environ(), "IMAGE_")
+// eventingImagesFromCatalog overrides registry images
+func eventingImagesFromCatalog(ke *eventingv1alpha1.KnativeEventing, r client.Reader) error {
+	catalog, _, err := ImageCatalog(r, images.Prefix)
+	if err != nil {
+		return err
+	}
+	ke.Spec.Registry.Override = catalog
 
-	if defaultVal, ok := ke.Spec.Registry.Override["default"]; ok {
+	if defaultVal, ok := ke.Spec.Registry.Override[images.DefaultKey]; ok {
 		ke.Spec.Registry.Default = defaultVal
 	}
 
 	log.Info("Setting", "registry", ke.Spec.Registry)
+	return nil
 }
 
 func ensureEventingWebhookMemoryLimit(ke *eventingv1alpha1.KnativeEventing) {
@@ -43,3 +59,24 @@ func ensureEventingWebhookInclusionMode(ke *eventingv1alpha1.KnativeEventing) {
 		ke.Spec.SinkBindingSelectionMode = "inclusion"
 	}
 }
+
+// KnativeEventingMonitoring returns the cluster's KnativeEventing, which is nil if there's
+// none, and its monitoring settings. The settings in spec.openshift aren't part of the
+// upstream type, so the KnativeEventing is read as unstructured through the given reader,
+// usually the manager's cache.
+func KnativeEventingMonitoring(ctx context.Context, r client.Reader) (*eventingv1alpha1.KnativeEventing, monitoring.Spec, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(eventingv1alpha1.SchemeGroupVersion.WithKind("KnativeEventingList"))
+	if err := r.List(ctx, list); err != nil {
+		return nil, monitoring.Spec{}, fmt.Errorf("failed to list KnativeEventings: %w", err)
+	}
+	if len(list.Items) == 0 {
+		return nil, monitoring.Spec{}, nil
+	}
+	ke := &eventingv1alpha1.KnativeEventing{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[0].Object, ke); err != nil {
+		return nil, monitoring.Spec{}, fmt.Errorf("failed to convert KnativeEventing: %w", err)
+	}
+	spec, err := monitoring.SpecFrom(&list.Items[0])
+	return ke, spec, err
+}
@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/yaml"
 )
 
@@ -30,7 +31,9 @@ func TestMutateEventing(t *testing.T) {
 	os.Setenv("IMAGE_bar__baz", image2)
 
 	// Mutate for OpenShift
-	common.MutateEventing(ke)
+	if err := common.MutateEventing(ke, fake.NewClientBuilder().Build()); err != nil {
+		t.Fatal(err)
+	}
 	verifyEventingHA(t, ke, 2)
 	verifyImageOverride(t, &ke.Spec.Registry, "foo", image1)
 	verifyImageOverride(t, &ke.Spec.Registry, "bar/baz", image2)
@@ -57,7 +60,9 @@ func TestEventingWebhookMemoryLimit(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.Input.Name, func(t *testing.T) {
-			common.MutateEventing(&test.Input)
+			if err := common.MutateEventing(&test.Input, fake.NewClientBuilder().Build()); err != nil {
+				t.Fatal(err)
+			}
 			if !cmp.Equal(test.Input.Spec.Resources, test.Expected, cmpopts.IgnoreUnexported(resource.Quantity{})) {
 				t.Errorf("Resources not as expected, diff: %s", cmp.Diff(test.Expected, test.Input.Spec.Resources))
 			}
@@ -108,7 +113,9 @@ func TestEventingWebhookInclusionMode(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			common.MutateEventing(tc.ke)
+			if err := common.MutateEventing(tc.ke, fake.NewClientBuilder().Build()); err != nil {
+				t.Fatal(err)
+			}
 			if tc.ke.Spec.SinkBindingSelectionMode != tc.wanted {
 				t.Errorf(`Name: %s\n Expected "%s", Got: "%s"`, tc.name, tc.wanted, tc.ke.Spec.SinkBindingSelectionMode)
 			}
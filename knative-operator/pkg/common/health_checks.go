@@ -0,0 +1,112 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	mf "github.com/manifestival/manifestival"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// checkTimeout bounds the checks that wait for something, probes time out after a second
+// by default.
+const checkTimeout = 500 * time.Millisecond
+
+// CacheSyncer is implemented by the manager's cache.
+type CacheSyncer interface {
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+// CacheSyncedCheck passes once the informer caches are synced, the controllers can't
+// reconcile before.
+func CacheSyncedCheck(c CacheSyncer) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches are not synced")
+		}
+		return nil
+	}
+}
+
+// WebhookCheck passes if the webhook server at the given address serves a certificate
+// that's currently valid.
+func WebhookCheck(addr string) healthz.Checker {
+	return func(*http.Request) error {
+		dialer := &net.Dialer{Timeout: checkTimeout}
+		// The certificate is signed for the webhook's service, the API server verifies it.
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return fmt.Errorf("webhook server is not serving: %w", err)
+		}
+		defer conn.Close()
+
+		certs := conn.ConnectionState().PeerCertificates
+		if len(certs) == 0 {
+			return errors.New("webhook server serves no certificate")
+		}
+		now := time.Now()
+		if cert := certs[0]; now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fmt.Errorf("webhook certificate is only valid from %v to %v", cert.NotBefore, cert.NotAfter)
+		}
+		return nil
+	}
+}
+
+// LeaderCheck passes if this replica is the leader or another replica holds a lease that
+// it keeps renewing. Standby replicas serve the webhooks, so they're ready as long as some
+// replica reconciles.
+func LeaderCheck(elected <-chan struct{}, lock resourcelock.Interface) healthz.Checker {
+	return func(req *http.Request) error {
+		select {
+		case <-elected:
+			return nil
+		default:
+		}
+
+		record, _, err := lock.Get(req.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get leader election record of %s: %w", lock.Describe(), err)
+		}
+		if record.HolderIdentity == "" {
+			return errors.New("no leader elected")
+		}
+		expiry := record.RenewTime.Add(time.Duration(record.LeaseDurationSeconds) * time.Second)
+		if time.Now().After(expiry) {
+			return fmt.Errorf("lease of leader %s expired at %v", record.HolderIdentity, expiry)
+		}
+		return nil
+	}
+}
+
+// ManifestsCheck passes if the manifests at the paths given by the environment variables
+// are loadable. The manifests are part of the image, so they're only loaded once, when the
+// check is created.
+func ManifestsCheck(envKeys ...string) healthz.Checker {
+	err := loadManifests(envKeys...)
+	return func(*http.Request) error {
+		return err
+	}
+}
+
+// loadManifests loads the manifests at the paths given by the environment variables.
+func loadManifests(envKeys ...string) error {
+	for _, key := range envKeys {
+		path := os.Getenv(key)
+		if path == "" {
+			return fmt.Errorf("%s is not set", key)
+		}
+		if _, err := mf.ManifestFrom(mf.Path(path)); err != nil {
+			return fmt.Errorf("failed to load manifest %s from %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
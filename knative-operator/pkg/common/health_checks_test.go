@@ -0,0 +1,208 @@
+package common
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+type syncer bool
+
+func (s syncer) WaitForCacheSync(context.Context) bool {
+	return bool(s)
+}
+
+func TestCacheSyncedCheck(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	if err := CacheSyncedCheck(syncer(true))(req); err != nil {
+		t.Errorf("check() = %v, want nil for synced caches", err)
+	}
+	if err := CacheSyncedCheck(syncer(false))(req); err == nil {
+		t.Error("check() = nil, want an error for caches that aren't synced")
+	}
+}
+
+func TestWebhookCheck(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		notAfter  time.Time
+		wantErr   bool
+	}{{
+		name:      "valid",
+		notBefore: now.Add(-time.Hour),
+		notAfter:  now.Add(time.Hour),
+	}, {
+		name:      "expired",
+		notBefore: now.Add(-2 * time.Hour),
+		notAfter:  now.Add(-time.Hour),
+		wantErr:   true,
+	}, {
+		name:      "not yet valid",
+		notBefore: now.Add(time.Hour),
+		notAfter:  now.Add(2 * time.Hour),
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.NotFoundHandler())
+			server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate(t, test.notBefore, test.notAfter)}}
+			server.StartTLS()
+			defer server.Close()
+
+			err := WebhookCheck(server.Listener.Addr().String())(nil)
+			if (err != nil) != test.wantErr {
+				t.Errorf("check() = %v, want an error: %v", err, test.wantErr)
+			}
+		})
+	}
+
+	t.Run("not serving", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.NotFoundHandler())
+		addr := server.Listener.Addr().String()
+		server.Close()
+		if err := WebhookCheck(addr)(nil); err == nil {
+			t.Error("check() = nil, want an error for a closed server")
+		}
+	})
+}
+
+// certificate returns a self-signed certificate valid in the given period.
+func certificate(t *testing.T, notBefore, notAfter time.Time) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Failed to generate key:", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "webhook"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("Failed to create certificate:", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// fakeLock returns a fixed leader election record.
+type fakeLock struct {
+	resourcelock.Interface
+	record *resourcelock.LeaderElectionRecord
+	err    error
+}
+
+func (l *fakeLock) Get(context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	return l.record, nil, l.err
+}
+
+func (l *fakeLock) Describe() string {
+	return "openshift-serverless/knative-serving-openshift-lock"
+}
+
+func TestLeaderCheck(t *testing.T) {
+	elected := make(chan struct{})
+	close(elected)
+
+	now := metav1.Now()
+	tests := []struct {
+		name    string
+		elected chan struct{}
+		lock    *fakeLock
+		wantErr bool
+	}{{
+		name:    "elected",
+		elected: elected,
+		lock:    &fakeLock{err: errors.New("not called")},
+	}, {
+		name: "other leader",
+		lock: &fakeLock{record: &resourcelock.LeaderElectionRecord{
+			HolderIdentity:       "other",
+			LeaseDurationSeconds: 15,
+			RenewTime:            now,
+		}},
+	}, {
+		name: "expired lease",
+		lock: &fakeLock{record: &resourcelock.LeaderElectionRecord{
+			HolderIdentity:       "other",
+			LeaseDurationSeconds: 15,
+			RenewTime:            metav1.NewTime(now.Add(-time.Minute)),
+		}},
+		wantErr: true,
+	}, {
+		name:    "no leader",
+		lock:    &fakeLock{record: &resourcelock.LeaderElectionRecord{}},
+		wantErr: true,
+	}, {
+		name:    "no record",
+		lock:    &fakeLock{err: errors.New("not found")},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := LeaderCheck(test.elected, test.lock)(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if (err != nil) != test.wantErr {
+				t.Errorf("check() = %v, want an error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestManifestsCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifests")
+	if err != nil {
+		t.Fatal("Failed to create directory:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	valid := filepath.Join(dir, "valid.yaml")
+	if err := ioutil.WriteFile(valid, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n"), 0600); err != nil {
+		t.Fatal("Failed to write manifest:", err)
+	}
+
+	const key = "TEST_MANIFEST_PATH"
+	defer os.Unsetenv(key)
+
+	os.Unsetenv(key)
+	if err := ManifestsCheck(key)(nil); err == nil || !strings.Contains(err.Error(), key) {
+		t.Errorf("check() = %v, want an error about the unset %s", err, key)
+	}
+
+	os.Setenv(key, filepath.Join(dir, "missing.yaml"))
+	if err := ManifestsCheck(key)(nil); err == nil {
+		t.Error("check() = nil, want an error for a missing manifest")
+	}
+
+	os.Setenv(key, valid)
+	check := ManifestsCheck(key)
+	if err := check(nil); err != nil {
+		t.Errorf("check() = %v, want nil for a loadable manifest", err)
+	}
+
+	// The manifests aren't loaded again.
+	os.Remove(valid)
+	if err := check(nil); err != nil {
+		t.Errorf("check() = %v, want nil once the manifests were loaded", err)
+	}
+}
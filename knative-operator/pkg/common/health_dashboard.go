@@ -5,11 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	mfc "github.com/manifestival/controller-runtime-client"
 	mf "github.com/manifestival/manifestival"
+	serverlessoperatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard/builder"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -45,11 +51,113 @@ func InstallHealthDashboard(api client.Client) error {
 	return nil
 }
 
+// HealthComponents are the components whose health is shown on the health dashboard.
+type HealthComponents struct {
+	Serving  bool
+	Eventing bool
+	Kafka    bool
+}
+
+// healthComponents returns the components installed on the cluster.
+func healthComponents(api client.Client) (HealthComponents, error) {
+	components := HealthComponents{}
+	serving := &operatorv1alpha1.KnativeServingList{}
+	if err := api.List(context.TODO(), serving); err != nil {
+		return components, fmt.Errorf("failed to list KnativeServings: %w", err)
+	}
+	eventing := &operatorv1alpha1.KnativeEventingList{}
+	if err := api.List(context.TODO(), eventing); err != nil {
+		return components, fmt.Errorf("failed to list KnativeEventings: %w", err)
+	}
+	kafka := &serverlessoperatorv1alpha1.KnativeKafkaList{}
+	if err := api.List(context.TODO(), kafka); err != nil {
+		return components, fmt.Errorf("failed to list KnativeKafkas: %w", err)
+	}
+	components.Serving = len(serving.Items) > 0
+	components.Eventing = len(eventing.Items) > 0
+	components.Kafka = len(kafka.Items) > 0
+	return components, nil
+}
+
+// HealthDashboard returns the dashboard showing the readiness of the given components.
+func HealthDashboard(c HealthComponents) *builder.Dashboard {
+	const legend = "Status - 1 is Ready, 0 is NotReady"
+	var types []string
+	var graphs []builder.Panel
+	for _, component := range []struct {
+		enabled bool
+		name    string
+		status  string
+	}{
+		{c.Serving, "Serving", "serving_status"},
+		{c.Eventing, "Eventing", "eventing_status"},
+		{c.Kafka, "Kafka", "kafka_status"},
+	} {
+		if !component.enabled {
+			continue
+		}
+		types = append(types, component.status)
+		graphs = append(graphs, builder.Panel{
+			Type:  builder.Graph,
+			Title: fmt.Sprintf("Knative %s Status", component.name),
+			Targets: []builder.Target{{
+				Expr:   fmt.Sprintf(`knative_up{namespace="$namespace", service="knative-openshift-metrics-3", type="%s"}`, component.status),
+				Legend: legend,
+			}, {
+				Expr:   fmt.Sprintf(`knative_condition{namespace="$namespace", service="knative-openshift-metrics-3", type="%s", condition="install_succeeded", status="false"}`, component.status),
+				Legend: "Installation - 1 is Failed, 0 is Installing or Installed",
+			}},
+		})
+	}
+
+	var rows []builder.Row
+	if len(types) > 0 {
+		status := builder.Panel{
+			Type:  builder.SingleStat,
+			Title: "Knative Status",
+			Width: 24,
+			Targets: []builder.Target{{
+				Expr: fmt.Sprintf(`min by(job)(knative_up{namespace="$namespace", job="knative-openshift-metrics-3", type=~"%s"})`, strings.Join(types, "|")),
+			}},
+			ValueMaps: []builder.ValueMap{
+				{Value: "null", Text: "N/A"},
+				{Value: "1", Text: "Ready"},
+				{Value: "0", Text: "NotReady"},
+			},
+		}
+		rows = append(rows, builder.Row{Panels: append([]builder.Panel{status}, graphs...)})
+	}
+	return &builder.Dashboard{
+		Name:  "grafana-dashboard-definition-knative-health",
+		Key:   "revision-dashboard.json",
+		UID:   "bhpVnWVMz",
+		Title: "Knative Health Status",
+		Variables: []builder.Variable{{
+			Name:  "namespace",
+			Query: `label_values(knative_up{namespace!="unknown"}, namespace)`,
+		}},
+		Rows: rows,
+	}
+}
+
 // manifest returns dashboard deployment resources manifest
 func manifest(apiclient client.Client, deploymentName string, namespace string) (mf.Manifest, error) {
-	manifest, err := mfc.NewManifest(manifestPath(), apiclient, mf.UseLogger(logh.WithName("mf")))
+	components, err := healthComponents(apiclient)
+	if err != nil {
+		return mf.Manifest{}, err
+	}
+	cm, err := builder.ConfigMap(HealthDashboard(components))
 	if err != nil {
-		return mf.Manifest{}, fmt.Errorf("failed to read dashboard manifest: %w", err)
+		return mf.Manifest{}, err
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+	if err != nil {
+		return mf.Manifest{}, fmt.Errorf("failed to convert dashboard: %w", err)
+	}
+	manifest, err := mf.ManifestFrom(mf.Slice([]unstructured.Unstructured{{Object: u}}),
+		mf.UseClient(mfc.NewClient(apiclient)), mf.UseLogger(logh.WithName("mf")))
+	if err != nil {
+		return mf.Manifest{}, fmt.Errorf("failed to create dashboard manifest: %w", err)
 	}
 	transforms := []mf.Transformer{
 		SetAnnotations(map[string]string{
@@ -58,21 +166,9 @@ func manifest(apiclient client.Client, deploymentName string, namespace string)
 		}),
 		mf.InjectNamespace(ConfigManagedNamespace),
 	}
-	if manifest, err = manifest.Transform(transforms...); err != nil {
-		return mf.Manifest{}, fmt.Errorf("unable to transform role and roleBinding serviceMonitor manifest %w", err)
-	}
 	manifest, err = manifest.Transform(transforms...)
 	if err != nil {
 		return mf.Manifest{}, fmt.Errorf("failed to transform kn dashboard resources manifest %w", err)
 	}
 	return manifest, nil
 }
-
-// manifestPath returns health dashboard resource manifest path
-func manifestPath() string {
-	path := os.Getenv("HEALTH_DASHBOARD_MANIFEST_PATH")
-	if path == "" {
-		return "deploy/resources/dashboards/grafana-dash-knative-health.yaml"
-	}
-	return path
-}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the dashboards")
+
+func TestHealthDashboardGolden(t *testing.T) {
+	cases := []struct {
+		golden     string
+		components HealthComponents
+	}{{
+		golden:     "health-dashboard.json",
+		components: HealthComponents{Serving: true, Eventing: true, Kafka: true},
+	}, {
+		golden:     "health-dashboard-serving.json",
+		components: HealthComponents{Serving: true},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.golden, func(t *testing.T) {
+			got, err := HealthDashboard(c.components).JSON()
+			if err != nil {
+				t.Fatal("JSON() =", err)
+			}
+			path := filepath.Join("testdata", c.golden)
+			if *update {
+				if err := ioutil.WriteFile(path, []byte(got+"\n"), 0o644); err != nil {
+					t.Fatal("failed to update golden file:", err)
+				}
+			}
+			want, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal("failed to read golden file:", err)
+			}
+			if diff := cmp.Diff(string(want), got+"\n"); diff != "" {
+				t.Errorf("dashboard differs from %s, run the tests with -update to regenerate it (-want, +got): %s", path, diff)
+			}
+		})
+	}
+}
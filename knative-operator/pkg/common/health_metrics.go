@@ -1,8 +1,18 @@
 package common
 
 import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var (
@@ -16,9 +26,122 @@ var (
 	KnativeServingUpG  prometheus.Gauge
 	KnativeEventingUpG prometheus.Gauge
 	KnativeKafkaUpG    prometheus.Gauge
+
+	// KnativeCondition is 1 for the current status of each condition of a Knative component
+	// and 0 for the other statuses, which tells a component still being installed from a
+	// failed one.
+	KnativeCondition = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "knative_condition",
+			Help: "Reports the status of the conditions of a Knative component",
+		},
+		[]string{"type", "condition", "status"},
+	)
+	KnativeReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "knative_reconcile_duration_seconds",
+			Help:    "Duration of the reconciliations of a Knative component",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+		},
+		[]string{"type"},
+	)
+	KnativeReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "knative_reconcile_errors_total",
+			Help: "Number of failed reconciliations of a Knative component",
+		},
+		[]string{"type"},
+	)
 )
 
+// conditionStatuses are the label values of the statuses of a condition.
+var conditionStatuses = map[corev1.ConditionStatus]string{
+	corev1.ConditionTrue:    "true",
+	corev1.ConditionFalse:   "false",
+	corev1.ConditionUnknown: "unknown",
+}
+
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(KnativeUp)
+	metrics.Registry.MustRegister(KnativeUp, KnativeCondition, KnativeReconcileDuration, KnativeReconcileErrors)
+}
+
+// reportedConditions tracks the conditions reported per component, so conditions that
+// disappear from a component's status are removed from KnativeCondition.
+var reportedConditions = struct {
+	sync.Mutex
+	byComponent map[string]sets.String
+}{byComponent: map[string]sets.String{}}
+
+// SetConditionMetrics reports the status of the conditions of the given component. Conditions
+// reported before that aren't part of the status anymore are removed.
+func SetConditionMetrics(component string, status duckv1.Status) {
+	current := sets.NewString()
+	for _, cond := range status.Conditions {
+		name := conditionName(cond.Type)
+		current.Insert(name)
+		for s, label := range conditionStatuses {
+			value := 0.0
+			if cond.Status == s {
+				value = 1
+			}
+			KnativeCondition.WithLabelValues(component, name, label).Set(value)
+		}
+	}
+
+	reportedConditions.Lock()
+	defer reportedConditions.Unlock()
+	deleteConditions(component, reportedConditions.byComponent[component].Difference(current))
+	reportedConditions.byComponent[component] = current
+}
+
+// DeleteConditionMetrics removes the conditions of the given component once it's removed.
+func DeleteConditionMetrics(component string, status duckv1.Status) {
+	conditions := sets.NewString()
+	for _, cond := range status.Conditions {
+		conditions.Insert(conditionName(cond.Type))
+	}
+
+	reportedConditions.Lock()
+	defer reportedConditions.Unlock()
+	deleteConditions(component, conditions.Union(reportedConditions.byComponent[component]))
+	delete(reportedConditions.byComponent, component)
+}
+
+// deleteConditions removes all statuses of the given conditions of the component.
+func deleteConditions(component string, conditions sets.String) {
+	for name := range conditions {
+		for _, label := range conditionStatuses {
+			KnativeCondition.DeleteLabelValues(component, name, label)
+		}
+	}
+}
+
+// conditionName returns the snake case name of the condition, e.g. install_succeeded.
+func conditionName(t apis.ConditionType) string {
+	var b strings.Builder
+	for i, r := range string(t) {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// InstrumentReconciler records the duration and the errors of the reconciliations of the
+// given component.
+func InstrumentReconciler(component string, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+		start := time.Now()
+		result, err := r.Reconcile(ctx, request)
+		KnativeReconcileDuration.WithLabelValues(component).Observe(time.Since(start).Seconds())
+		if err != nil {
+			KnativeReconcileErrors.WithLabelValues(component).Inc()
+		}
+		return result, err
+	})
 }
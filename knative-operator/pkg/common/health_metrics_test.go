@@ -0,0 +1,137 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestConditionMetrics(t *testing.T) {
+	status := duckv1.Status{Conditions: duckv1.Conditions{{
+		Type:   operatorv1alpha1.InstallSucceeded,
+		Status: corev1.ConditionFalse,
+	}, {
+		Type:   operatorv1alpha1.DeploymentsAvailable,
+		Status: corev1.ConditionUnknown,
+	}}}
+	SetConditionMetrics("test_status", status)
+
+	cases := []struct {
+		condition string
+		status    string
+		want      float64
+	}{
+		{"install_succeeded", "false", 1},
+		{"install_succeeded", "true", 0},
+		{"install_succeeded", "unknown", 0},
+		{"deployments_available", "unknown", 1},
+		{"deployments_available", "false", 0},
+	}
+	for _, c := range cases {
+		labels := map[string]string{"type": "test_status", "condition": c.condition, "status": c.status}
+		if got, ok := metricValue(t, "knative_condition", labels); !ok || got != c.want {
+			t.Errorf("knative_condition%v = %v (found: %v), want: %v", labels, got, ok, c.want)
+		}
+	}
+
+	DeleteConditionMetrics("test_status", status)
+	labels := map[string]string{"type": "test_status", "condition": "install_succeeded", "status": "false"}
+	if _, ok := metricValue(t, "knative_condition", labels); ok {
+		t.Error("got condition metrics, want them removed")
+	}
+}
+
+func TestConditionMetricsRemovedCondition(t *testing.T) {
+	SetConditionMetrics("removed_status", duckv1.Status{Conditions: duckv1.Conditions{{
+		Type:   operatorv1alpha1.InstallSucceeded,
+		Status: corev1.ConditionTrue,
+	}, {
+		Type:   operatorv1alpha1.DeploymentsAvailable,
+		Status: corev1.ConditionFalse,
+	}}})
+	SetConditionMetrics("removed_status", duckv1.Status{Conditions: duckv1.Conditions{{
+		Type:   operatorv1alpha1.InstallSucceeded,
+		Status: corev1.ConditionTrue,
+	}}})
+
+	labels := map[string]string{"type": "removed_status", "condition": "deployments_available", "status": "false"}
+	if _, ok := metricValue(t, "knative_condition", labels); ok {
+		t.Error("got metrics of a condition that's no longer reported, want them removed")
+	}
+	labels = map[string]string{"type": "removed_status", "condition": "install_succeeded", "status": "true"}
+	if got, ok := metricValue(t, "knative_condition", labels); !ok || got != 1 {
+		t.Errorf("knative_condition%v = %v (found: %v), want: 1", labels, got, ok)
+	}
+	DeleteConditionMetrics("removed_status", duckv1.Status{})
+}
+
+func TestConditionName(t *testing.T) {
+	cases := map[apis.ConditionType]string{
+		apis.ConditionReady:                       "ready",
+		operatorv1alpha1.InstallSucceeded:         "install_succeeded",
+		operatorv1alpha1.DependenciesInstalled:    "dependencies_installed",
+		operatorv1alpha1.VersionMigrationEligible: "version_migration_eligible",
+	}
+	for in, want := range cases {
+		if got := conditionName(in); got != want {
+			t.Errorf("conditionName(%q) = %q, want: %q", in, got, want)
+		}
+	}
+}
+
+func TestInstrumentReconciler(t *testing.T) {
+	r := InstrumentReconciler("instrumented_status", reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, errors.New("failed")
+	}))
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(context.Background(), reconcile.Request{}); err == nil {
+			t.Fatal("Reconcile() = nil, want the reconciler's error")
+		}
+	}
+	labels := map[string]string{"type": "instrumented_status"}
+	if got, _ := metricValue(t, "knative_reconcile_errors_total", labels); got != 2 {
+		t.Errorf("knative_reconcile_errors_total = %v, want: 2", got)
+	}
+	if got, _ := metricValue(t, "knative_reconcile_duration_seconds", labels); got != 2 {
+		t.Errorf("knative_reconcile_duration_seconds count = %v, want: 2", got)
+	}
+}
+
+// metricValue returns the value of the gauge or counter, or the sample count of the histogram
+// with the given labels.
+func metricValue(t *testing.T, name string, labels map[string]string) (float64, bool) {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal("failed to gather metrics:", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metric:
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if labels[l.GetName()] != l.GetValue() {
+					continue metric
+				}
+			}
+			switch {
+			case m.GetGauge() != nil:
+				return m.GetGauge().GetValue(), true
+			case m.GetCounter() != nil:
+				return m.GetCounter().GetValue(), true
+			case m.GetHistogram() != nil:
+				return float64(m.GetHistogram().GetSampleCount()), true
+			}
+		}
+	}
+	return 0, false
+}
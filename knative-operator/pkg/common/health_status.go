@@ -0,0 +1,145 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	serverlessoperatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Phases of a Knative component reported by the status endpoint.
+const (
+	PhaseNotInstalled = "NotInstalled"
+	PhaseInstalling   = "Installing"
+	PhaseReady        = "Ready"
+	PhaseFailed       = "Failed"
+	PhaseDeleting     = "Deleting"
+)
+
+// Health is the health of the Knative components.
+type Health struct {
+	Serving  ComponentHealth `json:"serving"`
+	Eventing ComponentHealth `json:"eventing"`
+	Kafka    ComponentHealth `json:"kafka"`
+}
+
+// ComponentHealth is the health of a Knative component as reported by its CR.
+type ComponentHealth struct {
+	Phase      string            `json:"phase"`
+	Name       string            `json:"name,omitempty"`
+	Namespace  string            `json:"namespace,omitempty"`
+	Version    string            `json:"version,omitempty"`
+	Conditions []ConditionHealth `json:"conditions,omitempty"`
+}
+
+// ConditionHealth is a condition of a component's CR.
+type ConditionHealth struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// StatusHandler serves the health of the Knative components as JSON.
+func StatusHandler(api client.Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		health, err := GetHealth(req.Context(), api)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(health); err != nil {
+			logh.Error(err, "Failed to write the status")
+		}
+	})
+}
+
+// GetHealth returns the health of the Knative components.
+func GetHealth(ctx context.Context, api client.Reader) (Health, error) {
+	health := Health{
+		Serving:  ComponentHealth{Phase: PhaseNotInstalled},
+		Eventing: ComponentHealth{Phase: PhaseNotInstalled},
+		Kafka:    ComponentHealth{Phase: PhaseNotInstalled},
+	}
+
+	serving := &operatorv1alpha1.KnativeServingList{}
+	if err := list(ctx, api, serving); err != nil {
+		return health, fmt.Errorf("failed to list KnativeServings: %w", err)
+	}
+	if len(serving.Items) > 0 {
+		ks := serving.Items[0]
+		health.Serving = componentHealth(&ks, ks.Status.Status, ks.Status.Version)
+	}
+
+	eventing := &operatorv1alpha1.KnativeEventingList{}
+	if err := list(ctx, api, eventing); err != nil {
+		return health, fmt.Errorf("failed to list KnativeEventings: %w", err)
+	}
+	if len(eventing.Items) > 0 {
+		ke := eventing.Items[0]
+		health.Eventing = componentHealth(&ke, ke.Status.Status, ke.Status.Version)
+	}
+
+	kafka := &serverlessoperatorv1alpha1.KnativeKafkaList{}
+	if err := list(ctx, api, kafka); err != nil {
+		return health, fmt.Errorf("failed to list KnativeKafkas: %w", err)
+	}
+	if len(kafka.Items) > 0 {
+		kk := kafka.Items[0]
+		health.Kafka = componentHealth(&kk, kk.Status.Status, "")
+	}
+	return health, nil
+}
+
+// list lists the given CRs, there are none if their CRD isn't installed.
+func list(ctx context.Context, api client.Reader, l client.ObjectList) error {
+	if err := api.List(ctx, l); err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+	return nil
+}
+
+func componentHealth(obj metav1.Object, status duckv1.Status, version string) ComponentHealth {
+	health := ComponentHealth{
+		Phase:     phase(obj, status),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Version:   version,
+	}
+	for _, cond := range status.Conditions {
+		health.Conditions = append(health.Conditions, ConditionHealth{
+			Type:    string(cond.Type),
+			Status:  string(cond.Status),
+			Reason:  cond.Reason,
+			Message: cond.Message,
+		})
+	}
+	return health
+}
+
+// phase tells a component being installed from a broken one. The deployments becoming
+// available or the dependencies being installed take time, a failed installation or an
+// upgrade that isn't possible requires an intervention.
+func phase(obj metav1.Object, status duckv1.Status) string {
+	if obj.GetDeletionTimestamp() != nil {
+		return PhaseDeleting
+	}
+	if status.GetCondition(apis.ConditionReady).IsTrue() {
+		return PhaseReady
+	}
+	for _, t := range []apis.ConditionType{operatorv1alpha1.InstallSucceeded, operatorv1alpha1.VersionMigrationEligible} {
+		if status.GetCondition(t).IsFalse() {
+			return PhaseFailed
+		}
+	}
+	return PhaseInstalling
+}
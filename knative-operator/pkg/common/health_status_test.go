@@ -0,0 +1,113 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	serverlessoperatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStatusHandler(t *testing.T) {
+	ks := &operatorv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Name: "knative-serving", Namespace: "knative-serving"},
+		Status: operatorv1alpha1.KnativeServingStatus{
+			Status:  conditions(apis.ConditionReady, corev1.ConditionTrue),
+			Version: "0.22.0",
+		},
+	}
+	ke := &operatorv1alpha1.KnativeEventing{
+		ObjectMeta: metav1.ObjectMeta{Name: "knative-eventing", Namespace: "knative-eventing"},
+		Status: operatorv1alpha1.KnativeEventingStatus{
+			Status: conditions(operatorv1alpha1.DeploymentsAvailable, corev1.ConditionFalse),
+		},
+	}
+	kk := &serverlessoperatorv1alpha1.KnativeKafka{
+		ObjectMeta: metav1.ObjectMeta{Name: "knative-kafka", Namespace: "knative-eventing"},
+		Status: serverlessoperatorv1alpha1.KnativeKafkaStatus{
+			Status: conditions(operatorv1alpha1.InstallSucceeded, corev1.ConditionFalse),
+		},
+	}
+
+	cases := []struct {
+		name    string
+		objects []runtime.Object
+		want    Health
+	}{{
+		name: "nothing installed",
+		want: Health{
+			Serving:  ComponentHealth{Phase: PhaseNotInstalled},
+			Eventing: ComponentHealth{Phase: PhaseNotInstalled},
+			Kafka:    ComponentHealth{Phase: PhaseNotInstalled},
+		},
+	}, {
+		name:    "ready, installing and failed",
+		objects: []runtime.Object{ks, ke, kk},
+		want: Health{
+			Serving: ComponentHealth{
+				Phase:      PhaseReady,
+				Name:       "knative-serving",
+				Namespace:  "knative-serving",
+				Version:    "0.22.0",
+				Conditions: []ConditionHealth{{Type: "Ready", Status: "True"}},
+			},
+			Eventing: ComponentHealth{
+				Phase:      PhaseInstalling,
+				Name:       "knative-eventing",
+				Namespace:  "knative-eventing",
+				Conditions: []ConditionHealth{{Type: "DeploymentsAvailable", Status: "False", Reason: "Test"}},
+			},
+			Kafka: ComponentHealth{
+				Phase:      PhaseFailed,
+				Name:       "knative-kafka",
+				Namespace:  "knative-eventing",
+				Conditions: []ConditionHealth{{Type: "InstallSucceeded", Status: "False", Reason: "Test"}},
+			},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(c.objects...).Build()
+			rec := httptest.NewRecorder()
+			StatusHandler(cl).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got status code %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+			}
+			var got Health
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal("failed to decode the status:", err)
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("got unexpected health (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPhaseDeleting(t *testing.T) {
+	now := metav1.NewTime(time.Now())
+	ks := &operatorv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}}
+	if got := phase(ks, conditions(apis.ConditionReady, corev1.ConditionTrue)); got != PhaseDeleting {
+		t.Errorf("phase() = %q, want: %q", got, PhaseDeleting)
+	}
+}
+
+func conditions(t apis.ConditionType, status corev1.ConditionStatus) duckv1.Status {
+	cond := apis.Condition{Type: t, Status: status}
+	if status == corev1.ConditionFalse {
+		cond.Reason = "Test"
+	}
+	return duckv1.Status{Conditions: duckv1.Conditions{cond}}
+}
@@ -0,0 +1,160 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/openshift-knative/serverless-operator/pkg/images"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// imageCatalogCache is shared by all controllers and webhooks of the manager.
+var imageCatalogCache struct {
+	once  sync.Once
+	cache cache.Cache
+	err   error
+}
+
+// catalogCache is started by the manager along with its own cache, before any controller
+// or webhook reads from it.
+type catalogCache struct {
+	cache.Cache
+}
+
+func (c catalogCache) GetCache() cache.Cache {
+	return c.Cache
+}
+
+// ImageCatalogCache returns the cache the image catalog ConfigMap is read and watched
+// through. It only holds the operator's namespace, so the ConfigMaps of the whole cluster
+// aren't cached just for the catalog. The cache is added to the given manager on the first
+// call.
+func ImageCatalogCache(mgr manager.Manager) (cache.Cache, error) {
+	imageCatalogCache.once.Do(func() {
+		c, err := cache.New(mgr.GetConfig(), cache.Options{
+			Scheme:    mgr.GetScheme(),
+			Mapper:    mgr.GetRESTMapper(),
+			Namespace: os.Getenv(NamespaceEnvKey),
+		})
+		if err != nil {
+			imageCatalogCache.err = fmt.Errorf("failed to create the image catalog cache: %w", err)
+			return
+		}
+		if err := mgr.Add(catalogCache{c}); err != nil {
+			imageCatalogCache.err = fmt.Errorf("failed to add the image catalog cache: %w", err)
+			return
+		}
+		imageCatalogCache.cache = c
+	})
+	return imageCatalogCache.cache, imageCatalogCache.err
+}
+
+// ImageCatalog resolves the image catalog with the given prefix from the environment and
+// the image catalog ConfigMap in the operator's namespace, read through the given reader.
+// Invalid overrides are dropped and returned as problems.
+func ImageCatalog(r client.Reader, prefix string) (images.Catalog, []images.Problem, error) {
+	cm, err := imageCatalogConfigMap(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	catalog, problems := images.Resolve(os.Environ(), cm, prefix)
+	return catalog, problems, nil
+}
+
+// ImageMirrors returns the mirror rules from the image catalog ConfigMap, read through the
+// given reader, and from the cluster's ImageContentSourcePolicies, if the cluster supports
+// them.
+func ImageMirrors(r client.Reader, c client.Client) (images.Mirrors, error) {
+	cm, err := imageCatalogConfigMap(r)
+	if err != nil {
+		return nil, err
+	}
+	mirrors, err := images.MirrorsFromConfigMap(cm)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := &unstructured.UnstructuredList{}
+	policies.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "operator.openshift.io",
+		Version: "v1alpha1",
+		Kind:    "ImageContentSourcePolicyList",
+	})
+	if err := c.List(context.TODO(), policies); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return mirrors, nil
+		}
+		return nil, fmt.Errorf("failed to list ImageContentSourcePolicies: %w", err)
+	}
+	return append(mirrors, images.MirrorsFromPolicies(policies.Items)...), nil
+}
+
+// imageCatalogConfigMap returns the image catalog ConfigMap from the operator's namespace
+// or nil if there is none.
+func imageCatalogConfigMap(r client.Reader) (*corev1.ConfigMap, error) {
+	ns := os.Getenv(NamespaceEnvKey)
+	if ns == "" {
+		return nil, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := r.Get(context.TODO(), client.ObjectKey{Namespace: ns, Name: images.ConfigMapName}, cm)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get image catalog ConfigMap: %w", err)
+	}
+	return cm, nil
+}
+
+// isImageCatalog returns true if the given object is the image catalog ConfigMap.
+func isImageCatalog(obj client.Object) bool {
+	return obj.GetName() == images.ConfigMapName && obj.GetNamespace() == os.Getenv(NamespaceEnvKey)
+}
+
+// EnqueueAllOnImageCatalogChange enqueues reconcile requests for all objects of the given
+// list's type whenever the image catalog ConfigMap changes.
+func EnqueueAllOnImageCatalogChange(c client.Client, list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		if !isImageCatalog(obj) {
+			return nil
+		}
+
+		instances := list.DeepCopyObject().(client.ObjectList)
+		if err := c.List(context.TODO(), instances); err != nil {
+			Log.Error(err, "Failed to list instances for the image catalog change")
+			return nil
+		}
+		items, err := meta.ExtractList(instances)
+		if err != nil {
+			Log.Error(err, "Failed to extract instances for the image catalog change")
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(items))
+		for _, item := range items {
+			accessor, err := meta.Accessor(item)
+			if err != nil {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()},
+			})
+		}
+		return requests
+	})
+}
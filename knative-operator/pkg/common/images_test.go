@@ -0,0 +1,78 @@
+package common_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/pkg/images"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestImageCatalog(t *testing.T) {
+	os.Setenv(common.NamespaceEnvKey, "openshift-serverless")
+	os.Setenv("KAFKA_IMAGE_foo", "quay.io/foo:env")
+	os.Setenv("KAFKA_IMAGE_bar", "quay.io/bar:env")
+	defer func() {
+		os.Unsetenv(common.NamespaceEnvKey)
+		os.Unsetenv("KAFKA_IMAGE_foo")
+		os.Unsetenv("KAFKA_IMAGE_bar")
+	}()
+
+	client := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-serverless",
+			Name:      images.ConfigMapName,
+		},
+		Data: map[string]string{
+			"KAFKA_IMAGE_foo":           "registry.redhat.io/foo:cm",
+			images.AllowedRegistriesKey: "registry.redhat.io",
+		},
+	}).Build()
+
+	catalog, problems, err := common.ImageCatalog(client, images.KafkaPrefix)
+	if err != nil {
+		t.Fatal("ImageCatalog() =", err)
+	}
+
+	want := images.Catalog{"foo": "registry.redhat.io/foo:cm"}
+	if !cmp.Equal(catalog, want) {
+		t.Errorf("Got = %v, want: %v", catalog, want)
+	}
+	if got := images.Keys(problems); !cmp.Equal(got, []string{"bar"}) {
+		t.Errorf("Problems = %v, want an invalid override for bar", problems)
+	}
+}
+
+func TestImageMirrors(t *testing.T) {
+	os.Setenv(common.NamespaceEnvKey, "openshift-serverless")
+	defer os.Unsetenv(common.NamespaceEnvKey)
+
+	client := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-serverless",
+			Name:      images.ConfigMapName,
+		},
+		Data: map[string]string{
+			images.MirrorsKey: `
+- source: registry.redhat.io
+  mirrors:
+  - mirror.example.com/redhat
+`,
+		},
+	}).Build()
+
+	// The fake client doesn't know ImageContentSourcePolicies, like a cluster without them.
+	mirrors, err := common.ImageMirrors(client, client)
+	if err != nil {
+		t.Fatal("ImageMirrors() =", err)
+	}
+
+	want := images.Mirrors{{Source: "registry.redhat.io", Mirrors: []string{"mirror.example.com/redhat"}}}
+	if !cmp.Equal(mirrors, want) {
+		t.Errorf("Got = %v, want: %v", mirrors, want)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	commonv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 )
 
+// MutateKafka sets the defaults of KnativeKafka. The mutating webhook and the controller
+// share it, so that they agree on the defaulted object.
 func MutateKafka(ke *operatorv1alpha1.KnativeKafka) {
 	defaultToKafkaHa(ke)
+	defaultKafkaAuthSecretNamespace(ke)
 }
 
 func defaultToKafkaHa(ke *operatorv1alpha1.KnativeKafka) {
@@ -16,3 +19,11 @@ func defaultToKafkaHa(ke *operatorv1alpha1.KnativeKafka) {
 		}
 	}
 }
+
+// defaultKafkaAuthSecretNamespace looks up the auth secret in the namespace of the
+// KnativeKafka if no other is given.
+func defaultKafkaAuthSecretNamespace(ke *operatorv1alpha1.KnativeKafka) {
+	if ke.Spec.Channel.AuthSecretName != "" && ke.Spec.Channel.AuthSecretNamespace == "" {
+		ke.Spec.Channel.AuthSecretNamespace = ke.Namespace
+	}
+}
@@ -25,6 +25,13 @@ const (
 	operatorDeploymentNameEnvKey = "DEPLOYMENT_NAME"
 	// service monitor created successfully when monitoringLabel added to namespace
 	monitoringLabel = "openshift.io/cluster-monitoring"
+	// prometheusRBACName is the name of the Role and RoleBinding granting Prometheus access
+	prometheusRBACName = "knative-serving-prometheus-k8s"
+	// sourceMonitoringLabel marks the Role and RoleBinding created for the sources of a namespace
+	sourceMonitoringLabel = "eventing.knative.openshift.io/source-monitoring"
+	// sourcePreviousLabelAnnotation records the value the monitoring label had before it was
+	// set for the sources of a namespace. An empty value means the label wasn't present.
+	sourcePreviousLabelAnnotation = "eventing.knative.openshift.io/previous-cluster-monitoring"
 )
 
 func SetupMonitoringRequirements(api client.Client, instance mf.Owner) error {
@@ -39,6 +46,98 @@ func SetupMonitoringRequirements(api client.Client, instance mf.Owner) error {
 	return nil
 }
 
+// SetupSourcePlatformMonitoring grants the platform's Prometheus access to the namespace of the
+// given source deployment. If labelNamespace is set, the namespace is opted into cluster
+// monitoring too, remembering the label's previous value for RemoveSourcePlatformMonitoring.
+// The RBAC is shared by all sources of the namespace, so none of them owns it.
+func SetupSourcePlatformMonitoring(api client.Client, instance *appsv1.Deployment, labelNamespace bool) error {
+	if labelNamespace {
+		legacy, err := hasLegacySourceRBAC(api, instance.Namespace)
+		if err != nil {
+			return err
+		}
+		if err := addSourceMonitoringLabelToNamespace(instance.Namespace, legacy, api); err != nil {
+			return err
+		}
+	}
+	clientOptions := mf.UseClient(mfclient.NewClient(api))
+	rbacManifest, err := createRBACManifestForPrometheusAccount(instance.Namespace, clientOptions)
+	if err != nil {
+		return err
+	}
+	transforms := []mf.Transformer{func(u *unstructured.Unstructured) error {
+		labels := u.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string, 1)
+		}
+		labels[sourceMonitoringLabel] = "true"
+		u.SetLabels(labels)
+		// Drop the owner references older versions set.
+		u.SetOwnerReferences(nil)
+		return nil
+	}}
+	if *rbacManifest, err = rbacManifest.Transform(transforms...); err != nil {
+		return fmt.Errorf("unable to transform role and roleBinding manifest for Prometheus account: %w", err)
+	}
+	if err := rbacManifest.Apply(); err != nil {
+		return fmt.Errorf("unable to create role and roleBinding for Prometheus account %w", err)
+	}
+	return nil
+}
+
+// RemoveSourcePlatformMonitoring removes what SetupSourcePlatformMonitoring set up in the given
+// namespace. If labelNamespace is set, the namespace's monitoring label is reverted too. Labels
+// and RBAC not created for the sources are left untouched.
+func RemoveSourcePlatformMonitoring(api client.Client, namespace string, labelNamespace bool) error {
+	ctx := context.TODO()
+	key := client.ObjectKey{Namespace: namespace, Name: prometheusRBACName}
+	legacy := false
+	for _, obj := range []client.Object{&rbacv1.RoleBinding{}, &rbacv1.Role{}} {
+		if err := api.Get(ctx, key, obj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if isLegacySourceRBAC(obj) {
+			legacy = true
+		} else if obj.GetLabels()[sourceMonitoringLabel] != "true" {
+			continue
+		}
+		if err := api.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s/%s: %w", namespace, prometheusRBACName, err)
+		}
+	}
+	if !labelNamespace {
+		return nil
+	}
+	return revertSourceMonitoringLabelOnNamespace(namespace, legacy, api)
+}
+
+// isLegacySourceRBAC returns true if the given RBAC has been created for the sources by older
+// versions. They didn't label it but made the source deployment own it.
+func isLegacySourceRBAC(obj client.Object) bool {
+	if obj.GetLabels()[sourceMonitoringLabel] == "true" {
+		return false
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.APIVersion == "apps/v1" && ref.Kind == "Deployment" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLegacySourceRBAC returns true if the given namespace has the RBAC older versions created
+// for the sources.
+func hasLegacySourceRBAC(api client.Client, namespace string) (bool, error) {
+	role := &rbacv1.Role{}
+	if err := api.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: prometheusRBACName}, role); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return isLegacySourceRBAC(role), nil
+}
+
 func RemoveOldServiceMonitorResourcesIfExist(namespace string, api client.Client) error {
 	oldSM := monitoringv1.ServiceMonitor{
 		ObjectMeta: metav1.ObjectMeta{
@@ -109,6 +208,65 @@ func addMonitoringLabelToNamespace(namespace string, api client.Client) error {
 	return nil
 }
 
+// addSourceMonitoringLabelToNamespace sets the monitoring label on the given namespace and
+// records its previous value. If legacy is set, older versions labelled the namespace without
+// recording anything, so the label is recorded as added by the operator.
+func addSourceMonitoringLabelToNamespace(namespace string, legacy bool, api client.Client) error {
+	ns := &v1.Namespace{}
+	if err := api.Get(context.TODO(), client.ObjectKey{Name: namespace}, ns); err != nil {
+		return err
+	}
+	_, recorded := ns.Annotations[sourcePreviousLabelAnnotation]
+	if ns.Labels[monitoringLabel] == "true" && (recorded || !legacy) {
+		return nil
+	}
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	if ns.Annotations == nil {
+		ns.Annotations = map[string]string{}
+	}
+	if !recorded {
+		previous := ns.Labels[monitoringLabel]
+		if legacy && previous == "true" {
+			previous = ""
+		}
+		ns.Annotations[sourcePreviousLabelAnnotation] = previous
+	}
+	ns.Labels[monitoringLabel] = "true"
+	if err := api.Update(context.TODO(), ns); err != nil {
+		return fmt.Errorf("could not add label %q to namespace %q: %w", monitoringLabel, namespace, err)
+	}
+	return nil
+}
+
+// revertSourceMonitoringLabelOnNamespace restores the monitoring label's previous value on the
+// given namespace. If legacy is set, an unrecorded label has been set by older versions and is
+// removed.
+func revertSourceMonitoringLabelOnNamespace(namespace string, legacy bool, api client.Client) error {
+	ns := &v1.Namespace{}
+	if err := api.Get(context.TODO(), client.ObjectKey{Name: namespace}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	previous, ok := ns.Annotations[sourcePreviousLabelAnnotation]
+	if !ok && (!legacy || ns.Labels[monitoringLabel] != "true") {
+		return nil
+	}
+	if previous == "" {
+		delete(ns.Labels, monitoringLabel)
+	} else {
+		if ns.Labels == nil {
+			ns.Labels = map[string]string{}
+		}
+		ns.Labels[monitoringLabel] = previous
+	}
+	delete(ns.Annotations, sourcePreviousLabelAnnotation)
+	if err := api.Update(context.TODO(), ns); err != nil {
+		return fmt.Errorf("could not revert label %q on namespace %q: %w", monitoringLabel, namespace, err)
+	}
+	return nil
+}
+
 func createRoleAndRoleBinding(instance mf.Owner, namespace string, client client.Client) error {
 	clientOptions := mf.UseClient(mfclient.NewClient(client))
 	rbacManifest, err := createRBACManifestForPrometheusAccount(namespace, clientOptions)
@@ -130,7 +288,7 @@ func createRBACManifestForPrometheusAccount(ns string, options mf.Option) (*mf.M
 	var rbU = &unstructured.Unstructured{}
 	role := rbacv1.Role{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "knative-serving-prometheus-k8s",
+			Name:      prometheusRBACName,
 			Namespace: ns,
 		},
 		Rules: []rbacv1.PolicyRule{{
@@ -141,7 +299,7 @@ func createRBACManifestForPrometheusAccount(ns string, options mf.Option) (*mf.M
 	}
 	rb := rbacv1.RoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "knative-serving-prometheus-k8s",
+			Name:      prometheusRBACName,
 			Namespace: ns,
 		},
 		RoleRef: rbacv1.RoleRef{
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"fmt"
 
 	mfclient "github.com/manifestival/controller-runtime-client"
@@ -8,6 +9,8 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -36,6 +39,28 @@ func SetupSourceServiceMonitorResources(client client.Client, instance *appsv1.D
 	return smManifest.Apply()
 }
 
+// RemoveSourceServiceMonitorResources deletes the Service and ServiceMonitor of the given
+// source deployment. Resources not controlled by the deployment are left alone.
+func RemoveSourceServiceMonitorResources(c client.Client, instance *appsv1.Deployment) error {
+	ctx := context.TODO()
+	key := client.ObjectKey{Namespace: instance.Namespace, Name: instance.Name}
+	for _, obj := range []client.Object{&v1.Service{}, &monitoringv1.ServiceMonitor{}} {
+		if err := c.Get(ctx, key, obj); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(obj, instance) {
+			continue
+		}
+		if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s/%s of source deployment: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
 func createServiceMonitorManifest(labels map[string]string, depName string, ns string, options mf.Option) (*mf.Manifest, error) {
 	var svU = &unstructured.Unstructured{}
 	var smU = &unstructured.Unstructured{}
@@ -3,8 +3,8 @@ package common
 import (
 	"context"
 	"fmt"
-	"os"
 
+	"github.com/openshift-knative/serverless-operator/pkg/images"
 	configv1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -15,14 +15,18 @@ import (
 
 var log = Log
 
-func Mutate(ks *servingv1alpha1.KnativeServing, c client.Client) error {
+// Mutate applies the OpenShift specific defaults to the given KnativeServing. The image
+// catalog is read through the given reader.
+func Mutate(ks *servingv1alpha1.KnativeServing, c client.Client, catalog client.Reader) error {
 	if err := ingress(ks, c); err != nil {
 		return fmt.Errorf("failed to configure ingress: %w", err)
 	}
 
 	configureLogURLTemplate(ks, c)
 	ensureCustomCerts(ks)
-	imagesFromEnviron(ks)
+	if err := imagesFromCatalog(ks, catalog); err != nil {
+		return fmt.Errorf("failed to resolve images: %w", err)
+	}
 	ensureServingWebhookMemoryLimit(ks)
 	defaultToHa(ks)
 	return nil
@@ -93,11 +97,15 @@ func ensureCustomCerts(ks *servingv1alpha1.KnativeServing) {
 	log.Info("ControllerCustomCerts", "certs", ks.Spec.ControllerCustomCerts)
 }
 
-// imagesFromEnviron overrides registry images
-func imagesFromEnviron(ks *servingv1alpha1.KnativeServing) {
-	ks.Spec.Registry.Override = BuildImageOverrideMapFromEnviron(os.Environ(), "IMAGE_")
+// imagesFromCatalog overrides registry images
+func imagesFromCatalog(ks *servingv1alpha1.KnativeServing, r client.Reader) error {
+	catalog, _, err := ImageCatalog(r, images.Prefix)
+	if err != nil {
+		return err
+	}
+	ks.Spec.Registry.Override = catalog
 
-	if defaultVal, ok := ks.Spec.Registry.Override["default"]; ok {
+	if defaultVal, ok := ks.Spec.Registry.Override[images.DefaultKey]; ok {
 		ks.Spec.Registry.Default = defaultVal
 	}
 
@@ -106,4 +114,5 @@ func imagesFromEnviron(ks *servingv1alpha1.KnativeServing) {
 		Configure(ks, "deployment", "queueSidecarImage", qpVal)
 	}
 	log.Info("Setting", "registry", ks.Spec.Registry)
+	return nil
 }
@@ -73,7 +73,7 @@ func TestMutate(t *testing.T) {
 			Build()
 		// Setup image override
 		// Mutate for OpenShift
-		err := common.Mutate(ks, client)
+		err := common.Mutate(ks, client, client)
 		if err != nil {
 			t.Error(err)
 		}
@@ -86,7 +86,7 @@ func TestMutate(t *testing.T) {
 		tc.ha(t, ks)
 
 		// Rerun, should be a noop
-		err = common.Mutate(ks, client)
+		err = common.Mutate(ks, client, client)
 		if err != nil {
 			t.Error(err)
 		}
@@ -102,7 +102,7 @@ func TestMutate(t *testing.T) {
 			"ingress.class":  "foo",
 			"domainTemplate": "{{.Name}}.{{.Namespace}}.{{Domain}}",
 		}
-		err = common.Mutate(ks, client)
+		err = common.Mutate(ks, client, client)
 		if err != nil {
 			t.Error(err)
 		}
@@ -178,7 +178,7 @@ func TestWebhookMemoryLimit(t *testing.T) {
 	client := fake.NewClientBuilder().WithObjects(mockIngressConfig("whatever")).Build()
 	for _, test := range tests {
 		t.Run(test.Input.Name, func(t *testing.T) {
-			err := common.Mutate(&test.Input, client)
+			err := common.Mutate(&test.Input, client, client)
 			if err != nil {
 				t.Error(err)
 			}
@@ -2,17 +2,23 @@ package sources
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
-	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -27,9 +33,13 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileSourceDeployment{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileSourceDeployment{client: mgr.GetClient(), scheme: mgr.GetScheme(), eventings: mgr.GetCache()}
 }
 
+// resyncPeriod is the period source deployments are reconciled at, which repairs drift of
+// resources that aren't watched, like the ServiceMonitors.
+const resyncPeriod = 10 * time.Minute
+
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
@@ -38,25 +48,83 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	// common function to enqueue reconcile requests for resources
-	enqueueRequests := handler.MapFunc(func(obj client.Object) []reconcile.Request {
-		dep := obj.(*v1.Deployment)
-		sourceLabel := dep.Spec.Selector.MatchLabels[common.SourceLabel]
-		sourceNameLabel := dep.Spec.Selector.MatchLabels[common.SourceNameLabel]
-		sourceRoleLabel := dep.Spec.Selector.MatchLabels[common.SourceRoleLabel]
+	// Enqueue deployments that are sources or stopped being one, so their monitoring
+	// resources are removed.
+	enqueue := func(obj client.Object, q workqueue.RateLimitingInterface) {
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}})
+	}
+	err = c.Watch(&source.Kind{Type: &v1.Deployment{}}, handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			if isSource(e.Object.(*v1.Deployment)) {
+				enqueue(e.Object, q)
+			}
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			if isSource(e.ObjectOld.(*v1.Deployment)) || isSource(e.ObjectNew.(*v1.Deployment)) {
+				enqueue(e.ObjectNew, q)
+			}
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			if dep, ok := e.Object.(*v1.Deployment); ok && isSource(dep) {
+				enqueue(e.Object, q)
+			}
+		},
+		GenericFunc: func(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			if isSource(e.Object.(*v1.Deployment)) {
+				enqueue(e.Object, q)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
 
-		if (sourceLabel != "" && sourceNameLabel != "") || (sourceLabel != "" && sourceRoleLabel != "") {
-			return []reconcile.Request{{
-				NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()},
-			}}
-		}
-		return nil
+	// Watch for changes to the Services created for the sources.
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &v1.Deployment{},
+		IsController: true,
 	})
-	err = c.Watch(&source.Kind{Type: &v1.Deployment{}}, handler.EnqueueRequestsFromMapFunc(enqueueRequests), skipDeletePredicate{}, skipUpdatePredicate{})
 	if err != nil {
 		return err
 	}
-	return nil
+
+	// Watch for changes to namespaces, whose labels select the monitored sources.
+	err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		return sourceRequests(mgr.GetClient(), client.InNamespace(obj.GetName()))
+	}))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to KnativeEventing, which configures the monitoring of all sources.
+	return c.Watch(&source.Kind{Type: &operatorv1alpha1.KnativeEventing{}}, handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return sourceRequests(mgr.GetClient())
+	}))
+}
+
+// sourceRequests returns the requests for the source deployments matching the given options.
+func sourceRequests(c client.Client, opts ...client.ListOption) []reconcile.Request {
+	deployments := &v1.DeploymentList{}
+	if err := c.List(context.TODO(), deployments, opts...); err != nil {
+		log.Error(err, "Failed to list source deployments")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range deployments.Items {
+		if dep := &deployments.Items[i]; isSource(dep) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: dep.Namespace, Name: dep.Name}})
+		}
+	}
+	return requests
+}
+
+// isSource returns true if the given deployment is the adapter of an eventing source.
+func isSource(dep *v1.Deployment) bool {
+	if dep.Spec.Selector == nil {
+		return false
+	}
+	labels := dep.Spec.Selector.MatchLabels
+	return labels[common.SourceLabel] != "" && (labels[common.SourceNameLabel] != "" || labels[common.SourceRoleLabel] != "")
 }
 
 // blank assignment to verify that ReconcileSourceDeployment implements reconcile.Reconciler
@@ -68,38 +136,122 @@ type ReconcileSourceDeployment struct {
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
 	scheme *runtime.Scheme
+	// eventings reads the KnativeEventings as unstructured, keeping their spec.openshift.
+	eventings client.Reader
 }
 
-// Reconcile reads that state of the cluster for an eventing source deployment
+// Reconcile reads that state of the cluster for an eventing source deployment. The
+// Service and ServiceMonitor of a source are kept up to date with its selector labels and
+// removed if it stops being a source or isn't monitored. Resources of deleted deployments
+// are garbage collected through their owner references. The platform monitoring set up in
+// a namespace is removed once no source in it uses it anymore.
 func (r *ReconcileSourceDeployment) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
-	reqLogger.Info("Reconciling the source deployment, setting up a service/service monitor if required")
+	settings, err := r.sourceMonitoring(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	dep := &v1.Deployment{}
-	if err := r.client.Get(context.TODO(), request.NamespacedName, dep); err != nil {
-		// the deployment does not exist anymore do nothing
-		return reconcile.Result{}, nil
+	if err := r.client.Get(ctx, request.NamespacedName, dep); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, r.cleanupNamespace(ctx, request.Namespace, settings)
+		}
+		return reconcile.Result{}, err
+	}
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: dep.Namespace}, ns); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
-	if err := common.SetupMonitoringRequirements(r.client, dep); err != nil {
+
+	if !isSource(dep) || !settings.monitors(ns) {
+		reqLogger.Info("Removing the source's service/service monitor if present")
+		if err := common.RemoveSourceServiceMonitorResources(r.client, dep); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, r.cleanupNamespace(ctx, dep.Namespace, settings)
+	}
+
+	reqLogger.Info("Reconciling the source deployment, setting up a service/service monitor")
+	if settings.usesPlatform(ns.Name) {
+		// The control plane's namespace is opted into cluster monitoring by Knative Eventing.
+		if err := common.SetupSourcePlatformMonitoring(r.client, dep, ns.Name != settings.controlPlane); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if err := r.cleanupNamespace(ctx, dep.Namespace, settings); err != nil {
 		return reconcile.Result{}, err
 	}
 	if err := common.SetupSourceServiceMonitorResources(r.client, dep); err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: resyncPeriod}, nil
 }
 
-type skipDeletePredicate struct {
-	predicate.Funcs
+// cleanupNamespace removes the platform monitoring of the given namespace if none of its
+// sources uses it anymore.
+func (r *ReconcileSourceDeployment) cleanupNamespace(ctx context.Context, namespace string, settings sourceMonitoring) error {
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if settings.monitors(ns) && settings.usesPlatform(namespace) {
+		deployments := &v1.DeploymentList{}
+		if err := r.client.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+			return fmt.Errorf("failed to list deployments in %s: %w", namespace, err)
+		}
+		for i := range deployments.Items {
+			if dep := &deployments.Items[i]; isSource(dep) && dep.DeletionTimestamp == nil {
+				return nil
+			}
+		}
+	}
+	log.Info("Removing the platform monitoring of sources", "namespace", namespace)
+	// The control plane's namespace is labelled by Knative Eventing.
+	return common.RemoveSourcePlatformMonitoring(r.client, namespace, namespace != settings.controlPlane)
+}
+
+// sourceMonitoring is the monitoring configured for sources by Knative Eventing.
+type sourceMonitoring struct {
+	monitoring.SourceMonitoring
+	// enabled is true if the metrics of Knative Eventing are scraped.
+	enabled bool
+	// controlPlane is the namespace of Knative Eventing.
+	controlPlane string
 }
 
-func (skipDeletePredicate) Delete(e event.DeleteEvent) bool {
-	return false
+func (s sourceMonitoring) monitors(ns *corev1.Namespace) bool {
+	return s.enabled && s.Monitors(ns, s.controlPlane)
 }
 
-type skipUpdatePredicate struct {
-	predicate.Funcs
+func (s sourceMonitoring) usesPlatform(ns string) bool {
+	return s.UsesPlatform(ns, s.controlPlane)
 }
 
-func (skipUpdatePredicate) Update(e event.UpdateEvent) bool {
-	return false
+// sourceMonitoring returns the monitoring configured for sources by the KnativeEventing.
+// Monitoring is enabled by default if there is no KnativeEventing. Invalid settings disable
+// it, they are reported on the KnativeEventing's status.
+func (r *ReconcileSourceDeployment) sourceMonitoring(ctx context.Context) (sourceMonitoring, error) {
+	ke, spec, err := common.KnativeEventingMonitoring(ctx, r.eventings)
+	if ke == nil && err != nil {
+		return sourceMonitoring{}, err
+	}
+	var config operatorv1alpha1.ConfigMapData
+	var controlPlane string
+	if ke != nil {
+		config = ke.Spec.GetConfig()
+		controlPlane = ke.Namespace
+	}
+	settings, settingsErr := monitoring.SourceMonitoringFrom(config)
+	if err == nil {
+		err = settingsErr
+	}
+	if err != nil {
+		log.Error(err, "Invalid source monitoring settings, disabling it")
+		return sourceMonitoring{SourceMonitoring: settings, controlPlane: controlPlane}, nil
+	}
+	return sourceMonitoring{
+		SourceMonitoring: settings,
+		enabled:          monitoring.ShouldEnableMonitoring(config, spec),
+		controlPlane:     controlPlane,
+	}, nil
 }
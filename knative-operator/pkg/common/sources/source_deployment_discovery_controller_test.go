@@ -7,12 +7,16 @@ import (
 
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -77,7 +81,7 @@ func TestSourceReconcile(t *testing.T) {
 		WithObjects(&apiserversourceDeployment, &pingsourceDeployment, &defaultNamespace, &eventingNamespace).
 		Build()
 
-	r := &ReconcileSourceDeployment{client: cl, scheme: scheme.Scheme}
+	r := &ReconcileSourceDeployment{client: cl, scheme: scheme.Scheme, eventings: cl}
 	// Reconcile for an api server source
 	if _, err := r.Reconcile(context.Background(), apiserverRequest); err != nil {
 		t.Fatalf("reconcile: (%v)", err)
@@ -122,3 +126,245 @@ func TestSourceReconcile(t *testing.T) {
 		t.Fatalf("got %q, want %q", smPing.Spec.Selector.MatchLabels["name"], "ping1")
 	}
 }
+
+// TestSourceReconcileDrift verifies that the monitoring resources follow the source deployment.
+func TestSourceReconcileDrift(t *testing.T) {
+	dep := apiserversourceDeployment.DeepCopy()
+	cl := fake.NewClientBuilder().WithObjects(dep, &defaultNamespace).Build()
+	r := &ReconcileSourceDeployment{client: cl, scheme: scheme.Scheme, eventings: cl}
+
+	result, err := r.Reconcile(context.Background(), apiserverRequest)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if result.RequeueAfter != resyncPeriod {
+		t.Errorf("got requeue after %v, want %v", result.RequeueAfter, resyncPeriod)
+	}
+
+	// Change the selector labels of the source.
+	if err := cl.Get(context.TODO(), apiserverRequest.NamespacedName, dep); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+	dep.Spec.Selector.MatchLabels[common.SourceNameLabel] = "api2"
+	if err := cl.Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update: (%v)", err)
+	}
+	if _, err := r.Reconcile(context.Background(), apiserverRequest); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	svc := &corev1.Service{}
+	if err := cl.Get(context.TODO(), apiserverRequest.NamespacedName, svc); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+	if svc.Spec.Selector[common.SourceNameLabel] != "api2" {
+		t.Fatalf("got %q, want %q", svc.Spec.Selector[common.SourceNameLabel], "api2")
+	}
+
+	// The deployment stops being a source.
+	if err := cl.Get(context.TODO(), apiserverRequest.NamespacedName, dep); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+	delete(dep.Spec.Selector.MatchLabels, common.SourceLabel)
+	if err := cl.Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update: (%v)", err)
+	}
+	if _, err := r.Reconcile(context.Background(), apiserverRequest); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err := cl.Get(context.TODO(), apiserverRequest.NamespacedName, &corev1.Service{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("got %v, want the service to be removed", err)
+	}
+	if err := cl.Get(context.TODO(), apiserverRequest.NamespacedName, &monitoringv1.ServiceMonitor{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("got %v, want the service monitor to be removed", err)
+	}
+}
+
+// TestSourceReconcileMonitoringDisabled verifies that no monitoring resources are kept for sources
+// if monitoring is disabled for Knative Eventing.
+func TestSourceReconcileMonitoringDisabled(t *testing.T) {
+	ke := &operatorv1alpha1.KnativeEventing{
+		ObjectMeta: metav1.ObjectMeta{Name: "knative-eventing", Namespace: "knative-eventing"},
+		Spec: operatorv1alpha1.KnativeEventingSpec{
+			CommonSpec: operatorv1alpha1.CommonSpec{
+				Config: operatorv1alpha1.ConfigMapData{
+					"observability": {"metrics.backend-destination": "none"},
+				},
+			},
+		},
+	}
+	// A service of the same name not created for the source.
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "ping1", Namespace: "knative-eventing"}}
+	cl := fake.NewClientBuilder().
+		WithObjects(pingsourceDeployment.DeepCopy(), &eventingNamespace, ke, svc).
+		Build()
+	r := &ReconcileSourceDeployment{client: cl, scheme: scheme.Scheme, eventings: cl}
+
+	if _, err := r.Reconcile(context.Background(), pingsourceRequest); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err := cl.Get(context.TODO(), pingsourceRequest.NamespacedName, &monitoringv1.ServiceMonitor{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("got %v, want no service monitor", err)
+	}
+	if err := cl.Get(context.TODO(), pingsourceRequest.NamespacedName, &corev1.Service{}); err != nil {
+		t.Fatalf("got %v, want the unrelated service to be kept", err)
+	}
+}
+
+// TestSourceReconcileMonitoringModes verifies how the namespaces of the sources are set up for
+// the different monitoring modes.
+func TestSourceReconcileMonitoringModes(t *testing.T) {
+	cases := []struct {
+		name         string
+		settings     map[string]string
+		request      reconcile.Request
+		wantMonitor  bool
+		wantLabel    bool
+		wantPlatform bool
+	}{{
+		name:        "user-workload in tenant namespace",
+		request:     apiserverRequest,
+		wantMonitor: true,
+	}, {
+		name:         "platform in tenant namespace",
+		settings:     map[string]string{monitoring.SourceMonitoringKey: monitoring.SourceMonitoringPlatform},
+		request:      apiserverRequest,
+		wantMonitor:  true,
+		wantLabel:    true,
+		wantPlatform: true,
+	}, {
+		name:         "control plane namespace",
+		request:      pingsourceRequest,
+		wantMonitor:  true,
+		wantPlatform: true,
+	}, {
+		name:     "denied namespace",
+		settings: map[string]string{monitoring.SourceMonitoringNamespaceSelectorKey: "team=a"},
+		request:  apiserverRequest,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ke := &operatorv1alpha1.KnativeEventing{
+				ObjectMeta: metav1.ObjectMeta{Name: "knative-eventing", Namespace: "knative-eventing"},
+				Spec: operatorv1alpha1.KnativeEventingSpec{
+					CommonSpec: operatorv1alpha1.CommonSpec{
+						Config: operatorv1alpha1.ConfigMapData{"openshift": c.settings},
+					},
+				},
+			}
+			cl := fake.NewClientBuilder().
+				WithObjects(apiserversourceDeployment.DeepCopy(), pingsourceDeployment.DeepCopy(),
+					defaultNamespace.DeepCopy(), eventingNamespace.DeepCopy(), ke).
+				Build()
+			r := &ReconcileSourceDeployment{client: cl, scheme: scheme.Scheme, eventings: cl}
+
+			if _, err := r.Reconcile(context.Background(), c.request); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			err := cl.Get(context.TODO(), c.request.NamespacedName, &monitoringv1.ServiceMonitor{})
+			if got := err == nil; got != c.wantMonitor {
+				t.Errorf("got service monitor %v, want %v", got, c.wantMonitor)
+			}
+			ns := &corev1.Namespace{}
+			if err := cl.Get(context.TODO(), types.NamespacedName{Name: c.request.Namespace}, ns); err != nil {
+				t.Fatalf("get: (%v)", err)
+			}
+			if got := ns.Labels["openshift.io/cluster-monitoring"] == "true"; got != c.wantLabel {
+				t.Errorf("got namespace label %v, want %v", got, c.wantLabel)
+			}
+			role := types.NamespacedName{Namespace: c.request.Namespace, Name: "knative-serving-prometheus-k8s"}
+			err = cl.Get(context.TODO(), role, &rbacv1.Role{})
+			if got := err == nil; got != c.wantPlatform {
+				t.Errorf("got role %v, want %v", got, c.wantPlatform)
+			}
+
+			// Removing the last source cleans up the namespace.
+			if err := cl.Delete(context.TODO(), &v1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Namespace: c.request.Namespace, Name: c.request.Name,
+			}}); err != nil {
+				t.Fatalf("delete: (%v)", err)
+			}
+			if _, err := r.Reconcile(context.Background(), c.request); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			ns = &corev1.Namespace{}
+			if err := cl.Get(context.TODO(), types.NamespacedName{Name: c.request.Namespace}, ns); err != nil {
+				t.Fatalf("get: (%v)", err)
+			}
+			if _, ok := ns.Labels["openshift.io/cluster-monitoring"]; ok {
+				t.Errorf("got namespace labels %v, want the monitoring label to be removed", ns.Labels)
+			}
+			if len(ns.Annotations) != 0 {
+				t.Errorf("got namespace annotations %v, want none", ns.Annotations)
+			}
+			if err := cl.Get(context.TODO(), role, &rbacv1.Role{}); !apierrors.IsNotFound(err) {
+				t.Errorf("got %v, want the role to be removed", err)
+			}
+		})
+	}
+}
+
+// TestSourceReconcileLegacyPlatformMonitoring verifies that the namespace label and RBAC set up
+// by older versions, which neither annotated the namespace nor labelled the RBAC, are removed.
+func TestSourceReconcileLegacyPlatformMonitoring(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings map[string]string
+	}{{
+		name: "user-workload",
+	}, {
+		name:     "platform",
+		settings: map[string]string{monitoring.SourceMonitoringKey: monitoring.SourceMonitoringPlatform},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ke := &operatorv1alpha1.KnativeEventing{
+				ObjectMeta: metav1.ObjectMeta{Name: "knative-eventing", Namespace: "knative-eventing"},
+				Spec: operatorv1alpha1.KnativeEventingSpec{
+					CommonSpec: operatorv1alpha1.CommonSpec{
+						Config: operatorv1alpha1.ConfigMapData{"openshift": c.settings},
+					},
+				},
+			}
+			ns := defaultNamespace.DeepCopy()
+			ns.Labels = map[string]string{"openshift.io/cluster-monitoring": "true"}
+			owner := []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "api1"}}
+			meta := metav1.ObjectMeta{Namespace: "default", Name: "knative-serving-prometheus-k8s", OwnerReferences: owner}
+			cl := fake.NewClientBuilder().
+				WithObjects(apiserversourceDeployment.DeepCopy(), ns, ke,
+					&rbacv1.Role{ObjectMeta: meta}, &rbacv1.RoleBinding{ObjectMeta: meta}).
+				Build()
+			r := &ReconcileSourceDeployment{client: cl, scheme: scheme.Scheme, eventings: cl}
+
+			if _, err := r.Reconcile(context.Background(), apiserverRequest); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			// Removing the last source cleans up the namespace in either mode.
+			if err := cl.Delete(context.TODO(), apiserversourceDeployment.DeepCopy()); err != nil {
+				t.Fatalf("delete: (%v)", err)
+			}
+			if _, err := r.Reconcile(context.Background(), apiserverRequest); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+
+			ns = &corev1.Namespace{}
+			if err := cl.Get(context.TODO(), types.NamespacedName{Name: "default"}, ns); err != nil {
+				t.Fatalf("get: (%v)", err)
+			}
+			if _, ok := ns.Labels["openshift.io/cluster-monitoring"]; ok {
+				t.Errorf("got namespace labels %v, want the monitoring label to be removed", ns.Labels)
+			}
+			if len(ns.Annotations) != 0 {
+				t.Errorf("got namespace annotations %v, want none", ns.Annotations)
+			}
+			key := types.NamespacedName{Namespace: "default", Name: "knative-serving-prometheus-k8s"}
+			if err := cl.Get(context.TODO(), key, &rbacv1.Role{}); !apierrors.IsNotFound(err) {
+				t.Errorf("got %v, want the role to be removed", err)
+			}
+			if err := cl.Get(context.TODO(), key, &rbacv1.RoleBinding{}); !apierrors.IsNotFound(err) {
+				t.Errorf("got %v, want the role binding to be removed", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package common
+
+import (
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// specExtensionPath is the path of the OpenShift specific settings in KnativeServing and
+// KnativeEventing. They aren't part of the upstream types, so they're missing from the
+// marshaled objects.
+const specExtensionPath = "/spec/openshift"
+
+// KeepSpecExtension drops the patches that would remove the OpenShift specific settings.
+func KeepSpecExtension(resp admission.Response) admission.Response {
+	patches := resp.Patches[:0]
+	for _, patch := range resp.Patches {
+		if patch.Path != specExtensionPath && !strings.HasPrefix(patch.Path, specExtensionPath+"/") {
+			patches = append(patches, patch)
+		}
+	}
+	resp.Patches = patches
+	if len(patches) == 0 {
+		resp.PatchType = nil
+	}
+	return resp
+}
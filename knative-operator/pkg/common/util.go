@@ -1,8 +1,6 @@
 package common
 
 import (
-	"strings"
-
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -39,33 +37,6 @@ func Configure(ks *operatorv1alpha1.KnativeServing, cm, key, value string) bool
 	return true
 }
 
-// BuildImageOverrideMapFromEnviron creates a map to overrides registry images
-func BuildImageOverrideMapFromEnviron(environ []string, prefix string) map[string]string {
-	overrideMap := map[string]string{}
-
-	for _, e := range environ {
-		pair := strings.SplitN(e, "=", 2)
-		if strings.HasPrefix(pair[0], prefix) {
-			// convert
-			// "IMAGE_container=docker.io/foo"
-			// "IMAGE_deployment__container=docker.io/foo2"
-			// "IMAGE_env_var=docker.io/foo3"
-			// "IMAGE_deployment__env_var=docker.io/foo4"
-			// to
-			// container: docker.io/foo
-			// deployment/container: docker.io/foo2
-			// env_var: docker.io/foo3
-			// deployment/env_var: docker.io/foo4
-			name := strings.TrimPrefix(pair[0], prefix)
-			name = strings.Replace(name, "__", "/", 1)
-			if pair[1] != "" {
-				overrideMap[name] = pair[1]
-			}
-		}
-	}
-	return overrideMap
-}
-
 // SetAnnotations is a transformer to set annotations on given object
 // The existing annotations are kept as is, except they are overridden with the
 // annotations given as the argument.
@@ -0,0 +1,378 @@
+// Package builder generates the Grafana dashboards shown in the OpenShift console from typed
+// panel definitions.
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConsoleDashboardLabel marks the ConfigMaps the console picks dashboards up from.
+	ConsoleDashboardLabel = "console.openshift.io/dashboard"
+
+	// datasource is the Prometheus datasource of the console.
+	datasource = "prometheus"
+	// gridWidth is the width of Grafana's grid.
+	gridWidth = 24
+)
+
+// PanelType is the visualization of a panel.
+type PanelType string
+
+const (
+	// Graph plots the targets over time.
+	Graph PanelType = "graph"
+	// SingleStat shows the current value of a single target.
+	SingleStat PanelType = "singlestat"
+)
+
+// Dashboard is a Grafana dashboard stored in a ConfigMap.
+type Dashboard struct {
+	// Name is the name of the ConfigMap.
+	Name string
+	// Key is the key of the dashboard's JSON in the ConfigMap.
+	Key string
+
+	UID   string
+	Title string
+	Tags  []string
+	// Refresh is the auto-refresh interval, no auto-refresh if empty.
+	Refresh string
+	// From is the start of the default time range, "now-6h" if empty.
+	From string
+
+	Variables []Variable
+	Rows      []Row
+}
+
+// Variable is a templating variable whose values are queried from Prometheus.
+type Variable struct {
+	Name  string
+	Label string
+	// Query is a Grafana label_values() query.
+	Query string
+}
+
+// Row groups panels. A row without title has no header.
+type Row struct {
+	Title  string
+	Panels []Panel
+}
+
+// Panel is a single visualization.
+type Panel struct {
+	Type        PanelType
+	Title       string
+	Description string
+	// Unit is the Grafana unit of the values, "short" if empty.
+	Unit     string
+	Decimals *int
+	// Width and Height in grid units. They default to 12x9 for graphs and 4x8 for single stats.
+	Width, Height int
+	Targets       []Target
+	// ValueMaps map the values of single stats to texts.
+	ValueMaps []ValueMap
+}
+
+// ValueMap shows a value as the given text.
+type ValueMap struct {
+	Value string
+	Text  string
+}
+
+// Target is a Prometheus query of a panel.
+type Target struct {
+	Expr   string
+	Legend string
+}
+
+// Decimals returns a pointer to the given number of decimals.
+func Decimals(d int) *int {
+	return &d
+}
+
+// ConfigMap returns the ConfigMap containing the given dashboard.
+func ConfigMap(d *Dashboard) (*corev1.ConfigMap, error) {
+	data, err := d.JSON()
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   d.Name,
+			Labels: map[string]string{ConsoleDashboardLabel: "true"},
+		},
+		Data: map[string]string{d.Key: data},
+	}, nil
+}
+
+// JSON returns the Grafana JSON model of the dashboard. Panels are laid out left to right
+// and wrap into a new line once the grid's width is exceeded.
+func (d *Dashboard) JSON() (string, error) {
+	from := d.From
+	if from == "" {
+		from = "now-6h"
+	}
+	model := dashboardModel{
+		Annotations:   annotationsModel{List: []interface{}{}},
+		Editable:      false,
+		Links:         []interface{}{},
+		Panels:        d.panels(),
+		Refresh:       d.Refresh,
+		SchemaVersion: 18,
+		Style:         "dark",
+		Tags:          d.Tags,
+		Templating:    templatingModel{List: d.variables()},
+		Time:          timeModel{From: from, To: "now"},
+		Title:         d.Title,
+		UID:           d.UID,
+		Version:       1,
+	}
+	if model.Tags == nil {
+		model.Tags = []string{}
+	}
+	b, err := json.MarshalIndent(model, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal dashboard %q: %w", d.Title, err)
+	}
+	return string(b), nil
+}
+
+func (d *Dashboard) panels() []panelModel {
+	var panels []panelModel
+	id, y := 1, 0
+	for _, row := range d.Rows {
+		if row.Title != "" {
+			panels = append(panels, panelModel{
+				ID:      id,
+				Type:    "row",
+				Title:   row.Title,
+				GridPos: gridPos{H: 1, W: gridWidth, X: 0, Y: y},
+				Panels:  []panelModel{},
+			})
+			id++
+			y++
+		}
+		x, lineHeight := 0, 0
+		for _, p := range row.Panels {
+			w, h := p.size()
+			if x+w > gridWidth {
+				x, y, lineHeight = 0, y+lineHeight, 0
+			}
+			panels = append(panels, p.model(id, gridPos{H: h, W: w, X: x, Y: y}))
+			id++
+			x += w
+			if h > lineHeight {
+				lineHeight = h
+			}
+		}
+		y += lineHeight
+	}
+	return panels
+}
+
+func (d *Dashboard) variables() []variableModel {
+	variables := make([]variableModel, 0, len(d.Variables))
+	for _, v := range d.Variables {
+		variables = append(variables, variableModel{
+			Current:    map[string]interface{}{},
+			Datasource: datasource,
+			Definition: v.Query,
+			Label:      v.Label,
+			Name:       v.Name,
+			Options:    []interface{}{},
+			Query:      v.Query,
+			Refresh:    2,
+			Sort:       1,
+			Type:       "query",
+		})
+	}
+	return variables
+}
+
+func (p Panel) size() (int, int) {
+	w, h := p.Width, p.Height
+	if w == 0 {
+		w = 12
+		if p.Type == SingleStat {
+			w = 4
+		}
+	}
+	if h == 0 {
+		h = 9
+		if p.Type == SingleStat {
+			h = 8
+		}
+	}
+	return w, h
+}
+
+func (p Panel) model(id int, pos gridPos) panelModel {
+	unit := p.Unit
+	if unit == "" {
+		unit = "short"
+	}
+	m := panelModel{
+		Datasource:  datasource,
+		Description: p.Description,
+		GridPos:     pos,
+		ID:          id,
+		Title:       p.Title,
+		Type:        string(p.Type),
+		Targets:     make([]targetModel, 0, len(p.Targets)),
+	}
+	for i, t := range p.Targets {
+		m.Targets = append(m.Targets, targetModel{
+			Expr:           t.Expr,
+			Format:         "time_series",
+			IntervalFactor: 1,
+			LegendFormat:   t.Legend,
+			RefID:          string(rune('A' + i)),
+		})
+	}
+	switch p.Type {
+	case Graph:
+		m.Fill = 1
+		m.Legend = &legendModel{Show: true, Values: false}
+		m.Lines = true
+		m.Linewidth = 1
+		m.Tooltip = &tooltipModel{Shared: true, Sort: 0, ValueType: "individual"}
+		m.XAxis = &xAxisModel{Mode: "time", Show: true}
+		m.YAxes = []yAxisModel{
+			{Decimals: p.Decimals, Format: unit, LogBase: 1, Show: true},
+			{Format: "short", LogBase: 1, Show: false},
+		}
+	case SingleStat:
+		m.Decimals = p.Decimals
+		m.Format = unit
+		m.Sparkline = &sparklineModel{Show: true}
+		m.ValueName = "current"
+		for _, v := range p.ValueMaps {
+			m.ValueMaps = append(m.ValueMaps, valueMapModel{Op: "=", Text: v.Text, Value: v.Value})
+		}
+	}
+	return m
+}
+
+// The models below are the subset of Grafana's JSON model the console renders.
+
+type dashboardModel struct {
+	Annotations   annotationsModel `json:"annotations"`
+	Editable      bool             `json:"editable"`
+	Links         []interface{}    `json:"links"`
+	Panels        []panelModel     `json:"panels"`
+	Refresh       string           `json:"refresh"`
+	SchemaVersion int              `json:"schemaVersion"`
+	Style         string           `json:"style"`
+	Tags          []string         `json:"tags"`
+	Templating    templatingModel  `json:"templating"`
+	Time          timeModel        `json:"time"`
+	Title         string           `json:"title"`
+	UID           string           `json:"uid"`
+	Version       int              `json:"version"`
+}
+
+type annotationsModel struct {
+	List []interface{} `json:"list"`
+}
+
+type templatingModel struct {
+	List []variableModel `json:"list"`
+}
+
+type timeModel struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type variableModel struct {
+	Current    map[string]interface{} `json:"current"`
+	Datasource string                 `json:"datasource"`
+	Definition string                 `json:"definition"`
+	Label      string                 `json:"label,omitempty"`
+	Name       string                 `json:"name"`
+	Options    []interface{}          `json:"options"`
+	Query      string                 `json:"query"`
+	Refresh    int                    `json:"refresh"`
+	Sort       int                    `json:"sort"`
+	Type       string                 `json:"type"`
+}
+
+type gridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type panelModel struct {
+	Datasource  string          `json:"datasource,omitempty"`
+	Decimals    *int            `json:"decimals,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Fill        int             `json:"fill,omitempty"`
+	Format      string          `json:"format,omitempty"`
+	GridPos     gridPos         `json:"gridPos"`
+	ID          int             `json:"id"`
+	Legend      *legendModel    `json:"legend,omitempty"`
+	Lines       bool            `json:"lines,omitempty"`
+	Linewidth   int             `json:"linewidth,omitempty"`
+	Panels      []panelModel    `json:"panels,omitempty"`
+	Sparkline   *sparklineModel `json:"sparkline,omitempty"`
+	Targets     []targetModel   `json:"targets,omitempty"`
+	Title       string          `json:"title"`
+	Tooltip     *tooltipModel   `json:"tooltip,omitempty"`
+	Type        string          `json:"type"`
+	ValueMaps   []valueMapModel `json:"valueMaps,omitempty"`
+	ValueName   string          `json:"valueName,omitempty"`
+	XAxis       *xAxisModel     `json:"xaxis,omitempty"`
+	YAxes       []yAxisModel    `json:"yaxes,omitempty"`
+}
+
+type targetModel struct {
+	Expr           string `json:"expr"`
+	Format         string `json:"format"`
+	IntervalFactor int    `json:"intervalFactor"`
+	LegendFormat   string `json:"legendFormat,omitempty"`
+	RefID          string `json:"refId"`
+}
+
+type legendModel struct {
+	Show   bool `json:"show"`
+	Values bool `json:"values"`
+}
+
+type tooltipModel struct {
+	Shared    bool   `json:"shared"`
+	Sort      int    `json:"sort"`
+	ValueType string `json:"value_type"`
+}
+
+type sparklineModel struct {
+	Show bool `json:"show"`
+}
+
+type valueMapModel struct {
+	Op    string `json:"op"`
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+type xAxisModel struct {
+	Mode string `json:"mode"`
+	Show bool   `json:"show"`
+}
+
+type yAxisModel struct {
+	Decimals *int   `json:"decimals,omitempty"`
+	Format   string `json:"format"`
+	LogBase  int    `json:"logBase"`
+	Show     bool   `json:"show"`
+}
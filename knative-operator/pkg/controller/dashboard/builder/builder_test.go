@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLayout(t *testing.T) {
+	d := &Dashboard{
+		Rows: []Row{{
+			Title: "first",
+			Panels: []Panel{
+				{Type: SingleStat},
+				{Type: Graph, Width: 20},
+				{Type: Graph},
+				{Type: Graph},
+			},
+		}, {
+			Panels: []Panel{{Type: Graph, Width: 24, Height: 4}},
+		}},
+	}
+
+	var got []gridPos
+	for _, p := range d.panels() {
+		got = append(got, p.GridPos)
+	}
+	want := []gridPos{
+		{H: 1, W: 24, X: 0, Y: 0},
+		{H: 8, W: 4, X: 0, Y: 1},
+		{H: 9, W: 20, X: 4, Y: 1},
+		{H: 9, W: 12, X: 0, Y: 10},
+		{H: 9, W: 12, X: 12, Y: 10},
+		{H: 4, W: 24, X: 0, Y: 19},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v, diff: %s", got, want, cmp.Diff(got, want))
+	}
+}
+
+func TestConfigMap(t *testing.T) {
+	cm, err := ConfigMap(&Dashboard{Name: "dashboard", Key: "dashboard.json", Title: "Test"})
+	if err != nil {
+		t.Fatal("ConfigMap() =", err)
+	}
+	if cm.Name != "dashboard" || cm.Labels[ConsoleDashboardLabel] != "true" {
+		t.Errorf("Got ConfigMap %v, want a console dashboard named dashboard", cm.ObjectMeta)
+	}
+	if _, ok := cm.Data["dashboard.json"]; !ok {
+		t.Errorf("Got data %v, want the dashboard at dashboard.json", cm.Data)
+	}
+}
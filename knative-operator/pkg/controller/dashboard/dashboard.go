@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 
-	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
-
 	mfc "github.com/manifestival/controller-runtime-client"
 	mf "github.com/manifestival/manifestival"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard/builder"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -18,13 +19,8 @@ var log = common.Log.WithName("dashboard")
 
 const ConfigManagedNamespace = "openshift-config-managed"
 
-const EventingResourceDashboardPathEnvVar = "EVENTING_RESOURCES_DASHBOARD_MANIFEST_PATH"
-const EventingBrokerDashboardPathEnvVar = "EVENTING_BROKER_DASHBOARD_MANIFEST_PATH"
-const EventingSourceDashboardPathEnvVar = "EVENTING_SOURCE_DASHBOARD_MANIFEST_PATH"
-const ServingResourceDashboardPathEnvVar = "SERVING_RESOURCES_DASHBOARD_MANIFEST_PATH"
-
-// Apply applies dashboard resources.
-func Apply(path string, instance operatorv1alpha1.KComponent, api client.Client) error {
+// Apply applies the given dashboards, annotated with their owner.
+func Apply(owner Owner, api client.Client, dashboards ...*builder.Dashboard) error {
 	err := api.Get(context.TODO(), client.ObjectKey{Name: ConfigManagedNamespace}, &corev1.Namespace{})
 	if apierrors.IsNotFound(err) {
 		log.Info(fmt.Sprintf("namespace %q not found. Skipping to create dashboard.", ConfigManagedNamespace))
@@ -32,11 +28,11 @@ func Apply(path string, instance operatorv1alpha1.KComponent, api client.Client)
 	} else if err != nil {
 		return fmt.Errorf("failed to get namespace %q: %w", ConfigManagedNamespace, err)
 	}
-	manifest, err := manifest(path, getAnnotationsFromInstance(instance), api)
+	manifest, err := manifest(dashboards, ownerAnnotations(owner), api)
 	if err != nil {
-		return fmt.Errorf("failed to load dashboard manifest: %w", err)
+		return fmt.Errorf("failed to build dashboard manifest: %w", err)
 	}
-	log.Info("Installing dashboard ", "path:", path)
+	log.Info("Installing dashboards", "dashboards", names(dashboards))
 	if err := manifest.Apply(); err != nil {
 		return fmt.Errorf("failed to apply dashboard manifest: %w", err)
 	}
@@ -44,12 +40,12 @@ func Apply(path string, instance operatorv1alpha1.KComponent, api client.Client)
 	return nil
 }
 
-// Delete deletes dashboard resources.
-func Delete(path string, instance operatorv1alpha1.KComponent, api client.Client) error {
-	log.Info("Deleting dashboard")
-	manifest, err := manifest(path, getAnnotationsFromInstance(instance), api)
+// Delete deletes the given dashboards.
+func Delete(owner Owner, api client.Client, dashboards ...*builder.Dashboard) error {
+	log.Info("Deleting dashboards", "dashboards", names(dashboards))
+	manifest, err := manifest(dashboards, ownerAnnotations(owner), api)
 	if err != nil {
-		return fmt.Errorf("failed to load dashboard manifest: %w", err)
+		return fmt.Errorf("failed to build dashboard manifest: %w", err)
 	}
 
 	if err := manifest.Delete(); err != nil {
@@ -58,11 +54,23 @@ func Delete(path string, instance operatorv1alpha1.KComponent, api client.Client
 	return nil
 }
 
-// manifest returns dashboard deploymnet resources manifest
-func manifest(path string, owner mf.Transformer, apiclient client.Client) (mf.Manifest, error) {
-	manifest, err := mfc.NewManifest(path, apiclient, mf.UseLogger(log.WithName("mf")))
+// manifest returns the manifest of the ConfigMaps of the given dashboards.
+func manifest(dashboards []*builder.Dashboard, owner mf.Transformer, apiclient client.Client) (mf.Manifest, error) {
+	resources := make([]unstructured.Unstructured, 0, len(dashboards))
+	for _, d := range dashboards {
+		cm, err := builder.ConfigMap(d)
+		if err != nil {
+			return mf.Manifest{}, err
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+		if err != nil {
+			return mf.Manifest{}, fmt.Errorf("failed to convert dashboard %q: %w", d.Name, err)
+		}
+		resources = append(resources, unstructured.Unstructured{Object: u})
+	}
+	manifest, err := mf.ManifestFrom(mf.Slice(resources), mf.UseClient(mfc.NewClient(apiclient)), mf.UseLogger(log.WithName("mf")))
 	if err != nil {
-		return mf.Manifest{}, fmt.Errorf("failed to read dashboard manifest: %w", err)
+		return mf.Manifest{}, fmt.Errorf("failed to create dashboard manifest: %w", err)
 	}
 
 	// set owner to watch events.
@@ -75,19 +83,19 @@ func manifest(path string, owner mf.Transformer, apiclient client.Client) (mf.Ma
 	return manifest, nil
 }
 
-func getAnnotationsFromInstance(instance operatorv1alpha1.KComponent) mf.Transformer {
-	var value interface{} = instance
-	switch v := value.(type) {
-	case operatorv1alpha1.KnativeEventing:
-		return common.SetAnnotations(map[string]string{
-			common.EventingOwnerName:      v.Name,
-			common.EventingOwnerNamespace: v.Namespace,
-		})
-	case operatorv1alpha1.KnativeServing:
-		return common.SetAnnotations(map[string]string{
-			common.ServingOwnerName:      v.Name,
-			common.ServingOwnerNamespace: v.Namespace,
-		})
+func names(dashboards []*builder.Dashboard) []string {
+	names := make([]string, 0, len(dashboards))
+	for _, d := range dashboards {
+		names = append(names, d.Name)
 	}
-	return nil
+	return names
+}
+
+// ownerAnnotations returns a transformer annotating the resources with the given owner.
+func ownerAnnotations(owner Owner) mf.Transformer {
+	name, namespace := owner.OwnerAnnotationKeys()
+	return common.SetAnnotations(map[string]string{
+		name:      owner.GetName(),
+		namespace: owner.GetNamespace(),
+	})
 }
@@ -0,0 +1,347 @@
+package dashboard
+
+import (
+	"fmt"
+
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard/builder"
+)
+
+// Metrics emitted by the eventing data plane.
+const (
+	brokerIngressEventCount      = "mt_broker_ingress_event_count"
+	brokerIngressDispatchLatency = "mt_broker_ingress_event_dispatch_latencies_bucket"
+	brokerFilterEventCount       = "mt_broker_filter_event_count"
+	brokerFilterDispatchLatency  = "mt_broker_filter_event_dispatch_latencies_bucket"
+	brokerFilterProcessLatency   = "mt_broker_filter_event_processing_latencies_bucket"
+	apiServerSourceEventCount    = "apiserversource_event_count"
+	pingSourceEventCount         = "pingsource_event_count"
+	kafkaSourceEventCount        = "kafkasource_event_count"
+	kafkaSourceDispatchLatency   = "kafkasource_event_dispatch_latencies_bucket"
+	kafkaChannelEventCount       = "kafka_ch_dispatcher_event_count"
+	kafkaChannelDispatchLatency  = "kafka_ch_dispatcher_event_dispatch_latencies_bucket"
+	kafkaConsumerGroupLag        = "kafka_consumergroup_lag"
+)
+
+// EventingComponents are the optional eventing components whose dashboards are installed.
+type EventingComponents struct {
+	// Broker is true if the multi-tenant broker is installed.
+	Broker bool
+	// KafkaSource is true if KnativeKafka installs the KafkaSource.
+	KafkaSource bool
+}
+
+// KafkaComponents are the components of KnativeKafka whose dashboards are installed.
+type KafkaComponents struct {
+	// Channel is true if the KafkaChannel is installed.
+	Channel bool
+	// Source is true if the KafkaSource is installed.
+	Source bool
+}
+
+// ServingDashboards returns the dashboards of Knative Serving.
+func ServingDashboards() []*builder.Dashboard {
+	return []*builder.Dashboard{servingResources()}
+}
+
+// EventingDashboards returns the dashboards of Knative Eventing that are installed with the
+// given components and those that have to be removed.
+func EventingDashboards(c EventingComponents) (enabled, disabled []*builder.Dashboard) {
+	enabled = []*builder.Dashboard{eventingResources(), eventingSources(c)}
+	if c.Broker {
+		enabled = append(enabled, eventingBroker())
+	} else {
+		disabled = append(disabled, eventingBroker())
+	}
+	return enabled, disabled
+}
+
+// KafkaDashboards returns the dashboards of KnativeKafka that are installed with the given
+// components and those that have to be removed.
+func KafkaDashboards(c KafkaComponents) (enabled, disabled []*builder.Dashboard) {
+	if c.Channel {
+		enabled = append(enabled, kafkaChannel())
+	} else {
+		disabled = append(disabled, kafkaChannel())
+	}
+	if c.Source {
+		enabled = append(enabled, kafkaSource())
+	} else {
+		disabled = append(disabled, kafkaSource())
+	}
+	return enabled, disabled
+}
+
+func servingResources() *builder.Dashboard {
+	return &builder.Dashboard{
+		Name:    "grafana-dashboard-definition-knative-serving-resources",
+		Key:     "resource-dashboard.json",
+		UID:     "bKOoE9Wmk",
+		Title:   "Knative Serving - Revision CPU and Memory Usage",
+		Tags:    []string{"Knative"},
+		Refresh: "5s",
+		From:    "now-15m",
+		Variables: []builder.Variable{{
+			Name:  "namespace",
+			Query: `label_values(kube_pod_labels{label_serving_knative_dev_configuration=~".+"}, namespace)`,
+		}, {
+			Name:  "configuration",
+			Query: `label_values(kube_pod_labels{label_serving_knative_dev_configuration=~".+", namespace="$namespace"}, label_serving_knative_dev_configuration)`,
+		}, {
+			Name:  "revision",
+			Query: `label_values(kube_pod_labels{label_serving_knative_dev_configuration=~".+", namespace="$namespace", label_serving_knative_dev_configuration="$configuration"}, label_serving_knative_dev_revision)`,
+		}, {
+			Name:  "deployment",
+			Query: `label_values(kube_deployment_labels{label_serving_knative_dev_configuration=~"$configuration", label_serving_knative_dev_revision="$revision", namespace="$namespace"}, deployment)`,
+		}},
+		Rows: []builder.Row{{
+			Panels: resourcePanels(`pod=~"$revision.*"`, `name=~"k8s_POD_$deployment.*"`),
+		}},
+	}
+}
+
+func eventingResources() *builder.Dashboard {
+	const sourcePods = `label_replace(kube_pod_labels{namespace="$namespace", label_eventing_knative_dev_source=~".+"}, "label_eventing_knative_dev_source", "$1$2", "label_eventing_knative_dev_source", "(.+)-(.+)-(controller)")`
+	return &builder.Dashboard{
+		Name:    "grafana-dashboard-definition-knative-eventing-resources",
+		Key:     "resource-dashboard.json",
+		UID:     "knative-eventing-resources",
+		Title:   "Knative Eventing - Source CPU and Memory Usage",
+		Tags:    []string{"Knative"},
+		Refresh: "5s",
+		From:    "now-15m",
+		Variables: []builder.Variable{{
+			Name:  "namespace",
+			Query: `label_values(kube_pod_labels{label_eventing_knative_dev_source=~".+"}, namespace)`,
+		}, {
+			Name:  "scontroller",
+			Query: `label_values(kube_pod_labels{label_eventing_knative_dev_source=~".+", namespace="$namespace"}, label_eventing_knative_dev_source)`,
+		}, {
+			Name:  "sprefix",
+			Label: "Source Type",
+			Query: fmt.Sprintf("label_values(%s, label_eventing_knative_dev_source)", sourcePods),
+		}, {
+			Name:  "source",
+			Query: `label_values(kube_pod_labels{label_eventing_knative_dev_source=~".+", label_eventing_knative_dev_sourceName=~".+", namespace="$namespace", label_eventing_knative_dev_source="$scontroller"}, label_eventing_knative_dev_sourceName)`,
+		}},
+		Rows: []builder.Row{{
+			Panels: resourcePanels(`pod=~"$sprefix-$source-.+|pingsource-mt-adapter-.+"`,
+				`name=~"k8s_POD_$sprefix-$source-.+|k8s_POD_pingsource-mt-adapter-.+"`),
+		}},
+	}
+}
+
+func eventingSources(c EventingComponents) *builder.Dashboard {
+	rows := []builder.Row{
+		eventCountRow("ApiServerSource", apiServerSourceEventCount, `namespace="$namespace"`),
+		eventCountRow("MT PingSource", pingSourceEventCount, `namespace="$namespace"`),
+	}
+	if c.KafkaSource {
+		rows = append(rows, eventCountRow("KafkaSource", kafkaSourceEventCount, `namespace="$namespace"`))
+	}
+	return &builder.Dashboard{
+		Name:  "grafana-dashboard-definition-knative-eventing-source",
+		Key:   "eventing-source-dashboard.json",
+		UID:   "-Vr2tYtZk",
+		Title: "Knative Eventing - Source",
+		Variables: []builder.Variable{{
+			Name:  "namespace",
+			Query: `label_values(kube_pod_labels{label_eventing_knative_dev_source=~".+"}, namespace)`,
+		}},
+		Rows: rows,
+	}
+}
+
+func eventingBroker() *builder.Dashboard {
+	const selector = `namespace_name=~"$namespace"`
+	ingress := eventCountRow("Broker (broker-ingress)", brokerIngressEventCount, selector)
+	ingress.Panels = append(ingress.Panels, builder.Panel{
+		Type:  builder.Graph,
+		Title: "Broker (broker-ingress): Event Count by Event Type (rate per minute)",
+		Unit:  "ops",
+		Targets: []builder.Target{{
+			Expr:   fmt.Sprintf("sum(rate(%s{%s}[1m])) by (event_type)", brokerIngressEventCount, selector),
+			Legend: "{{event_type}}",
+		}},
+	}, latencyPanel("Broker (broker-ingress): Event Dispatch Latency (ms)", brokerIngressDispatchLatency, selector))
+
+	filter := eventCountRow("Trigger (broker-filter)", brokerFilterEventCount, selector)
+	filter.Panels = append(filter.Panels,
+		latencyPanel("Trigger (broker-filter): Event Dispatch Latency (ms)", brokerFilterDispatchLatency, selector),
+		latencyPanel("Trigger (broker-filter): Event Processing Latency (ms)", brokerFilterProcessLatency, selector))
+
+	return &builder.Dashboard{
+		Name:  "grafana-dashboard-definition-knative-eventing-broker",
+		Key:   "eventing-broker-dashboard.json",
+		UID:   "e2WkUxtWz",
+		Title: "Knative Eventing - Broker/Trigger",
+		Variables: []builder.Variable{{
+			Name:  "namespace",
+			Query: fmt.Sprintf(`label_values(%s{namespace_name!="unknown"}, namespace_name)`, brokerIngressEventCount),
+		}},
+		Rows: []builder.Row{ingress, filter},
+	}
+}
+
+func kafkaChannel() *builder.Dashboard {
+	const selector = `namespace_name=~"$namespace"`
+	dispatcher := eventCountRow("KafkaChannel (dispatcher)", kafkaChannelEventCount, selector)
+	dispatcher.Panels = append(dispatcher.Panels,
+		latencyPanel("KafkaChannel (dispatcher): Event Dispatch Latency (ms)", kafkaChannelDispatchLatency, selector))
+
+	// The consumer groups of the subscriptions are named kafka.<namespace>.<channel>.<subscription>.
+	// Their lag isn't reported by the dispatcher but by the Kafka cluster's exporter.
+	const lagDescription = "Requires the Kafka exporter, for example the one deployed by Strimzi, " +
+		"to be scraped. The panel is empty otherwise."
+	lag := builder.Row{
+		Title: "Subscriptions",
+		Panels: []builder.Panel{{
+			Type:        builder.Graph,
+			Title:       "Consumer Lag by Subscription (messages)",
+			Description: lagDescription,
+			Targets: []builder.Target{{
+				Expr:   fmt.Sprintf(`sum(%s{consumergroup=~"kafka\\.$namespace\\..+"}) by (consumergroup)`, kafkaConsumerGroupLag),
+				Legend: "{{consumergroup}}",
+			}},
+		}, {
+			Type:        builder.Graph,
+			Title:       "Consumer Lag by Topic (messages)",
+			Description: lagDescription,
+			Targets: []builder.Target{{
+				Expr:   fmt.Sprintf(`sum(%s{consumergroup=~"kafka\\.$namespace\\..+"}) by (topic)`, kafkaConsumerGroupLag),
+				Legend: "{{topic}}",
+			}},
+		}},
+	}
+
+	return &builder.Dashboard{
+		Name:  "grafana-dashboard-definition-knative-kafka-channel",
+		Key:   "kafka-channel-dashboard.json",
+		UID:   "knative-kafka-channel",
+		Title: "Knative Kafka - Channel",
+		Variables: []builder.Variable{{
+			Name:  "namespace",
+			Query: fmt.Sprintf(`label_values(%s{namespace_name!="unknown"}, namespace_name)`, kafkaChannelEventCount),
+		}},
+		Rows: []builder.Row{dispatcher, lag},
+	}
+}
+
+func kafkaSource() *builder.Dashboard {
+	const selector = `namespace="$namespace"`
+	delivery := eventCountRow("KafkaSource", kafkaSourceEventCount, selector)
+	delivery.Panels = append(delivery.Panels,
+		latencyPanel("KafkaSource: Event Dispatch Latency (ms)", kafkaSourceDispatchLatency, selector))
+
+	return &builder.Dashboard{
+		Name:  "grafana-dashboard-definition-knative-kafka-source",
+		Key:   "kafka-source-dashboard.json",
+		UID:   "knative-kafka-source",
+		Title: "Knative Kafka - Source",
+		Variables: []builder.Variable{{
+			Name:  "namespace",
+			Query: fmt.Sprintf("label_values(%s, namespace)", kafkaSourceEventCount),
+		}},
+		Rows: []builder.Row{delivery},
+	}
+}
+
+// eventCountRow returns a row with the rate, success and failure rate of the events counted by
+// the given metric.
+func eventCountRow(title, metric, selector string) builder.Row {
+	rate := func(filter string) string {
+		return fmt.Sprintf("sum(rate(%s{%s%s}[1m]))", metric, selector, filter)
+	}
+	return builder.Row{
+		Title: title,
+		Panels: []builder.Panel{{
+			Type:     builder.SingleStat,
+			Title:    title + ": Event Count (rate per minute)",
+			Unit:     "ops",
+			Decimals: builder.Decimals(3),
+			Targets:  []builder.Target{{Expr: rate("")}},
+		}, {
+			Type:     builder.SingleStat,
+			Title:    title + ": Success Rate (2xx Event, fraction rate per minute)",
+			Unit:     "percentunit",
+			Decimals: builder.Decimals(2),
+			Targets:  []builder.Target{{Expr: rate(`, response_code_class="2xx"`) + " / " + rate("")}},
+		}, {
+			Type:     builder.SingleStat,
+			Title:    title + ": Failure Rate (non-2xx Event, fraction rate per minute)",
+			Unit:     "percentunit",
+			Decimals: builder.Decimals(2),
+			Targets:  []builder.Target{{Expr: rate(`, response_code_class!="2xx"`) + " / " + rate("")}},
+		}, {
+			Type:     builder.Graph,
+			Title:    title + ": Event Count by Response Code Class (rate per minute)",
+			Unit:     "ops",
+			Decimals: builder.Decimals(3),
+			Targets: []builder.Target{{
+				Expr:   rate("") + " by (response_code_class)",
+				Legend: "{{response_code_class}}",
+			}},
+		}},
+	}
+}
+
+// latencyPanel returns a graph of the percentiles of the given latency histogram.
+func latencyPanel(title, histogram, selector string) builder.Panel {
+	panel := builder.Panel{
+		Type:     builder.Graph,
+		Title:    title,
+		Unit:     "ms",
+		Decimals: builder.Decimals(3),
+	}
+	for _, q := range []string{"0.50", "0.90", "0.95", "0.99"} {
+		panel.Targets = append(panel.Targets, builder.Target{
+			Expr:   fmt.Sprintf("histogram_quantile(%s, sum(rate(%s{%s}[1m])) by (le))", q, histogram, selector),
+			Legend: "p" + q[2:],
+		})
+	}
+	return panel
+}
+
+// resourcePanels returns the CPU, memory and network usage of the selected pods. The network
+// is accounted to the pods' sandbox containers, which are selected by name.
+func resourcePanels(pods, sandboxes string) []builder.Panel {
+	containers := fmt.Sprintf(`namespace="$namespace", %s, container != "POD", container != ""`, pods)
+	network := fmt.Sprintf(`namespace="$namespace", container="POD", %s`, sandboxes)
+	return []builder.Panel{{
+		Type:  builder.Graph,
+		Title: "Total CPU Usage (rate per minute)",
+		Unit:  "s",
+		Targets: []builder.Target{{
+			Expr:   fmt.Sprintf("sum(rate(container_cpu_usage_seconds_total{%s}[1m])) by (container)", containers),
+			Legend: "{{container}}",
+		}},
+	}, {
+		Type:  builder.Graph,
+		Title: "Total Memory Usage (bytes)",
+		Unit:  "decbytes",
+		Targets: []builder.Target{{
+			Expr:   fmt.Sprintf("sum(container_memory_usage_bytes{%s}) by (container)", containers),
+			Legend: "{{container}}",
+		}},
+	}, {
+		Type:  builder.Graph,
+		Title: "Total Network I/O (rate per minute)",
+		Unit:  "decbytes",
+		Targets: []builder.Target{{
+			Expr:   fmt.Sprintf("sum(rate(container_network_receive_bytes_total{%s}[1m]))", network),
+			Legend: "received bytes",
+		}, {
+			Expr:   fmt.Sprintf("sum(rate(container_network_transmit_bytes_total{%s}[1m]))", network),
+			Legend: "transmitted bytes",
+		}},
+	}, {
+		Type:  builder.Graph,
+		Title: "Total Network Errors (rate per minute)",
+		Targets: []builder.Target{{
+			Expr:   fmt.Sprintf("sum(rate(container_network_receive_errors_total{%s}[1m]))", network),
+			Legend: "receive errors",
+		}, {
+			Expr:   fmt.Sprintf("sum(rate(container_network_transmit_errors_total{%s}[1m]))", network),
+			Legend: "transmit errors",
+		}},
+	}}
+}
@@ -0,0 +1,111 @@
+package dashboard
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard/builder"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the dashboards")
+
+func TestDashboardsGolden(t *testing.T) {
+	all := EventingComponents{Broker: true, KafkaSource: true}
+	enabled, _ := EventingDashboards(all)
+	withoutKafka, disabled := EventingDashboards(EventingComponents{})
+	kafka, _ := KafkaDashboards(KafkaComponents{Channel: true, Source: true})
+
+	cases := []struct {
+		golden    string
+		dashboard *builder.Dashboard
+	}{{
+		golden:    "serving-resources.json",
+		dashboard: ServingDashboards()[0],
+	}, {
+		golden:    "eventing-resources.json",
+		dashboard: enabled[0],
+	}, {
+		golden:    "eventing-source.json",
+		dashboard: enabled[1],
+	}, {
+		golden:    "eventing-source-without-kafka.json",
+		dashboard: withoutKafka[1],
+	}, {
+		golden:    "eventing-broker.json",
+		dashboard: enabled[2],
+	}, {
+		golden:    "kafka-channel.json",
+		dashboard: kafka[0],
+	}, {
+		golden:    "kafka-source.json",
+		dashboard: kafka[1],
+	}}
+
+	for _, c := range cases {
+		t.Run(c.golden, func(t *testing.T) {
+			got, err := c.dashboard.JSON()
+			if err != nil {
+				t.Fatal("JSON() =", err)
+			}
+			assertGolden(t, filepath.Join("testdata", c.golden), got)
+		})
+	}
+
+	if len(withoutKafka) != 2 || len(disabled) != 1 || disabled[0].Name != "grafana-dashboard-definition-knative-eventing-broker" {
+		t.Errorf("got enabled %v and disabled %v, want the broker dashboard to be disabled", names(withoutKafka), names(disabled))
+	}
+}
+
+func TestKafkaDashboards(t *testing.T) {
+	cases := []struct {
+		name         string
+		components   KafkaComponents
+		wantEnabled  []string
+		wantDisabled []string
+	}{{
+		name:         "channel and source",
+		components:   KafkaComponents{Channel: true, Source: true},
+		wantEnabled:  []string{"grafana-dashboard-definition-knative-kafka-channel", "grafana-dashboard-definition-knative-kafka-source"},
+		wantDisabled: []string{},
+	}, {
+		name:         "source only",
+		components:   KafkaComponents{Source: true},
+		wantEnabled:  []string{"grafana-dashboard-definition-knative-kafka-source"},
+		wantDisabled: []string{"grafana-dashboard-definition-knative-kafka-channel"},
+	}, {
+		name:         "none",
+		wantEnabled:  []string{},
+		wantDisabled: []string{"grafana-dashboard-definition-knative-kafka-channel", "grafana-dashboard-definition-knative-kafka-source"},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			enabled, disabled := KafkaDashboards(c.components)
+			if diff := cmp.Diff(c.wantEnabled, names(enabled)); diff != "" {
+				t.Errorf("enabled differs (-want, +got): %s", diff)
+			}
+			if diff := cmp.Diff(c.wantDisabled, names(disabled)); diff != "" {
+				t.Errorf("disabled differs (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func assertGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := ioutil.WriteFile(path, []byte(got+"\n"), 0o644); err != nil {
+			t.Fatal("failed to update golden file:", err)
+		}
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("failed to read golden file:", err)
+	}
+	if diff := cmp.Diff(string(want), got+"\n"); diff != "" {
+		t.Errorf("dashboard differs from %s, run the tests with -update to regenerate it (-want, +got): %s", path, diff)
+	}
+}
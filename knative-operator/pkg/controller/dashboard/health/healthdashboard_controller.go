@@ -3,12 +3,16 @@ package health
 import (
 	"context"
 
+	serverlessoperatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -17,6 +21,9 @@ import (
 
 var log = common.Log.WithName("health-controller")
 
+// healthDashboardName is the name of the health dashboard's ConfigMap.
+const healthDashboardName = "grafana-dashboard-definition-knative-health"
+
 // Add creates a new Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
@@ -40,6 +47,16 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	if err != nil {
 		return err
 	}
+
+	// Watch for the components coming and going, which adds or removes their panels.
+	enqueueDashboard := handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: common.ConfigManagedNamespace, Name: healthDashboardName}}}
+	})
+	for _, component := range []client.Object{&operatorv1alpha1.KnativeServing{}, &operatorv1alpha1.KnativeEventing{}, &serverlessoperatorv1alpha1.KnativeKafka{}} {
+		if err := c.Watch(&source.Kind{Type: component}, enqueueDashboard, componentPresencePredicate{}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -75,3 +92,16 @@ type skipCreatePredicate struct {
 func (skipCreatePredicate) Create(e event.CreateEvent) bool {
 	return false
 }
+
+// componentPresencePredicate only passes events of components being created or deleted.
+type componentPresencePredicate struct {
+	predicate.Funcs
+}
+
+func (componentPresencePredicate) Update(e event.UpdateEvent) bool {
+	return false
+}
+
+func (componentPresencePredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
@@ -0,0 +1,76 @@
+package dashboard
+
+import (
+	serverlessoperatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard/builder"
+	corev1 "k8s.io/api/core/v1"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Owner is a component CR owning dashboards. The dashboards live in another namespace, so
+// they reference their owner through annotations instead of owner references.
+type Owner interface {
+	client.Object
+	// OwnerAnnotationKeys returns the keys of the annotations referencing the owner's name
+	// and namespace.
+	OwnerAnnotationKeys() (name, namespace string)
+}
+
+// KnativeKafka owns the dashboards of the Kafka components itself.
+var _ Owner = (*serverlessoperatorv1alpha1.KnativeKafka)(nil)
+
+// servingOwner makes a KnativeServing an Owner.
+type servingOwner struct {
+	*operatorv1alpha1.KnativeServing
+}
+
+func (servingOwner) OwnerAnnotationKeys() (string, string) {
+	return common.ServingOwnerName, common.ServingOwnerNamespace
+}
+
+// ServingOwner returns the given KnativeServing as the owner of dashboards.
+func ServingOwner(ks *operatorv1alpha1.KnativeServing) Owner {
+	return servingOwner{ks}
+}
+
+// eventingOwner makes a KnativeEventing an Owner.
+type eventingOwner struct {
+	*operatorv1alpha1.KnativeEventing
+}
+
+func (eventingOwner) OwnerAnnotationKeys() (string, string) {
+	return common.EventingOwnerName, common.EventingOwnerNamespace
+}
+
+// EventingOwner returns the given KnativeEventing as the owner of dashboards.
+func EventingOwner(ke *operatorv1alpha1.KnativeEventing) Owner {
+	return eventingOwner{ke}
+}
+
+// Watch enqueues the owners of the dashboards that are changed or deleted, so their
+// reconciliation restores them. The owner only selects the annotations to follow.
+func Watch(c controller.Controller, owner Owner) error {
+	name, namespace := owner.OwnerAnnotationKeys()
+	return c.Watch(&source.Kind{Type: &corev1.ConfigMap{}},
+		common.EnqueueRequestByOwnerAnnotations(name, namespace),
+		predicate.NewPredicateFuncs(isDashboard), skipCreatePredicate{})
+}
+
+func isDashboard(obj client.Object) bool {
+	return obj.GetNamespace() == ConfigManagedNamespace && obj.GetLabels()[builder.ConsoleDashboardLabel] == "true"
+}
+
+type skipCreatePredicate struct {
+	predicate.Funcs
+}
+
+// since the operator creates the dashboards there is no need to process their creation
+func (skipCreatePredicate) Create(event.CreateEvent) bool {
+	return false
+}
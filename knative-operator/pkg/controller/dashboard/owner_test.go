@@ -0,0 +1,111 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis"
+	serverlessoperatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func init() {
+	apis.AddToScheme(scheme.Scheme)
+}
+
+func TestOwnerAnnotations(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "instance", Namespace: "knative-test"}
+	cases := []struct {
+		name          string
+		owner         Owner
+		wantName      string
+		wantNamespace string
+	}{{
+		name:          "KnativeServing",
+		owner:         ServingOwner(&operatorv1alpha1.KnativeServing{ObjectMeta: meta}),
+		wantName:      common.ServingOwnerName,
+		wantNamespace: common.ServingOwnerNamespace,
+	}, {
+		name:          "KnativeEventing",
+		owner:         EventingOwner(&operatorv1alpha1.KnativeEventing{ObjectMeta: meta}),
+		wantName:      common.EventingOwnerName,
+		wantNamespace: common.EventingOwnerNamespace,
+	}, {
+		name:          "KnativeKafka",
+		owner:         &serverlessoperatorv1alpha1.KnativeKafka{ObjectMeta: meta},
+		wantName:      common.KafkaOwnerName,
+		wantNamespace: common.KafkaOwnerNamespace,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ConfigManagedNamespace}}
+			cl := fake.NewClientBuilder().WithObjects(ns).Build()
+			d := ServingDashboards()[0]
+			if err := Apply(c.owner, cl, d); err != nil {
+				t.Fatal("Apply() =", err)
+			}
+
+			cm := &corev1.ConfigMap{}
+			if err := cl.Get(context.Background(), types.NamespacedName{Namespace: ConfigManagedNamespace, Name: d.Name}, cm); err != nil {
+				t.Fatal("failed to get dashboard:", err)
+			}
+			if got := cm.Annotations[c.wantName]; got != meta.Name {
+				t.Errorf("got owner name %q, want %q", got, meta.Name)
+			}
+			if got := cm.Annotations[c.wantNamespace]; got != meta.Namespace {
+				t.Errorf("got owner namespace %q, want %q", got, meta.Namespace)
+			}
+
+			// Deleting the dashboard enqueues its owner, which restores it.
+			if !isDashboard(cm) {
+				t.Error("isDashboard() = false, want the ConfigMap to be watched")
+			}
+			name, namespace := c.owner.OwnerAnnotationKeys()
+			queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer queue.ShutDown()
+			common.EnqueueRequestByOwnerAnnotations(name, namespace).Delete(event.DeleteEvent{Object: cm}, queue)
+			if queue.Len() != 1 {
+				t.Fatalf("got %d requests, want the owner to be enqueued", queue.Len())
+			}
+			item, _ := queue.Get()
+			want := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: meta.Namespace, Name: meta.Name}}
+			if item != want {
+				t.Errorf("got request %v, want %v", item, want)
+			}
+
+			if err := Delete(c.owner, cl, d); err != nil {
+				t.Fatal("Delete() =", err)
+			}
+		})
+	}
+}
+
+func TestIsDashboard(t *testing.T) {
+	dashboard := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: ConfigManagedNamespace,
+		Labels:    map[string]string{"console.openshift.io/dashboard": "true"},
+	}}
+	if !isDashboard(dashboard) {
+		t.Error("isDashboard() = false, want true for a dashboard")
+	}
+	other := dashboard.DeepCopy()
+	other.Namespace = "knative-serving"
+	if isDashboard(other) {
+		t.Error("isDashboard() = true, want false outside of the config-managed namespace")
+	}
+	unlabelled := dashboard.DeepCopy()
+	unlabelled.Labels = nil
+	if isDashboard(unlabelled) {
+		t.Error("isDashboard() = true, want false without the console label")
+	}
+}
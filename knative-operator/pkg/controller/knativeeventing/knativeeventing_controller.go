@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 
+	operatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -15,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	eventingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -29,6 +32,9 @@ const (
 	finalizerName = "knative-eventing-openshift"
 
 	requiredNsEnvName = "REQUIRED_EVENTING_NAMESPACE"
+
+	// brokerIngressName is the deployment of the multi-tenant broker's ingress.
+	brokerIngressName = "mt-broker-ingress"
 )
 
 var log = common.Log.WithName("controller")
@@ -36,11 +42,15 @@ var log = common.Log.WithName("controller")
 // Add creates a new KnativeEventing Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	catalog, err := common.ImageCatalogCache(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, newReconciler(mgr, catalog), catalog)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, catalog cache.Cache) reconcile.Reconciler {
 	client := mgr.GetClient()
 
 	// Create required namespace first.
@@ -51,27 +61,73 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 	}
 
 	return &ReconcileKnativeEventing{
-		client: client,
-		scheme: mgr.GetScheme(),
+		client:  client,
+		catalog: catalog,
+		scheme:  mgr.GetScheme(),
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, catalog cache.Cache) error {
 	// Create a new controller
-	c, err := controller.New("knativeeventing-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("knativeeventing-controller", mgr, controller.Options{Reconciler: common.InstrumentReconciler("eventing_status", r)})
 	if err != nil {
 		return err
 	}
 
 	// Watch for changes to primary resource KnativeEventing
 	requiredNs := os.Getenv(requiredNsEnvName)
-	return c.Watch(&source.Kind{Type: &eventingv1alpha1.KnativeEventing{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+	err = c.Watch(&source.Kind{Type: &eventingv1alpha1.KnativeEventing{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(func(obj client.Object) bool {
 		if requiredNs == "" {
 			return true
 		}
 		return obj.GetNamespace() == requiredNs
 	}))
+	if err != nil {
+		return err
+	}
+
+	// Watch for the broker coming and going, which adds or removes its dashboard.
+	err = c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		if obj.GetName() != brokerIngressName {
+			return nil
+		}
+		return knativeEventingRequests(mgr.GetClient(), client.InNamespace(obj.GetNamespace()))
+	}))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to KnativeKafka, which adds or removes the KafkaSource's panels.
+	err = c.Watch(&source.Kind{Type: &operatorv1alpha1.KnativeKafka{}}, handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return knativeEventingRequests(mgr.GetClient())
+	}))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the dashboards, which are restored if they're edited or deleted.
+	err = dashboard.Watch(c, dashboard.EventingOwner(&eventingv1alpha1.KnativeEventing{}))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the image catalog, which affects all KnativeEventings.
+	return c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, catalog), common.EnqueueAllOnImageCatalogChange(mgr.GetClient(), &eventingv1alpha1.KnativeEventingList{}))
+}
+
+// knativeEventingRequests returns the requests for the KnativeEventings matching the given options.
+func knativeEventingRequests(c client.Client, opts ...client.ListOption) []reconcile.Request {
+	list := &eventingv1alpha1.KnativeEventingList{}
+	if err := c.List(context.TODO(), list, opts...); err != nil {
+		log.Error(err, "Failed to list KnativeEventings")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, ke := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ke.Namespace, Name: ke.Name}})
+	}
+	return requests
 }
 
 // blank assignment to verify that ReconcileKnativeEventing implements reconcile.Reconciler
@@ -82,7 +138,9 @@ type ReconcileKnativeEventing struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
-	scheme *runtime.Scheme
+	// catalog reads the image catalog from the operator's namespace.
+	catalog client.Reader
+	scheme  *runtime.Scheme
 }
 
 // Reconcile reads that state of the cluster for a KnativeEventing
@@ -118,6 +176,7 @@ func (r *ReconcileKnativeEventing) Reconcile(ctx context.Context, request reconc
 	} else {
 		common.KnativeEventingUpG.Set(0)
 	}
+	common.SetConditionMetrics("eventing_status", instance.Status.Status)
 	return reconcile.Result{}, reconcileErr
 }
 
@@ -138,14 +197,17 @@ func (r *ReconcileKnativeEventing) reconcileKnativeEventing(instance *eventingv1
 // configure default settings for OpenShift
 func (r *ReconcileKnativeEventing) configure(instance *eventingv1alpha1.KnativeEventing) error {
 	before := instance.DeepCopy()
-	common.MutateEventing(instance)
+	if err := common.MutateEventing(instance, r.catalog); err != nil {
+		return err
+	}
 	if equality.Semantic.DeepEqual(before.Spec, instance.Spec) {
 		return nil
 	}
 
-	// Only apply the update if something changed.
+	// Only apply the update if something changed. A patch keeps the fields in the
+	// resource the typed client doesn't know about, like spec.openshift.
 	log.Info("Updating KnativeEventing with mutated state for Openshift")
-	if err := r.client.Update(context.TODO(), instance); err != nil {
+	if err := r.client.Patch(context.TODO(), instance, client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{})); err != nil {
 		return fmt.Errorf("failed to update KnativeEventing with mutated state: %w", err)
 	}
 	return nil
@@ -159,25 +221,51 @@ func (r *ReconcileKnativeEventing) ensureFinalizers(instance *eventingv1alpha1.K
 		}
 	}
 	log.Info("Adding finalizer")
+	before := instance.DeepCopy()
 	instance.SetFinalizers(append(instance.GetFinalizers(), finalizerName))
-	return r.client.Update(context.TODO(), instance)
+	return r.client.Patch(context.TODO(), instance, client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{}))
 }
 
 // installDashboard installs dashboard for OpenShift webconsole
 func (r *ReconcileKnativeEventing) installDashboards(instance *eventingv1alpha1.KnativeEventing) error {
 	log.Info("Installing Eventing Dashboards")
-	if err := dashboard.Apply(os.Getenv(dashboard.EventingResourceDashboardPathEnvVar), instance, r.client); err != nil {
+	components, err := r.eventingComponents(instance)
+	if err != nil {
 		return err
 	}
-	if err := dashboard.Apply(os.Getenv(dashboard.EventingBrokerDashboardPathEnvVar), instance, r.client); err != nil {
+	enabled, disabled := dashboard.EventingDashboards(components)
+	if err := dashboard.Apply(dashboard.EventingOwner(instance), r.client, enabled...); err != nil {
 		return err
 	}
-	return dashboard.Apply(os.Getenv(dashboard.EventingSourceDashboardPathEnvVar), instance, r.client)
+	return dashboard.Delete(dashboard.EventingOwner(instance), r.client, disabled...)
+}
+
+// eventingComponents returns the optional components installed with the given instance.
+func (r *ReconcileKnativeEventing) eventingComponents(instance *eventingv1alpha1.KnativeEventing) (dashboard.EventingComponents, error) {
+	components := dashboard.EventingComponents{}
+
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: instance.Namespace, Name: brokerIngressName}, &appsv1.Deployment{})
+	if err != nil && !errors.IsNotFound(err) {
+		return components, fmt.Errorf("failed to get deployment %q: %w", brokerIngressName, err)
+	}
+	components.Broker = err == nil
+
+	kafkas := &operatorv1alpha1.KnativeKafkaList{}
+	if err := r.client.List(context.TODO(), kafkas); err != nil {
+		return components, fmt.Errorf("failed to list KnativeKafkas: %w", err)
+	}
+	for _, kafka := range kafkas.Items {
+		if kafka.DeletionTimestamp == nil && kafka.Spec.Source.Enabled {
+			components.KafkaSource = true
+		}
+	}
+	return components, nil
 }
 
 // general clean-up, mostly resources in different namespaces from eventingv1alpha1.KnativeEventing.
 func (r *ReconcileKnativeEventing) delete(instance *eventingv1alpha1.KnativeEventing) error {
 	defer common.KnativeUp.DeleteLabelValues("eventing_status")
+	defer common.DeleteConditionMetrics("eventing_status", instance.Status.Status)
 	finalizers := sets.NewString(instance.GetFinalizers()...)
 
 	if !finalizers.Has(finalizerName) {
@@ -186,14 +274,9 @@ func (r *ReconcileKnativeEventing) delete(instance *eventingv1alpha1.KnativeEven
 	}
 	log.Info("Running cleanup logic")
 	log.Info("Deleting eventing dashboards")
-	if err := dashboard.Delete(os.Getenv(dashboard.EventingResourceDashboardPathEnvVar), instance, r.client); err != nil {
-		return fmt.Errorf("failed to delete resource dashboard configmap: %w", err)
-	}
-	if err := dashboard.Delete(os.Getenv(dashboard.EventingBrokerDashboardPathEnvVar), instance, r.client); err != nil {
-		return fmt.Errorf("failed to delete broker dashboard configmap: %w", err)
-	}
-	if err := dashboard.Delete(os.Getenv(dashboard.EventingSourceDashboardPathEnvVar), instance, r.client); err != nil {
-		return fmt.Errorf("failed to delete source dashboard configmap: %w", err)
+	enabled, disabled := dashboard.EventingDashboards(dashboard.EventingComponents{})
+	if err := dashboard.Delete(dashboard.EventingOwner(instance), r.client, append(enabled, disabled...)...); err != nil {
+		return fmt.Errorf("failed to delete dashboard configmaps: %w", err)
 	}
 	// The above might take a while, so we refetch the resource again in case it has changed.
 	refetched := &eventingv1alpha1.KnativeEventing{}
@@ -202,11 +285,12 @@ func (r *ReconcileKnativeEventing) delete(instance *eventingv1alpha1.KnativeEven
 	}
 
 	// Update the refetched finalizer list.
+	before := refetched.DeepCopy()
 	finalizers = sets.NewString(refetched.GetFinalizers()...)
 	finalizers.Delete(finalizerName)
 	refetched.SetFinalizers(finalizers.List())
 
-	if err := r.client.Update(context.TODO(), refetched); err != nil {
+	if err := r.client.Patch(context.TODO(), refetched, client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{})); err != nil {
 		return fmt.Errorf("failed to update KnativeEventing with removed finalizer: %w", err)
 	}
 	return nil
@@ -3,10 +3,14 @@ package knativeeventing
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis"
+	operatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,13 +41,16 @@ var (
 			Name: "knative-eventing",
 		},
 	}
+	brokerIngress = &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      brokerIngressName,
+			Namespace: "knative-eventing",
+		},
+	}
 )
 
 func init() {
 	os.Setenv("OPERATOR_NAME", "TEST_OPERATOR")
-	os.Setenv(dashboard.EventingSourceDashboardPathEnvVar, "../dashboard/testdata/grafana-dash-knative-eventing-source.yaml")
-	os.Setenv(dashboard.EventingBrokerDashboardPathEnvVar, "../dashboard/testdata/grafana-dash-knative-eventing-broker.yaml")
-	os.Setenv(dashboard.EventingResourceDashboardPathEnvVar, "../dashboard/testdata/grafana-dash-knative-eventing-resources.yaml")
 
 	apis.AddToScheme(scheme.Scheme)
 }
@@ -74,8 +81,8 @@ func TestEventingReconcile(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			cl := fake.NewClientBuilder().WithObjects(ke, dashboardNamespace, eventingNamespace).Build()
-			r := &ReconcileKnativeEventing{client: cl, scheme: scheme.Scheme}
+			cl := fake.NewClientBuilder().WithObjects(ke, dashboardNamespace, eventingNamespace, brokerIngress).Build()
+			r := &ReconcileKnativeEventing{client: cl, catalog: cl, scheme: scheme.Scheme}
 			// Reconcile to initialize
 			if _, err := r.Reconcile(context.Background(), req); err != nil {
 				t.Fatalf("reconcile: (%v)", err)
@@ -139,3 +146,52 @@ func TestEventingReconcile(t *testing.T) {
 		})
 	}
 }
+
+// TestEventingDashboardsFollowComponents verifies that the dashboards adapt to the installed components.
+func TestEventingDashboardsFollowComponents(t *testing.T) {
+	cl := fake.NewClientBuilder().WithObjects(ke.DeepCopy(), dashboardNamespace, eventingNamespace, brokerIngress.DeepCopy()).Build()
+	r := &ReconcileKnativeEventing{client: cl, catalog: cl, scheme: scheme.Scheme}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	brokerKey := types.NamespacedName{Name: "grafana-dashboard-definition-knative-eventing-broker", Namespace: dashboardNamespace.Name}
+	if err := cl.Get(context.TODO(), brokerKey, &corev1.ConfigMap{}); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+	sourceKey := types.NamespacedName{Name: "grafana-dashboard-definition-knative-eventing-source", Namespace: dashboardNamespace.Name}
+	sourceCM := &corev1.ConfigMap{}
+	if err := cl.Get(context.TODO(), sourceKey, sourceCM); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+	if strings.Contains(sourceCM.Data["eventing-source-dashboard.json"], "KafkaSource") {
+		t.Error("got KafkaSource panels, want none without KnativeKafka")
+	}
+	if sourceCM.Annotations[common.EventingOwnerName] != ke.Name || sourceCM.Annotations[common.EventingOwnerNamespace] != ke.Namespace {
+		t.Errorf("got annotations %v, want the KnativeEventing's owner annotations", sourceCM.Annotations)
+	}
+
+	// Remove the broker and install the KafkaSource.
+	if err := cl.Delete(context.TODO(), brokerIngress.DeepCopy()); err != nil {
+		t.Fatalf("delete: (%v)", err)
+	}
+	kafka := &operatorv1alpha1.KnativeKafka{
+		ObjectMeta: metav1.ObjectMeta{Name: "knative-kafka", Namespace: "knative-eventing"},
+		Spec:       operatorv1alpha1.KnativeKafkaSpec{Source: operatorv1alpha1.Source{Enabled: true}},
+	}
+	if err := cl.Create(context.TODO(), kafka); err != nil {
+		t.Fatalf("create: (%v)", err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err := cl.Get(context.TODO(), brokerKey, &corev1.ConfigMap{}); !errors.IsNotFound(err) {
+		t.Fatalf("got %v, want the broker dashboard to be removed", err)
+	}
+	sourceCM = &corev1.ConfigMap{}
+	if err := cl.Get(context.TODO(), sourceKey, sourceCM); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+	if !strings.Contains(sourceCM.Data["eventing-source-dashboard.json"], "KafkaSource") {
+		t.Error("got no KafkaSource panels, want them with KnativeKafka")
+	}
+}
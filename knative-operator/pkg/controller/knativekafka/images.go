@@ -3,12 +3,10 @@ package knativekafka
 import (
 	"github.com/go-logr/logr"
 	mf "github.com/manifestival/manifestival"
-	appsv1 "k8s.io/api/apps/v1"
-	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var delimiter = "/"
@@ -52,74 +50,66 @@ func ImageTransform(overrideMap map[string]string, log logr.Logger) mf.Transform
 	return ResourceImageTransformer(rit, log)
 }
 
+// podSpecablePath is the path to the PodSpec of all resources of the PodSpecable duck type.
+var podSpecablePath = []string{"spec", "template", "spec"}
+
+// podSpecPaths maps the kinds of workloads to the path of their PodSpec.
+var podSpecPaths = map[string][]string{
+	"Deployment":  podSpecablePath,
+	"DaemonSet":   podSpecablePath,
+	"StatefulSet": podSpecablePath,
+	"ReplicaSet":  podSpecablePath,
+	"Job":         podSpecablePath,
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
 // ResourceImageTransformer takes an ImageTransformer and transform images across resources
 func ResourceImageTransformer(imageTransformer ImageTransformer, log logr.Logger) mf.Transformer {
 	return func(u *unstructured.Unstructured) error {
-		switch u.GetKind() {
-		// TODO need to use PodSpecable duck type in order to remove duplicates of deployment, daemonSet
-		case "Deployment":
-			return updateDeployment(imageTransformer, u, log)
-		case "DaemonSet":
-			return updateDaemonSet(imageTransformer, u, log)
-		case "Job":
-			return updateJob(imageTransformer, u, log)
+		path, ok := podSpecPath(u)
+		if !ok {
+			return nil
 		}
-		return nil
+		return updatePodSpec(imageTransformer, u, path, log)
 	}
 }
 
-func updateDeployment(imageTransformer ImageTransformer, u *unstructured.Unstructured, log logr.Logger) error {
-	var deployment = &appsv1.Deployment{}
-	if err := scheme.Scheme.Convert(u, deployment, nil); err != nil {
-		log.Error(err, "Error converting Unstructured to Deployment", "unstructured", u, "deployment", deployment)
-		return err
-	}
-
-	updateRegistry(&deployment.Spec.Template.Spec, imageTransformer, log, deployment.GetName())
-	if err := scheme.Scheme.Convert(deployment, u, nil); err != nil {
-		return err
+// podSpecPath returns the path to the PodSpec of the given resource, if it has one.
+func podSpecPath(u *unstructured.Unstructured) ([]string, bool) {
+	if u.GetKind() == "Service" {
+		// Only Knative Services carry a PodSpec, Kubernetes Services don't.
+		return podSpecablePath, u.GroupVersionKind().Group == "serving.knative.dev"
 	}
-	// The zero-value timestamp defaulted by the conversion causes
-	// superfluous updates
-	u.SetCreationTimestamp(metav1.Time{})
-
-	log.Info("Finished conversion", "name", u.GetName(), "unstructured", u.Object)
-	return nil
+	path, ok := podSpecPaths[u.GetKind()]
+	return path, ok
 }
 
-func updateDaemonSet(imageTransformer ImageTransformer, u *unstructured.Unstructured, log logr.Logger) error {
-	var daemonSet = &appsv1.DaemonSet{}
-	if err := scheme.Scheme.Convert(u, daemonSet, nil); err != nil {
-		log.Error(err, "Error converting Unstructured to daemonSet", "unstructured", u, "daemonSet", daemonSet)
+func updatePodSpec(imageTransformer ImageTransformer, u *unstructured.Unstructured, path []string, log logr.Logger) error {
+	obj, found, err := unstructured.NestedMap(u.Object, path...)
+	if err != nil || !found {
 		return err
 	}
-	updateRegistry(&daemonSet.Spec.Template.Spec, imageTransformer, log, daemonSet.GetName())
-	if err := scheme.Scheme.Convert(daemonSet, u, nil); err != nil {
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, spec); err != nil {
+		log.Error(err, "Error converting Unstructured to PodSpec", "kind", u.GetKind(), "name", u.GetName())
 		return err
 	}
-	// The zero-value timestamp defaulted by the conversion causes
-	// superfluous updates
-	u.SetCreationTimestamp(metav1.Time{})
 
-	log.Info("Finished conversion", "name", u.GetName(), "unstructured", u.Object)
-	return nil
-}
+	before := spec.DeepCopy()
+	updateRegistry(spec, imageTransformer, log, u.GetName())
+	if equality.Semantic.DeepEqual(before, spec) {
+		return nil
+	}
 
-func updateJob(imageTransformer ImageTransformer, u *unstructured.Unstructured, log logr.Logger) error {
-	var job = &batchv1.Job{}
-	if err := scheme.Scheme.Convert(u, job, nil); err != nil {
-		log.Error(err, "Error converting Unstructured to job", "unstructured", u, "job", job)
+	obj, err = runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+	if err != nil {
 		return err
 	}
-	updateRegistry(&job.Spec.Template.Spec, imageTransformer, log, job.GetName())
-	if err := scheme.Scheme.Convert(job, u, nil); err != nil {
+	if err := unstructured.SetNestedMap(u.Object, obj, path...); err != nil {
 		return err
 	}
-	// The zero-value timestamp defaulted by the conversion causes
-	// superfluous updates
-	u.SetCreationTimestamp(metav1.Time{})
 
-	log.Info("Finished conversion", "name", u.GetName(), "unstructured", u.Object)
+	log.Info("Finished conversion", "kind", u.GetKind(), "name", u.GetName(), "unstructured", u.Object)
 	return nil
 }
 
@@ -132,19 +122,25 @@ func updateRegistry(spec *corev1.PodSpec, imageTransformer ImageTransformer, log
 
 // updateImage updates the image with a new registry and tag
 func updateImage(spec *corev1.PodSpec, imageTransformer ImageTransformer, log logr.Logger, name string) {
-	containers := spec.Containers
-	for index := range containers {
-		container := &containers[index]
-		newImage, _ := imageTransformer.ImageForContainer(container, name)
-		if newImage != "" {
-			updateContainer(container, newImage, log)
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for index := range containers {
+			container := &containers[index]
+			newImage, _ := imageTransformer.ImageForContainer(container, name)
+			if newImage != "" {
+				updateContainer(container, newImage, log)
+			}
 		}
 	}
-	log.Info("Finished updating images", "name", name, "containers", spec.Containers)
+	log.Info("Finished updating images", "name", name, "initContainers", spec.InitContainers, "containers", spec.Containers)
 }
 
 func updateEnvVarImages(spec *corev1.PodSpec, imageTransformer ImageTransformer) {
-	containers := spec.Containers
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		updateContainerEnvVarImages(containers, imageTransformer)
+	}
+}
+
+func updateContainerEnvVarImages(containers []corev1.Container, imageTransformer ImageTransformer) {
 	for index := range containers {
 		container := &containers[index]
 		for envIndex := range container.Env {
@@ -7,6 +7,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	util "knative.dev/operator/pkg/reconciler/common/testing"
 
@@ -242,6 +243,84 @@ func runResourceTransformTest(t *testing.T, tt *updateImageTest) {
 	jobTransform := ImageTransform(tt.overrideMap, log)
 	jobTransform(&unstructuredJob)
 	validateUnstructuredJobChanged(t, tt, &unstructuredJob)
+
+	// test for all other kinds carrying a PodSpec
+	for _, kind := range podSpecKinds {
+		u := makePodSpecResource(t, kind.apiVersion, kind.kind, tt.name, corev1.PodSpec{Containers: tt.containers})
+		ImageTransform(tt.overrideMap, log)(u)
+		util.AssertDeepEqual(t, podSpecOf(t, u).Containers, tt.expected)
+	}
+}
+
+var podSpecKinds = []struct {
+	apiVersion string
+	kind       string
+}{
+	{apiVersion: "apps/v1", kind: "StatefulSet"},
+	{apiVersion: "apps/v1", kind: "ReplicaSet"},
+	{apiVersion: "batch/v1beta1", kind: "CronJob"},
+	{apiVersion: "serving.knative.dev/v1", kind: "Service"},
+}
+
+func TestResourceTransformInitContainers(t *testing.T) {
+	overrideMap := map[string]string{
+		"init":       "new-registry.io/init:new-tag",
+		"SOME_IMAGE": "new-registry.io/env:new-tag",
+	}
+	podSpec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{
+			Name:  "init",
+			Image: "gcr.io/init:test",
+			Env:   []corev1.EnvVar{{Name: "SOME_IMAGE", Value: "gcr.io/env:test"}},
+		}},
+	}
+
+	u := makePodSpecResource(t, "batch/v1", "Job", "migrate", podSpec)
+	ImageTransform(overrideMap, log)(u)
+
+	util.AssertDeepEqual(t, podSpecOf(t, u).InitContainers, []corev1.Container{{
+		Name:  "init",
+		Image: "new-registry.io/init:new-tag",
+		Env:   []corev1.EnvVar{{Name: "SOME_IMAGE", Value: "new-registry.io/env:new-tag"}},
+	}})
+}
+
+func TestResourceTransformIgnoresKubernetesServices(t *testing.T) {
+	u := makePodSpecResource(t, "v1", "Service", "foo", corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "foo", Image: "gcr.io/foo:test"}},
+	})
+	ImageTransform(map[string]string{"foo": "new-registry.io/foo:new-tag"}, log)(u)
+
+	util.AssertEqual(t, podSpecOf(t, u).Containers[0].Image, "gcr.io/foo:test")
+}
+
+func makePodSpecResource(t *testing.T, apiVersion, kind, name string, podSpec corev1.PodSpec) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetName(name)
+
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podSpec)
+	util.AssertEqual(t, err, nil)
+	path := podSpecablePath
+	if kind == "CronJob" {
+		path = podSpecPaths[kind]
+	}
+	util.AssertEqual(t, unstructured.SetNestedMap(u.Object, spec, path...), nil)
+	return u
+}
+
+func podSpecOf(t *testing.T, u *unstructured.Unstructured) *corev1.PodSpec {
+	path := podSpecablePath
+	if u.GetKind() == "CronJob" {
+		path = podSpecPaths[u.GetKind()]
+	}
+	obj, _, err := unstructured.NestedMap(u.Object, path...)
+	util.AssertEqual(t, err, nil)
+
+	spec := &corev1.PodSpec{}
+	util.AssertEqual(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj, spec), nil)
+	return spec
 }
 
 func validateUnstructuredDeploymentChanged(t *testing.T, tt *updateImageTest, u *unstructured.Unstructured) {
@@ -9,6 +9,9 @@ import (
 	mf "github.com/manifestival/manifestival"
 	operatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
+	"github.com/openshift-knative/serverless-operator/pkg/images"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -18,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -31,6 +35,11 @@ const (
 	// DO NOT change to something else in the future!
 	// This needs to remain "knative-kafka-openshift" to be compatible with earlier versions in the future versions.
 	finalizerName = "knative-kafka-openshift"
+
+	// ChannelManifestPathEnvKey and SourceManifestPathEnvKey are the environment variables
+	// holding the paths of the KafkaChannel and KafkaSource manifests.
+	ChannelManifestPathEnvKey = "KAFKACHANNEL_MANIFEST_PATH"
+	SourceManifestPathEnvKey  = "KAFKASOURCE_MANIFEST_PATH"
 )
 
 var (
@@ -46,21 +55,25 @@ type stage func(*mf.Manifest, *operatorv1alpha1.KnativeKafka) error
 // Add creates a new KnativeKafka Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	reconciler, err := newReconciler(mgr)
+	catalog, err := common.ImageCatalogCache(mgr)
+	if err != nil {
+		return err
+	}
+	reconciler, err := newReconciler(mgr, catalog)
 	if err != nil {
 		return err
 	}
-	return add(mgr, reconciler)
+	return add(mgr, reconciler, catalog)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) (*ReconcileKnativeKafka, error) {
-	kafkaChannelManifest, err := mf.ManifestFrom(mf.Path(os.Getenv("KAFKACHANNEL_MANIFEST_PATH")))
+func newReconciler(mgr manager.Manager, catalog cache.Cache) (*ReconcileKnativeKafka, error) {
+	kafkaChannelManifest, err := mf.ManifestFrom(mf.Path(os.Getenv(ChannelManifestPathEnvKey)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load KafkaChannel manifest: %w", err)
 	}
 
-	kafkaSourceManifest, err := mf.ManifestFrom(mf.Path(os.Getenv("KAFKASOURCE_MANIFEST_PATH")))
+	kafkaSourceManifest, err := mf.ManifestFrom(mf.Path(os.Getenv(SourceManifestPathEnvKey)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load KafkaSource manifest: %w", err)
 	}
@@ -70,14 +83,16 @@ func newReconciler(mgr manager.Manager) (*ReconcileKnativeKafka, error) {
 		scheme:                  mgr.GetScheme(),
 		rawKafkaChannelManifest: kafkaChannelManifest,
 		rawKafkaSourceManifest:  kafkaSourceManifest,
+		catalog:                 catalog,
+		cached:                  mgr.GetCache(),
 	}
 	return &reconcileKnativeKafka, nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r *ReconcileKnativeKafka) error {
+func add(mgr manager.Manager, r *ReconcileKnativeKafka, catalog cache.Cache) error {
 	// Create a new controller
-	c, err := controller.New("knativekafka-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("knativekafka-controller", mgr, controller.Options{Reconciler: common.InstrumentReconciler("kafka_status", r)})
 	if err != nil {
 		return err
 	}
@@ -88,6 +103,18 @@ func add(mgr manager.Manager, r *ReconcileKnativeKafka) error {
 		return err
 	}
 
+	// Watch for changes to the image catalog, which affects all KnativeKafkas.
+	err = c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, catalog), common.EnqueueAllOnImageCatalogChange(mgr.GetClient(), &operatorv1alpha1.KnativeKafkaList{}))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the dashboards, which are restored if they're edited or deleted.
+	err = dashboard.Watch(c, &operatorv1alpha1.KnativeKafka{})
+	if err != nil {
+		return err
+	}
+
 	gvkToResource := common.BuildGVKToResourceMap(r.rawKafkaChannelManifest, r.rawKafkaSourceManifest)
 
 	for _, t := range gvkToResource {
@@ -111,6 +138,11 @@ type ReconcileKnativeKafka struct {
 	scheme                  *runtime.Scheme
 	rawKafkaChannelManifest mf.Manifest
 	rawKafkaSourceManifest  mf.Manifest
+	// catalog reads the image catalog from the operator's namespace.
+	catalog client.Reader
+	// cached reads unstructured resources from the manager's cache, i.e. the KnativeEventings
+	// keeping their spec.openshift and the cluster's APIServer config.
+	cached client.Reader
 }
 
 // Reconcile reads that state of the cluster for a KnativeKafka object and makes changes based on the state read
@@ -153,6 +185,7 @@ func (r *ReconcileKnativeKafka) Reconcile(ctx context.Context, request reconcile
 	} else {
 		common.KnativeKafkaUpG.Set(0)
 	}
+	common.SetConditionMetrics("kafka_status", instance.Status.Status)
 	return reconcile.Result{}, reconcileErr
 }
 
@@ -177,7 +210,9 @@ func (r *ReconcileKnativeKafka) executeInstallStages(instance *operatorv1alpha1.
 		r.configure,
 		r.ensureFinalizers,
 		r.transform,
+		r.mirrorImages,
 		r.apply,
+		r.installDashboards,
 		r.checkDeployments,
 	}
 
@@ -198,19 +233,10 @@ func (r *ReconcileKnativeKafka) executeDeleteStages(instance *operatorv1alpha1.K
 	return executeStages(instance, manifest, stages)
 }
 
-// set defaults for Openshift
-func (r *ReconcileKnativeKafka) configure(manifest *mf.Manifest, instance *operatorv1alpha1.KnativeKafka) error {
-	before := instance.DeepCopy()
+// set defaults for Openshift. They're shared with the mutating webhook, which persists them,
+// so they're only applied in memory here.
+func (r *ReconcileKnativeKafka) configure(_ *mf.Manifest, instance *operatorv1alpha1.KnativeKafka) error {
 	common.MutateKafka(instance)
-	if equality.Semantic.DeepEqual(before.Spec, instance.Spec) {
-		return nil
-	}
-
-	// Only apply the update if something changed.
-	log.Info("Updating KnativeKafka with mutated state for Openshift")
-	if err := r.client.Update(context.TODO(), instance); err != nil {
-		return fmt.Errorf("failed to update KnativeKafka with mutated state: %w", err)
-	}
 	return nil
 }
 
@@ -228,7 +254,15 @@ func (r *ReconcileKnativeKafka) ensureFinalizers(_ *mf.Manifest, instance *opera
 
 func (r *ReconcileKnativeKafka) transform(manifest *mf.Manifest, instance *operatorv1alpha1.KnativeKafka) error {
 	log.Info("Transforming manifest")
-	rbacProxyTranform, err := getRBACProxyInjectTransformer(r.client)
+	components, err := r.monitoringComponents(instance)
+	if err != nil {
+		return err
+	}
+	rbacProxyTranform, err := getRBACProxyInjectTransformer(r.cached, components)
+	if err != nil {
+		return err
+	}
+	catalog, err := r.imageCatalog(instance)
 	if err != nil {
 		return err
 	}
@@ -241,7 +275,7 @@ func (r *ReconcileKnativeKafka) transform(manifest *mf.Manifest, instance *opera
 		setKafkaDeployments(instance.Spec.HighAvailability.Replicas),
 		setBootstrapServers(instance.Spec.Channel.BootstrapServers),
 		setAuthSecret(instance.Spec.Channel.AuthSecretNamespace, instance.Spec.Channel.AuthSecretName),
-		ImageTransform(common.BuildImageOverrideMapFromEnviron(os.Environ(), "KAFKA_IMAGE_"), log),
+		ImageTransform(catalog, log),
 		replicasTransform(manifest.Client),
 		rbacProxyTranform,
 	)
@@ -252,6 +286,51 @@ func (r *ReconcileKnativeKafka) transform(manifest *mf.Manifest, instance *opera
 	return nil
 }
 
+// imageCatalog resolves the Kafka image catalog and reports invalid overrides and overrides
+// matching none of the Kafka components on the instance's status. Invalid overrides fail
+// the installation.
+func (r *ReconcileKnativeKafka) imageCatalog(instance *operatorv1alpha1.KnativeKafka) (images.Catalog, error) {
+	catalog, problems, err := common.ImageCatalog(r.catalog, images.KafkaPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if err := images.PolicyError(problems); err != nil {
+		images.MarkCondition(&instance.Status, problems, nil)
+		instance.Status.MarkInstallFailed(err.Error())
+		return nil, err
+	}
+	resources := append(r.rawKafkaChannelManifest.Resources(), r.rawKafkaSourceManifest.Resources()...)
+	images.MarkCondition(&instance.Status, problems, catalog.Unused(resources))
+	return catalog, nil
+}
+
+// monitoringComponents discovers the monitored components of all Kafka components and
+// reports the deployments that can't be monitored on the instance's status.
+func (r *ReconcileKnativeKafka) monitoringComponents(instance *operatorv1alpha1.KnativeKafka) (monitoring.Components, error) {
+	resources := append(r.rawKafkaChannelManifest.Resources(), r.rawKafkaSourceManifest.Resources()...)
+	components, unknown, err := monitoring.DiscoverComponents(resources)
+	if err != nil {
+		return nil, err
+	}
+	monitoring.MarkComponentsDiscovered(&instance.Status, unknown)
+	return components, nil
+}
+
+// mirrorImages rewrites the images of the manifest to the configured mirrors. Deleted
+// resources are matched by name, so only installed manifests have to be rewritten.
+func (r *ReconcileKnativeKafka) mirrorImages(manifest *mf.Manifest, instance *operatorv1alpha1.KnativeKafka) error {
+	mirrors, err := common.ImageMirrors(r.catalog, r.client)
+	if err != nil {
+		return err
+	}
+	m, err := manifest.Transform(mirrors.Transform(&instance.Status))
+	if err != nil {
+		return fmt.Errorf("failed to rewrite images to mirrors: %w", err)
+	}
+	*manifest = m
+	return nil
+}
+
 // Install Knative Kafka components
 func (r *ReconcileKnativeKafka) apply(manifest *mf.Manifest, instance *operatorv1alpha1.KnativeKafka) error {
 	log.Info("Installing manifest")
@@ -299,6 +378,35 @@ func (r *ReconcileKnativeKafka) checkDeployments(manifest *mf.Manifest, instance
 	return nil
 }
 
+// installDashboards installs the dashboards of the enabled components and removes those of
+// the disabled ones.
+func (r *ReconcileKnativeKafka) installDashboards(_ *mf.Manifest, instance *operatorv1alpha1.KnativeKafka) error {
+	enabled, disabled := dashboard.KafkaDashboards(kafkaComponents(instance))
+	if err := dashboard.Apply(instance, r.client, enabled...); err != nil {
+		return fmt.Errorf("failed to apply dashboards: %w", err)
+	}
+	if err := dashboard.Delete(instance, r.client, disabled...); err != nil {
+		return fmt.Errorf("failed to delete dashboards: %w", err)
+	}
+	return nil
+}
+
+// deleteDashboards removes the dashboards of all components.
+func (r *ReconcileKnativeKafka) deleteDashboards(_ *mf.Manifest, instance *operatorv1alpha1.KnativeKafka) error {
+	enabled, disabled := dashboard.KafkaDashboards(kafkaComponents(instance))
+	if err := dashboard.Delete(instance, r.client, append(enabled, disabled...)...); err != nil {
+		return fmt.Errorf("failed to delete dashboards: %w", err)
+	}
+	return nil
+}
+
+func kafkaComponents(instance *operatorv1alpha1.KnativeKafka) dashboard.KafkaComponents {
+	return dashboard.KafkaComponents{
+		Channel: instance.Spec.Channel.Enabled,
+		Source:  instance.Spec.Source.Enabled,
+	}
+}
+
 // Delete Knative Kafka resources
 func (r *ReconcileKnativeKafka) deleteResources(manifest *mf.Manifest, instance *operatorv1alpha1.KnativeKafka) error {
 	if len(manifest.Resources()) <= 0 {
@@ -327,6 +435,7 @@ func isDeploymentAvailable(d *appsv1.Deployment) bool {
 // general clean-up. required for the resources that cannot be garbage collected with the owner reference mechanism
 func (r *ReconcileKnativeKafka) delete(instance *operatorv1alpha1.KnativeKafka) error {
 	defer common.KnativeUp.DeleteLabelValues("kafka_status")
+	defer common.DeleteConditionMetrics("kafka_status", instance.Status.Status)
 	finalizers := sets.NewString(instance.GetFinalizers()...)
 
 	if !finalizers.Has(finalizerName) {
@@ -366,6 +475,7 @@ func (r *ReconcileKnativeKafka) deleteKnativeKafka(instance *operatorv1alpha1.Kn
 	stages := []stage{
 		r.transform,
 		r.deleteResources,
+		r.deleteDashboards,
 	}
 
 	return executeStages(instance, manifest, stages)
@@ -383,7 +493,7 @@ func (r *ReconcileKnativeKafka) buildManifest(instance *operatorv1alpha1.Knative
 	var resources []unstructured.Unstructured
 
 	if build == manifestBuildAll || (build == manifestBuildEnabledOnly && instance.Spec.Channel.Enabled) || (build == manifestBuildDisabledOnly && !instance.Spec.Channel.Enabled) {
-		channelRBACProxy, err := addRBACProxySupportToManifest(instance, kafkaChannelComponents)
+		channelRBACProxy, err := addRBACProxySupportToManifest(instance, r.rawKafkaChannelManifest)
 		if err != nil {
 			return nil, err
 		}
@@ -392,7 +502,7 @@ func (r *ReconcileKnativeKafka) buildManifest(instance *operatorv1alpha1.Knative
 	}
 
 	if build == manifestBuildAll || (build == manifestBuildEnabledOnly && instance.Spec.Source.Enabled) || (build == manifestBuildDisabledOnly && !instance.Spec.Source.Enabled) {
-		sourceRBACProxy, err := addRBACProxySupportToManifest(instance, kafkaSourceComponents)
+		sourceRBACProxy, err := addRBACProxySupportToManifest(instance, r.rawKafkaSourceManifest)
 		if err != nil {
 			return nil, err
 		}
@@ -400,6 +510,16 @@ func (r *ReconcileKnativeKafka) buildManifest(instance *operatorv1alpha1.Knative
 		resources = append(resources, r.rawKafkaSourceManifest.Resources()...)
 	}
 
+	// The alerts cover the components of both, so they're only removed along with the last one.
+	enabled := instance.Spec.Channel.Enabled || instance.Spec.Source.Enabled
+	if build == manifestBuildAll || (build == manifestBuildEnabledOnly && enabled) || (build == manifestBuildDisabledOnly && !enabled) {
+		rules, err := getAlertRules(r.cached, instance, resources)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, rules...)
+	}
+
 	manifest, err := mf.ManifestFrom(
 		mf.Slice(resources),
 		mf.UseClient(mfc.NewClient(r.client)),
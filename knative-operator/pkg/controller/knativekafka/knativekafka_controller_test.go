@@ -2,7 +2,9 @@ package knativekafka
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -10,6 +12,9 @@ import (
 	mf "github.com/manifestival/manifestival"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	kafkawebhook "github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/knativekafka"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/testutil"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -22,6 +27,7 @@ import (
 	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 var (
@@ -100,6 +106,8 @@ func TestKnativeKafkaReconcile(t *testing.T) {
 
 			r := &ReconcileKnativeKafka{
 				client:                  cl,
+				catalog:                 cl,
+				cached:                  cl,
 				scheme:                  scheme.Scheme,
 				rawKafkaChannelManifest: kafkaChannelManifest,
 				rawKafkaSourceManifest:  kafkaSourceManifest,
@@ -144,6 +152,15 @@ func TestKnativeKafkaReconcile(t *testing.T) {
 				}
 			}
 
+			// Check if the alerts are created along with any of the components
+			rule := &monitoringv1.PrometheusRule{}
+			err = cl.Get(context.TODO(), types.NamespacedName{Name: "knative-kafka-alerts", Namespace: "knative-eventing"}, rule)
+			if len(test.exists) > 0 && err != nil {
+				t.Fatalf("get: (%v)", err)
+			} else if len(test.exists) == 0 && !errors.IsNotFound(err) {
+				t.Fatalf("exists: (%v)", err)
+			}
+
 			// check if things that shouldnt exist is deleted
 			for _, d := range test.doesNotExist {
 				deployment := &appsv1.Deployment{}
@@ -491,3 +508,140 @@ func TestCheckHAComponent(t *testing.T) {
 		})
 	}
 }
+
+func TestKafkaDashboardsFollowComponents(t *testing.T) {
+	dashboardNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-config-managed"}}
+	cl := fake.NewClientBuilder().WithObjects(makeCr(withChannelEnabled, withSourceEnabled), dashboardNamespace, &operatorv1alpha1.KnativeEventing{}).Build()
+
+	kafkaChannelManifest, err := mf.ManifestFrom(mf.Path("testdata/1-channel-consolidated.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load KafkaChannel manifest: %v", err)
+	}
+	kafkaSourceManifest, err := mf.ManifestFrom(mf.Path("testdata/2-source.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load KafkaSource manifest: %v", err)
+	}
+	r := &ReconcileKnativeKafka{
+		client:                  cl,
+		catalog:                 cl,
+		cached:                  cl,
+		scheme:                  scheme.Scheme,
+		rawKafkaChannelManifest: kafkaChannelManifest,
+		rawKafkaSourceManifest:  kafkaSourceManifest,
+	}
+
+	channelKey := types.NamespacedName{Name: "grafana-dashboard-definition-knative-kafka-channel", Namespace: dashboardNamespace.Name}
+	sourceKey := types.NamespacedName{Name: "grafana-dashboard-definition-knative-kafka-source", Namespace: dashboardNamespace.Name}
+
+	if _, err := r.Reconcile(context.Background(), defaultRequest); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	for _, key := range []types.NamespacedName{channelKey, sourceKey} {
+		cm := &corev1.ConfigMap{}
+		if err := cl.Get(context.TODO(), key, cm); err != nil {
+			t.Fatalf("get: (%v)", err)
+		}
+		if cm.Annotations[common.KafkaOwnerName] != "knative-kafka" || cm.Annotations[common.KafkaOwnerNamespace] != "knative-eventing" {
+			t.Errorf("got annotations %v on %s, want the KnativeKafka's owner annotations", cm.Annotations, key.Name)
+		}
+	}
+
+	// Disable the channel.
+	instance := &v1alpha1.KnativeKafka{}
+	if err := cl.Get(context.TODO(), defaultRequest.NamespacedName, instance); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+	instance.Spec.Channel.Enabled = false
+	if err := cl.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("update: (%v)", err)
+	}
+	if _, err := r.Reconcile(context.Background(), defaultRequest); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err := cl.Get(context.TODO(), channelKey, &corev1.ConfigMap{}); !errors.IsNotFound(err) {
+		t.Fatalf("got %v, want the channel dashboard to be removed", err)
+	}
+	if err := cl.Get(context.TODO(), sourceKey, &corev1.ConfigMap{}); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+
+	// Finalize the KnativeKafka.
+	instance = &v1alpha1.KnativeKafka{}
+	if err := cl.Get(context.TODO(), defaultRequest.NamespacedName, instance); err != nil {
+		t.Fatalf("get: (%v)", err)
+	}
+	withDeleted(instance)
+	if err := cl.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("update: (%v)", err)
+	}
+	if _, err := r.Reconcile(context.Background(), defaultRequest); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err := cl.Get(context.TODO(), sourceKey, &corev1.ConfigMap{}); !errors.IsNotFound(err) {
+		t.Fatalf("got %v, want the source dashboard to be removed", err)
+	}
+}
+
+func TestDefaultsMatchWebhook(t *testing.T) {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatal("Failed to create decoder:", err)
+	}
+	configurator := kafkawebhook.NewConfigurator(decoder)
+	r := &ReconcileKnativeKafka{}
+
+	tests := []struct {
+		name string
+		in   *v1alpha1.KnativeKafka
+	}{{
+		name: "defaulted",
+		in:   makeCr(),
+	}, {
+		name: "nothing set",
+		in: makeCr(func(kk *v1alpha1.KnativeKafka) {
+			kk.Spec = v1alpha1.KnativeKafkaSpec{}
+		}),
+	}, {
+		name: "auth secret without namespace",
+		in: makeCr(withChannelEnabled, func(kk *v1alpha1.KnativeKafka) {
+			kk.Spec.HighAvailability = nil
+			kk.Spec.Channel.AuthSecretName = "my-secret"
+		}),
+	}, {
+		name: "auth secret with namespace",
+		in: makeCr(withChannelEnabled, withSourceEnabled, func(kk *v1alpha1.KnativeKafka) {
+			kk.Spec.HighAvailability = &operatorv1alpha1.HighAvailability{Replicas: 1}
+			kk.Spec.Channel.AuthSecretName = "my-secret"
+			kk.Spec.Channel.AuthSecretNamespace = "my-ns"
+		}),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := testutil.RequestFor(test.in)
+			if err != nil {
+				t.Fatalf("Failed to generate a request for %v: %v", test.in, err)
+			}
+			resp := configurator.Handle(context.Background(), req)
+			if !resp.Allowed {
+				t.Fatalf("The request is not allowed: %v", resp.Result)
+			}
+			fromController := test.in.DeepCopy()
+			if err := r.configure(nil, fromController); err != nil {
+				t.Fatal("configure() =", err)
+			}
+			marshaled, err := json.Marshal(fromController)
+			if err != nil {
+				t.Fatal("Failed to marshal the configured object:", err)
+			}
+			want := admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+
+			// The order of the patches doesn't matter.
+			sort.Slice(resp.Patches, func(i, j int) bool { return resp.Patches[i].Path < resp.Patches[j].Path })
+			sort.Slice(want.Patches, func(i, j int) bool { return want.Patches[i].Path < want.Patches[j].Path })
+			if !cmp.Equal(resp.Patches, want.Patches) {
+				t.Errorf("Webhook and controller defaults differ (-webhook, +controller):\n%s", cmp.Diff(resp.Patches, want.Patches))
+			}
+		})
+	}
+}
@@ -3,30 +3,37 @@ package knativekafka
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	mf "github.com/manifestival/manifestival"
 	operatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
-	"k8s.io/apimachinery/pkg/util/sets"
+	configv1 "github.com/openshift/api/config/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	eventingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-var (
-	kafkaChannelComponents = []string{"kafka-ch-controller", "kafka-ch-dispatcher", "kafka-webhook"}
-	kafkaSourceComponents  = []string{"kafka-controller-manager"}
-)
-
-func addRBACProxySupportToManifest(instance *operatorv1alpha1.KnativeKafka, components []string) (*mf.Manifest, error) {
+func addRBACProxySupportToManifest(instance *operatorv1alpha1.KnativeKafka, manifest mf.Manifest) (*mf.Manifest, error) {
+	components, _, err := monitoring.DiscoverComponents(manifest.Resources())
+	if err != nil {
+		return nil, err
+	}
 	proxyManifest := mf.Manifest{}
 	// Only create the roles needed for the deployment service accounts as Prometheus has already
 	// the rights needed due to eventing that is assumed to be installed.
-	for _, c := range components {
-		crbM, err := monitoring.CreateClusterRoleBindingManifest(c, instance.GetNamespace())
+	for sa := range components.ServiceAccounts() {
+		crbM, err := monitoring.CreateClusterRoleBindingManifest(sa, instance.GetNamespace())
 		if err != nil {
 			return nil, err
 		}
 		proxyManifest = proxyManifest.Append(*crbM)
+	}
+	for _, c := range components {
 		if err = monitoring.AppendManifestsForComponent(c, instance.GetNamespace(), &proxyManifest); err != nil {
 			return nil, err
 		}
@@ -34,17 +41,68 @@ func addRBACProxySupportToManifest(instance *operatorv1alpha1.KnativeKafka, comp
 	return &proxyManifest, nil
 }
 
-func getRBACProxyInjectTransformer(apiClient client.Client) (mf.Transformer, error) {
-	eventingList := &eventingv1alpha1.KnativeEventingList{}
-	err := apiClient.List(context.Background(), eventingList)
+// getAlertRules returns the PrometheusRule with the alerts of the Kafka components in the
+// given resources.
+func getAlertRules(cached client.Reader, instance *operatorv1alpha1.KnativeKafka, resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	config, spec, err := getEventingConfig(cached)
 	if err != nil {
 		return nil, err
 	}
-	if len(eventingList.Items) == 0 {
-		return nil, errors.New("eventing instance not found")
+	components, _, err := monitoring.DiscoverComponents(resources)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := monitoring.KafkaAlertRulesManifest(instance.GetNamespace(), config, spec, components)
+	if err != nil {
+		return nil, err
 	}
-	if monitoring.ShouldEnableMonitoring(eventingList.Items[0].GetSpec().GetConfig()) {
-		return monitoring.InjectRbacProxyContainerToDeployments(sets.NewString(append(kafkaChannelComponents, kafkaSourceComponents...)...)), nil
+	return rules.Resources(), nil
+}
+
+func getRBACProxyInjectTransformer(cached client.Reader, components monitoring.Components) (mf.Transformer, error) {
+	config, spec, err := getEventingConfig(cached)
+	if err != nil {
+		return nil, err
+	}
+	if scraped := monitoring.ScrapedDeployments(config, spec, components.Names()); scraped.Len() > 0 {
+		// The rbac-proxy authorization config and its RBAC are installed by Eventing.
+		proxy, err := monitoring.RbacProxyConfigFrom(spec)
+		if err != nil {
+			return nil, err
+		}
+		profile, err := clusterTLSProfile(cached)
+		if err != nil {
+			return nil, err
+		}
+		proxy.DefaultTLS(profile)
+		return monitoring.InjectRbacProxyContainerToDeployments(components.Only(scraped), proxy), nil
 	}
 	return nil, nil
 }
+
+// clusterTLSProfile returns the TLS profile of the cluster's APIServer, which is nil if it's
+// not set or the cluster doesn't have one. It's read from the given cache, which watches it.
+func clusterTLSProfile(cached client.Reader) (*configv1.TLSSecurityProfile, error) {
+	apiServer := &unstructured.Unstructured{}
+	apiServer.SetGroupVersionKind(configv1.GroupVersion.WithKind("APIServer"))
+	if err := cached.Get(context.TODO(), client.ObjectKey{Name: "cluster"}, apiServer); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get the APIServer config: %w", err)
+	}
+	return monitoring.TLSProfileFrom(apiServer)
+}
+
+// getEventingConfig returns the config and the monitoring settings of the Knative Eventing
+// instance. The monitoring of the Kafka components follows the one of Eventing.
+func getEventingConfig(cached client.Reader) (eventingv1alpha1.ConfigMapData, monitoring.Spec, error) {
+	ke, spec, err := common.KnativeEventingMonitoring(context.Background(), cached)
+	if err != nil {
+		return nil, monitoring.Spec{}, err
+	}
+	if ke == nil {
+		return nil, monitoring.Spec{}, errors.New("eventing instance not found")
+	}
+	return ke.GetSpec().GetConfig(), spec, nil
+}
@@ -20,6 +20,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	servingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -55,11 +56,15 @@ var log = common.Log.WithName("controller")
 // Add creates a new KnativeServing Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	catalog, err := common.ImageCatalogCache(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, newReconciler(mgr, catalog), catalog)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, catalog cache.Cache) reconcile.Reconciler {
 	client := mgr.GetClient()
 
 	// Create required namespace first.
@@ -70,15 +75,16 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 	}
 
 	return &ReconcileKnativeServing{
-		client: client,
-		scheme: mgr.GetScheme(),
+		client:  client,
+		catalog: catalog,
+		scheme:  mgr.GetScheme(),
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, catalog cache.Cache) error {
 	// Create a new controller
-	c, err := controller.New("knativeserving-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("knativeserving-controller", mgr, controller.Options{Reconciler: common.InstrumentReconciler("serving_status", r)})
 	if err != nil {
 		return err
 	}
@@ -104,6 +110,18 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to the dashboards, which are restored if they're edited or deleted.
+	err = dashboard.Watch(c, dashboard.ServingOwner(&servingv1alpha1.KnativeServing{}))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the image catalog, which affects all KnativeServings.
+	err = c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, catalog), common.EnqueueAllOnImageCatalogChange(mgr.GetClient(), &servingv1alpha1.KnativeServingList{}))
+	if err != nil {
+		return err
+	}
+
 	gvkToResource := map[schema.GroupVersionKind]client.Object{
 		consolev1.GroupVersion.WithKind("ConsoleCLIDownload"): &consolev1.ConsoleCLIDownload{},
 	}
@@ -125,7 +143,9 @@ type ReconcileKnativeServing struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
-	scheme *runtime.Scheme
+	// catalog reads the image catalog from the operator's namespace.
+	catalog client.Reader
+	scheme  *runtime.Scheme
 }
 
 // Reconcile reads that state of the cluster for a KnativeServing
@@ -162,6 +182,7 @@ func (r *ReconcileKnativeServing) Reconcile(ctx context.Context, request reconci
 	} else {
 		common.KnativeServingUpG.Set(0)
 	}
+	common.SetConditionMetrics("serving_status", instance.Status.Status)
 	return reconcile.Result{}, reconcileErr
 }
 
@@ -185,7 +206,7 @@ func (r *ReconcileKnativeServing) reconcileKnativeServing(instance *servingv1alp
 // configure default settings for OpenShift
 func (r *ReconcileKnativeServing) configure(instance *servingv1alpha1.KnativeServing) error {
 	before := instance.DeepCopy()
-	if err := common.Mutate(instance, r.client); err != nil {
+	if err := common.Mutate(instance, r.client, r.catalog); err != nil {
 		return err
 	}
 	if equality.Semantic.DeepEqual(before.Spec, instance.Spec) {
@@ -337,12 +358,13 @@ func (r *ReconcileKnativeServing) installKnConsoleCLIDownload(instance *servingv
 
 // installDashboard installs dashboard for OpenShift webconsole
 func (r *ReconcileKnativeServing) installDashboard(instance *servingv1alpha1.KnativeServing) error {
-	return dashboard.Apply(os.Getenv(dashboard.ServingResourceDashboardPathEnvVar), instance, r.client)
+	return dashboard.Apply(dashboard.ServingOwner(instance), r.client, dashboard.ServingDashboards()...)
 }
 
 // general clean-up, mostly resources in different namespaces from servingv1alpha1.KnativeServing.
 func (r *ReconcileKnativeServing) delete(instance *servingv1alpha1.KnativeServing) error {
 	defer common.KnativeUp.DeleteLabelValues("serving_status")
+	defer common.DeleteConditionMetrics("serving_status", instance.Status.Status)
 	finalizers := sets.NewString(instance.GetFinalizers()...)
 
 	if !finalizers.Has(finalizerName) {
@@ -357,7 +379,7 @@ func (r *ReconcileKnativeServing) delete(instance *servingv1alpha1.KnativeServin
 	}
 
 	log.Info("Deleting dashboard")
-	if err := dashboard.Delete(os.Getenv(dashboard.ServingResourceDashboardPathEnvVar), instance, r.client); err != nil {
+	if err := dashboard.Delete(dashboard.ServingOwner(instance), r.client, dashboard.ServingDashboards()...); err != nil {
 		return fmt.Errorf("failed to delete dashboard configmap: %w", err)
 	}
 
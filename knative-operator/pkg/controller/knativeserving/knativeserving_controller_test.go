@@ -124,7 +124,6 @@ var (
 func init() {
 	os.Setenv("OPERATOR_NAME", "TEST_OPERATOR")
 	os.Setenv(quickstart.EnvKey, "../../../deploy/resources/quickstart/serverless-application-quickstart.yaml")
-	os.Setenv(dashboard.ServingResourceDashboardPathEnvVar, "../dashboard/testdata/grafana-dash-knative-serving-resources.yaml")
 	apis.AddToScheme(scheme.Scheme)
 }
 
@@ -165,7 +164,7 @@ func TestExtraResourcesReconcile(t *testing.T) {
 			knService := &defaultKnService
 
 			cl := fake.NewClientBuilder().WithObjects(ks, ingress, ns, &servingNamespace, knService).Build()
-			r := &ReconcileKnativeServing{client: cl, scheme: scheme.Scheme}
+			r := &ReconcileKnativeServing{client: cl, catalog: cl, scheme: scheme.Scheme}
 
 			// Reconcile to initialize
 			if _, err := r.Reconcile(context.Background(), defaultRequest); err != nil {
@@ -317,7 +316,7 @@ func TestCustomCertsConfigMap(t *testing.T) {
 				WithRuntimeObjects(test.in...).
 				Build()
 
-			r := &ReconcileKnativeServing{client: cl, scheme: scheme.Scheme}
+			r := &ReconcileKnativeServing{client: cl, catalog: cl, scheme: scheme.Scheme}
 
 			if err := r.ensureCustomCertsConfigMap(ks); err != nil {
 				t.Fatal(err)
@@ -0,0 +1,154 @@
+package usermonitoring
+
+import (
+	"context"
+	"fmt"
+
+	mfclient "github.com/manifestival/controller-runtime-client"
+	mf "github.com/manifestival/manifestival"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = common.Log.WithName("user-workload-monitoring-controller")
+
+// Add creates a new Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileUserWorkloadMonitoring{client: mgr.GetClient()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("user-workload-monitoring-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for namespaces, which are reconciled by name.
+	err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to KnativeServing, whose config affects all namespaces.
+	return c.Watch(&source.Kind{Type: &servingv1alpha1.KnativeServing{}}, handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		namespaces := &corev1.NamespaceList{}
+		if err := mgr.GetClient().List(context.TODO(), namespaces); err != nil {
+			log.Error(err, "Failed to list namespaces for the KnativeServing change")
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(namespaces.Items))
+		for _, ns := range namespaces.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ns.Name}})
+		}
+		return requests
+	}))
+}
+
+// blank assignment to verify that ReconcileUserWorkloadMonitoring implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileUserWorkloadMonitoring{}
+
+// ReconcileUserWorkloadMonitoring sets up the user-workload monitoring of the Knative Services
+// in a namespace.
+type ReconcileUserWorkloadMonitoring struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+}
+
+// Reconcile creates the PodMonitors of the namespace if it's monitored and removes them otherwise.
+func (r *ReconcileUserWorkloadMonitoring) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Name", request.Name)
+
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: request.Name}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	uwm, err := r.userWorkloadMonitoring(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	manifest, err := monitoring.UserWorkloadMonitorsManifest(ns.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !uwm.Monitors(ns) {
+		return reconcile.Result{}, r.deleteMonitors(ctx, manifest)
+	}
+
+	reqLogger.Info("Setting up user-workload monitoring of Knative Services")
+	manifest.Client = mfclient.NewClient(r.client)
+	if err := manifest.Apply(); err != nil {
+		if meta.IsNoMatchError(err) {
+			// PodMonitors are only available with the cluster's monitoring stack.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to apply user-workload monitors: %w", err)
+	}
+	return reconcile.Result{}, nil
+}
+
+// userWorkloadMonitoring returns the user-workload monitoring settings of the KnativeServing.
+// It's disabled if there is none or it's being deleted. Invalid settings disable it too, they
+// are reported on the KnativeServing's status.
+func (r *ReconcileUserWorkloadMonitoring) userWorkloadMonitoring(ctx context.Context) (monitoring.UserWorkloadMonitoring, error) {
+	list := &servingv1alpha1.KnativeServingList{}
+	if err := r.client.List(ctx, list); err != nil {
+		return monitoring.UserWorkloadMonitoring{}, fmt.Errorf("failed to list KnativeServings: %w", err)
+	}
+	if len(list.Items) == 0 || list.Items[0].GetDeletionTimestamp() != nil {
+		return monitoring.UserWorkloadMonitoring{}, nil
+	}
+	uwm, err := monitoring.UserWorkloadMonitoringFrom(list.Items[0].Spec.GetConfig())
+	if err != nil {
+		log.Error(err, "Invalid user-workload monitoring settings, disabling it")
+		return monitoring.UserWorkloadMonitoring{}, nil
+	}
+	return uwm, nil
+}
+
+// deleteMonitors deletes the PodMonitors of the given manifest that have been created by the
+// operator.
+func (r *ReconcileUserWorkloadMonitoring) deleteMonitors(ctx context.Context, manifest mf.Manifest) error {
+	for _, u := range manifest.Resources() {
+		monitor := &monitoringv1.PodMonitor{}
+		err := r.client.Get(ctx, client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()}, monitor)
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if monitor.Labels[monitoring.UserWorkloadMonitorLabel] != "true" {
+			continue
+		}
+		log.Info("Deleting user-workload monitor", "namespace", monitor.Namespace, "name", monitor.Name)
+		if err := r.client.Delete(ctx, monitor); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PodMonitor %s/%s: %w", monitor.Namespace, monitor.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package usermonitoring
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func init() {
+	apis.AddToScheme(scheme.Scheme)
+}
+
+func TestReconcile(t *testing.T) {
+	ks := &v1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Name: "knative-serving", Namespace: "knative-serving"},
+		Spec: v1alpha1.KnativeServingSpec{CommonSpec: v1alpha1.CommonSpec{
+			Config: v1alpha1.ConfigMapData{common.ExtensionConfigName: {
+				monitoring.UserWorkloadMonitoringKey:                  "true",
+				monitoring.UserWorkloadMonitoringNamespaceSelectorKey: "team",
+			}},
+		}},
+	}
+	tenant := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Labels: map[string]string{"team": "a"}}}
+	other := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	// A monitor of the same name the operator didn't create.
+	foreign := &monitoringv1.PodMonitor{ObjectMeta: metav1.ObjectMeta{Name: monitoring.QueueProxyMonitorName, Namespace: "other"}}
+
+	cl := fake.NewClientBuilder().WithObjects(ks, tenant, other, foreign).Build()
+	r := &ReconcileUserWorkloadMonitoring{client: cl}
+
+	for _, ns := range []string{"tenant", "other", "deleted"} {
+		if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: ns}}); err != nil {
+			t.Fatalf("Reconcile(%s) = %v", ns, err)
+		}
+	}
+	for _, name := range []string{monitoring.QueueProxyMonitorName, monitoring.UserContainerMonitorName} {
+		if err := cl.Get(context.Background(), client.ObjectKey{Namespace: "tenant", Name: name}, &monitoringv1.PodMonitor{}); err != nil {
+			t.Errorf("Failed to get PodMonitor tenant/%s: %v", name, err)
+		}
+	}
+	err := cl.Get(context.Background(), client.ObjectKey{Namespace: "other", Name: monitoring.UserContainerMonitorName}, &monitoringv1.PodMonitor{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Got %v, want no PodMonitor in a namespace not matching the selector", err)
+	}
+
+	// Disabling removes the monitors created by the operator only.
+	ks.Spec.Config[common.ExtensionConfigName][monitoring.UserWorkloadMonitoringKey] = "false"
+	if err := cl.Update(context.Background(), ks); err != nil {
+		t.Fatal("Failed to update KnativeServing", err)
+	}
+	for _, ns := range []string{"tenant", "other"} {
+		if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: ns}}); err != nil {
+			t.Fatalf("Reconcile(%s) = %v", ns, err)
+		}
+	}
+	for _, name := range []string{monitoring.QueueProxyMonitorName, monitoring.UserContainerMonitorName} {
+		err := cl.Get(context.Background(), client.ObjectKey{Namespace: "tenant", Name: name}, &monitoringv1.PodMonitor{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("Got %v, want PodMonitor tenant/%s to be deleted", err, name)
+		}
+	}
+	if err := cl.Get(context.Background(), client.ObjectKey{Namespace: "other", Name: monitoring.QueueProxyMonitorName}, &monitoringv1.PodMonitor{}); err != nil {
+		t.Errorf("Failed to get the foreign PodMonitor: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Package certs provides the webhook server with the certificate of a TLS Secret, like the
+// ones the service CA operator or cert-manager maintain. The certificate is written into the
+// server's certificate directory, where the server picks up rotations without a restart.
+package certs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("certs")
+
+// SecretWriter writes the certificate and key of a TLS Secret into a directory.
+type SecretWriter struct {
+	// Kube reads the Secret.
+	Kube kubernetes.Interface
+	// Secret is the TLS Secret to read the certificate and key from.
+	Secret types.NamespacedName
+	// Dir is the certificate directory of the webhook server.
+	Dir string
+	// CertName and KeyName are the file names of the certificate and key in Dir.
+	CertName string
+	KeyName  string
+}
+
+// Write writes the current certificate and key. The webhook server needs them when it
+// starts, so this has to be called before.
+func (w *SecretWriter) Write(ctx context.Context) error {
+	secret, err := w.Kube.CoreV1().Secrets(w.Secret.Namespace).Get(ctx, w.Secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get certificate secret %s: %w", w.Secret, err)
+	}
+	return w.write(secret)
+}
+
+// Start writes the certificate and key whenever the Secret changes, until the context is done.
+func (w *SecretWriter) Start(ctx context.Context) error {
+	selector := fields.OneTermEqualSelector("metadata.name", w.Secret.Name).String()
+	secrets := w.Kube.CoreV1().Secrets(w.Secret.Namespace)
+	informer := cache.NewSharedInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = selector
+			return secrets.List(ctx, opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = selector
+			return secrets.Watch(ctx, opts)
+		},
+	}, &corev1.Secret{}, 0)
+
+	update := func(obj interface{}) {
+		secret, ok := obj.(*corev1.Secret)
+		if !ok || secret.Name != w.Secret.Name {
+			return
+		}
+		if err := w.write(secret); err != nil {
+			log.Error(err, "Failed to update the webhook certificate")
+		}
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    update,
+		UpdateFunc: func(_, obj interface{}) { update(obj) },
+	})
+	informer.Run(ctx.Done())
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every replica serves the
+// webhooks, so every replica needs the certificate.
+func (w *SecretWriter) NeedLeaderElection() bool {
+	return false
+}
+
+// write writes the certificate and key of the Secret if they changed, so that the webhook
+// server only reloads them on an actual rotation.
+func (w *SecretWriter) write(secret *corev1.Secret) error {
+	cert, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(cert) == 0 || len(key) == 0 {
+		return fmt.Errorf("secret %s/%s lacks %s or %s", secret.Namespace, secret.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+	if err := os.MkdirAll(w.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+
+	// The key goes first, so a reload never pairs a new certificate with the old key.
+	changed := false
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{{w.KeyName, key}, {w.CertName, cert}} {
+		path := filepath.Join(w.Dir, file.name)
+		if current, err := ioutil.ReadFile(path); err == nil && bytes.Equal(current, file.data) {
+			continue
+		}
+		if err := writeFile(path, file.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		changed = true
+	}
+	if changed {
+		log.Info("Wrote webhook certificate", "secret", secret.Namespace+"/"+secret.Name, "dir", w.Dir)
+	}
+	return nil
+}
+
+// writeFile replaces the file at path with the given data atomically, so that the webhook
+// server never reads a partially written file.
+func writeFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
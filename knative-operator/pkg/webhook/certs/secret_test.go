@@ -0,0 +1,101 @@
+package certs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSecretWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal("Failed to create directory:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-tls", Namespace: "openshift-serverless"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("cert-1"),
+			corev1.TLSPrivateKeyKey: []byte("key-1"),
+		},
+	}
+	kube := fake.NewSimpleClientset(secret)
+	w := &SecretWriter{
+		Kube:     kube,
+		Secret:   types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name},
+		Dir:      filepath.Join(dir, "serving-certs"),
+		CertName: "tls.crt",
+		KeyName:  "tls.key",
+	}
+
+	read := func() (string, string) {
+		cert, _ := ioutil.ReadFile(filepath.Join(w.Dir, w.CertName))
+		key, _ := ioutil.ReadFile(filepath.Join(w.Dir, w.KeyName))
+		return string(cert), string(key)
+	}
+
+	if err := w.Write(context.Background()); err != nil {
+		t.Fatal("Write() =", err)
+	}
+	if cert, key := read(); cert != "cert-1" || key != "key-1" {
+		t.Fatalf("Got cert %q and key %q, want cert-1 and key-1", cert, key)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Start(ctx)
+
+	// Rotate the certificate.
+	secret = secret.DeepCopy()
+	secret.Data[corev1.TLSCertKey] = []byte("cert-2")
+	secret.Data[corev1.TLSPrivateKeyKey] = []byte("key-2")
+	if _, err := kube.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Failed to update secret:", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		cert, key := read()
+		return cert == "cert-2" && key == "key-2", nil
+	}); err != nil {
+		cert, key := read()
+		t.Errorf("Got cert %q and key %q after rotation, want cert-2 and key-2", cert, key)
+	}
+
+	// No temporary files are left behind.
+	files, err := ioutil.ReadDir(w.Dir)
+	if err != nil {
+		t.Fatal("Failed to read directory:", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Got %d files in %s, want 2", len(files), w.Dir)
+	}
+}
+
+func TestSecretWriterIncompleteSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-tls", Namespace: "openshift-serverless"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+	w := &SecretWriter{
+		Kube:     fake.NewSimpleClientset(secret),
+		Secret:   types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name},
+		Dir:      os.TempDir(),
+		CertName: "tls.crt",
+		KeyName:  "tls.key",
+	}
+	if err := w.Write(context.Background()); err == nil {
+		t.Error("Write() = nil, want an error for a Secret without key")
+	}
+	if err := (&SecretWriter{Kube: fake.NewSimpleClientset(), Secret: w.Secret}).Write(context.Background()); err == nil {
+		t.Error("Write() = nil, want an error for a missing Secret")
+	}
+}
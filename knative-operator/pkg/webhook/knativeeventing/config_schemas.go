@@ -0,0 +1,23 @@
+package knativeeventing
+
+import (
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	eventingconfig "knative.dev/eventing/pkg/apis/config"
+)
+
+// configSchemas are the schemas of the Eventing ConfigMaps validated in spec.config.
+var configSchemas = map[string]validation.ConfigSchema{
+	"br-defaults": {
+		Keys: sets.NewString(eventingconfig.BrokerDefaultsKey),
+		Parse: func(data map[string]string) error {
+			// The defaults of the shipped ConfigMap remain if the key isn't overridden.
+			if _, ok := data[eventingconfig.BrokerDefaultsKey]; !ok {
+				return nil
+			}
+			_, err := eventingconfig.NewDefaultsConfigFromMap(data)
+			return err
+		},
+	},
+	"observability": validation.ObservabilitySchema,
+}
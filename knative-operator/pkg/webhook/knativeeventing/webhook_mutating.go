@@ -6,18 +6,24 @@ import (
 	"net/http"
 
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/validation"
+	"github.com/openshift-knative/serverless-operator/pkg/managed"
 	eventingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // Configurator annotates KEs
 type Configurator struct {
+	catalog client.Reader
 	decoder *admission.Decoder
 }
 
 // NewConfigurator creates a new Configurator instance to configure KnativeEventing CRs.
-func NewConfigurator(decoder *admission.Decoder) *Configurator {
+// The image catalog is read through the given reader.
+func NewConfigurator(catalog client.Reader, decoder *admission.Decoder) *Configurator {
 	return &Configurator{
+		catalog: catalog,
 		decoder: decoder,
 	}
 }
@@ -34,11 +40,29 @@ func (v *Configurator) Handle(ctx context.Context, req admission.Request) admiss
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	common.MutateEventing(ke)
+	user := ke.Spec.CommonSpec.DeepCopy()
+	err = common.MutateEventing(ke, v.catalog)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	var old map[string]string
+	if len(req.OldObject.Raw) > 0 {
+		oldObj := &eventingv1alpha1.KnativeEventing{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		old = managed.Values(&oldObj.Spec.CommonSpec, managed.EventingFields)
+	}
+	result := validation.ManagedFields(managed.Restore(ke, user, &ke.Spec.CommonSpec, managed.EventingFields), old)
+	if !result.Allowed() {
+		return result.Response()
+	}
 
 	marshaled, err := json.Marshal(ke)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
-	return admission.PatchResponseFromRaw(req.AdmissionRequest.Object.Raw, marshaled)
+	resp := admission.PatchResponseFromRaw(req.AdmissionRequest.Object.Raw, marshaled)
+	return common.KeepSpecExtension(resp).WithWarnings(result.Warnings...)
 }
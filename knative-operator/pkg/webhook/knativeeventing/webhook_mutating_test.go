@@ -0,0 +1,46 @@
+package knativeeventing
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfiguratorKeepsSpecExtension(t *testing.T) {
+	os.Clearenv()
+
+	configurator := NewConfigurator(fake.NewClientBuilder().Build(), decoder)
+
+	req, err := testutil.RequestFor(ke1)
+	if err != nil {
+		t.Fatalf("Failed to generate a request for %v: %v", ke1, err)
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		t.Fatal("Failed to unmarshal request:", err)
+	}
+	obj["spec"] = map[string]interface{}{
+		"openshift": map[string]interface{}{"defaultChannel": "KafkaChannel"},
+	}
+	if req.Object.Raw, err = json.Marshal(obj); err != nil {
+		t.Fatal("Failed to marshal request:", err)
+	}
+
+	result := configurator.Handle(context.Background(), req)
+	if !result.Allowed {
+		t.Fatalf("The request is not allowed: %v", result.AdmissionResponse)
+	}
+	if len(result.Patches) == 0 {
+		t.Fatal("Expected the defaults to be patched in")
+	}
+	for _, patch := range result.Patches {
+		if strings.HasPrefix(patch.Path, "/spec/openshift") {
+			t.Errorf("Patch %v touches /spec/openshift", patch)
+		}
+	}
+}
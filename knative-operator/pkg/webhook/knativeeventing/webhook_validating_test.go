@@ -38,7 +38,7 @@ func TestInvalidNamespace(t *testing.T) {
 	os.Clearenv()
 	os.Setenv("REQUIRED_EVENTING_NAMESPACE", "knative-eventing")
 
-	validator := NewValidator(nil, decoder)
+	validator := NewValidator(fake.NewClientBuilder().Build(), decoder)
 
 	req, err := testutil.RequestFor(ke1)
 	if err != nil {
@@ -34,6 +34,10 @@ func (v *Configurator) Handle(ctx context.Context, req admission.Request) admiss
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	// The namespace of new objects might not be set yet.
+	if kk.Namespace == "" {
+		kk.Namespace = req.Namespace
+	}
 	common.MutateKafka(kk)
 
 	marshaled, err := json.Marshal(kk)
@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
 
 	operatorv1alpha1 "github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis/operator/v1alpha1"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/validation"
 	eventingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -15,18 +15,31 @@ import (
 
 // Validator validates KnativeKafka CR's
 type Validator struct {
-	client  client.Client
+	rules   []validation.Rule
 	decoder *admission.Decoder
 }
 
 // NewValidator creates a new Valicator instance to validate KnativeKafka CRs.
 func NewValidator(client client.Client, decoder *admission.Decoder) *Validator {
 	return &Validator{
-		client:  client,
+		rules:   Rules(client),
 		decoder: decoder,
 	}
 }
 
+// Rules returns the rules KnativeKafka CRs are validated with.
+func Rules(c client.Reader) []validation.Rule {
+	return []validation.Rule{
+		validation.RequiredNamespace("REQUIRED_KAFKA_NAMESPACE"),
+		validation.Singleton(c, func() client.ObjectList { return &operatorv1alpha1.KnativeKafkaList{} }),
+		validation.HighAvailability(func(obj client.Object) *eventingv1alpha1.HighAvailability {
+			return obj.(*operatorv1alpha1.KnativeKafka).Spec.HighAvailability
+		}),
+		validateShape,
+		validateDependencies(c),
+	}
+}
+
 // Implement admission.Handler so the controller can handle admission request.
 var _ admission.Handler = (*Validator)(nil)
 
@@ -39,85 +52,40 @@ func (v *Validator) Handle(ctx context.Context, req admission.Request) admission
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	allowed, reason, err := v.validate(ctx, ke)
+	result, err := validation.Validate(ctx, ke, v.rules...)
 	if err != nil {
+		common.Log.WithName("validate").Error(err, "Failed to validate KnativeKafka")
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
-	return admission.ValidationResponse(allowed, reason)
-}
-
-// Validator checks for a minimum OpenShift version
-func (v *Validator) validate(ctx context.Context, ke *operatorv1alpha1.KnativeKafka) (allowed bool, reason string, err error) {
-	log := common.Log.WithName("validate")
-	stages := []func(context.Context, *operatorv1alpha1.KnativeKafka) (bool, string, error){
-		v.validateNamespace,
-		v.validateLoneliness,
-		v.validateShape,
-		v.validateDependencies,
-	}
-	for _, stage := range stages {
-		allowed, reason, err = stage(ctx, ke)
-		if len(reason) > 0 {
-			if err != nil {
-				log.Error(err, reason)
-			} else {
-				log.Info(reason)
-			}
-		}
-		if !allowed {
-			return
-		}
-	}
-	return
-}
-
-// validate required namespace, if any
-func (v *Validator) validateNamespace(ctx context.Context, ke *operatorv1alpha1.KnativeKafka) (bool, string, error) {
-	ns, required := os.LookupEnv("REQUIRED_KAFKA_NAMESPACE")
-	if required && ns != ke.Namespace {
-		return false, fmt.Sprintf("KnativeKafka may only be created in %s namespace", ns), nil
-	}
-	return true, "", nil
-}
-
-// validate this is the only KE in this namespace
-func (v *Validator) validateLoneliness(ctx context.Context, ke *operatorv1alpha1.KnativeKafka) (bool, string, error) {
-	list := &operatorv1alpha1.KnativeKafkaList{}
-	if err := v.client.List(ctx, list, &client.ListOptions{Namespace: ke.Namespace}); err != nil {
-		return false, "Unable to list KnativeKafkas", err
-	}
-	for _, v := range list.Items {
-		if ke.Name != v.Name {
-			return false, "Only one KnativeKafka allowed per namespace", nil
-		}
-	}
-	return true, "", nil
+	return result.Response()
 }
 
 // validate the shape of the CR
-func (v *Validator) validateShape(_ context.Context, ke *operatorv1alpha1.KnativeKafka) (bool, string, error) {
+func validateShape(_ context.Context, obj client.Object, result *validation.Result) error {
+	ke := obj.(*operatorv1alpha1.KnativeKafka)
 	if ke.Spec.Channel.Enabled && ke.Spec.Channel.BootstrapServers == "" {
-		return false, "spec.channel.bootStrapServers is a required detail when spec.channel.enabled is true", nil
+		result.Deny("spec.channel.bootStrapServers is a required detail when spec.channel.enabled is true")
 	}
 	if ke.Spec.Channel.AuthSecretName != "" && ke.Spec.Channel.AuthSecretNamespace == "" {
-		return false, "spec.channel.authSecretNamespace is required when spec.channel.authSecretName is defined", nil
+		result.Deny("spec.channel.authSecretNamespace is required when spec.channel.authSecretName is defined")
 	}
 	if ke.Spec.Channel.AuthSecretNamespace != "" && ke.Spec.Channel.AuthSecretName == "" {
-		return false, "spec.channel.authSecretName is required when spec.channel.authSecretNamespace is defined", nil
+		result.Deny("spec.channel.authSecretName is required when spec.channel.authSecretNamespace is defined")
 	}
-	return true, "", nil
+	return nil
 }
 
 // validate that KnativeEventing is installed as a hard dep
-func (v *Validator) validateDependencies(ctx context.Context, ke *operatorv1alpha1.KnativeKafka) (bool, string, error) {
-	// check to see if we can find KnativeEventing
-	list := &eventingv1alpha1.KnativeEventingList{}
-	if err := v.client.List(ctx, list, &client.ListOptions{Namespace: ke.Namespace}); err != nil {
-		return false, "Unable to list KnativeEventing instance", err
-	}
-	if len(list.Items) == 0 {
-		return false, "KnativeEventing instance must be installed before KnativeKafka", nil
+func validateDependencies(c client.Reader) validation.Rule {
+	return func(ctx context.Context, obj client.Object, result *validation.Result) error {
+		// check to see if we can find KnativeEventing
+		list := &eventingv1alpha1.KnativeEventingList{}
+		if err := c.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+			return fmt.Errorf("unable to list KnativeEventing instance: %w", err)
+		}
+		if len(list.Items) == 0 {
+			result.Deny("KnativeEventing instance must be installed before KnativeKafka")
+		}
+		return nil
 	}
-	// successful case
-	return true, "", nil
 }
@@ -218,3 +218,47 @@ func TestValidateDeps(t *testing.T) {
 		t.Error("No KnativeEventing instance install, but request allowed")
 	}
 }
+
+func TestAggregatedDenials(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("REQUIRED_KAFKA_NAMESPACE", "knative-eventing")
+
+	validator := NewValidator(fake.NewClientBuilder().Build(), decoder)
+
+	cr := invalidShapeCRs[0]
+	req, err := testutil.RequestFor(&cr)
+	if err != nil {
+		t.Fatalf("Failed to generate a request for %v: %v", cr, err)
+	}
+
+	result := validator.Handle(context.Background(), req)
+	if result.Allowed {
+		t.Fatal("The shape is invalid and KnativeEventing is missing, but the request is allowed")
+	}
+	want := "spec.channel.bootStrapServers is a required detail when spec.channel.enabled is true; " +
+		"KnativeEventing instance must be installed before KnativeKafka"
+	if got := string(result.Result.Reason); got != want {
+		t.Errorf("Got reason %q, want %q", got, want)
+	}
+}
+
+func TestHighAvailabilityWarning(t *testing.T) {
+	os.Clearenv()
+
+	validator := NewValidator(fake.NewClientBuilder().WithObjects(validKnativeEventingCR).Build(), decoder)
+
+	cr := defaultCR.DeepCopy()
+	cr.Spec.HighAvailability = &eventingv1alpha1.HighAvailability{Replicas: 1}
+	req, err := testutil.RequestFor(cr)
+	if err != nil {
+		t.Fatalf("Failed to generate a request for %v: %v", cr, err)
+	}
+
+	result := validator.Handle(context.Background(), req)
+	if !result.Allowed {
+		t.Errorf("The request is not allowed but should be: %v", result.Result)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Got warnings %v, want a warning about the single replica", result.Warnings)
+	}
+}
@@ -0,0 +1,140 @@
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/validation"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	networking "knative.dev/networking/pkg"
+	servingconfig "knative.dev/serving/pkg/apis/config"
+	autoscalerconfig "knative.dev/serving/pkg/autoscaler/config"
+	"knative.dev/serving/pkg/gc"
+)
+
+// configSchemas are the schemas of the Serving ConfigMaps validated in spec.config.
+var configSchemas = map[string]validation.ConfigSchema{
+	"autoscaler": {
+		Keys: sets.NewString(
+			"pod-autoscaler-class",
+			"enable-scale-to-zero",
+			"allow-zero-initial-scale",
+			"max-scale-up-rate",
+			"max-scale-down-rate",
+			"container-concurrency-target-percentage",
+			"container-concurrency-target-default",
+			"requests-per-second-target-default",
+			"target-burst-capacity",
+			"panic-window-percentage",
+			"activator-capacity",
+			"panic-threshold-percentage",
+			"initial-scale",
+			"max-scale",
+			"max-scale-limit",
+			"stable-window",
+			"scale-down-delay",
+			"scale-to-zero-grace-period",
+			"scale-to-zero-pod-retention-period",
+		),
+		Parse: func(data map[string]string) error {
+			_, err := autoscalerconfig.NewConfigFromMap(data)
+			return err
+		},
+	},
+	"network": {
+		Keys: sets.NewString(
+			networking.DeprecatedDefaultIngressClassKey,
+			networking.DefaultIngressClassKey,
+			networking.DefaultCertificateClassKey,
+			networking.DomainTemplateKey,
+			networking.TagTemplateKey,
+			networking.RolloutDurationKey,
+			networking.AutocreateClusterDomainClaimsKey,
+			networking.EnableMeshPodAddressabilityKey,
+			networking.AutoTLSKey,
+			networking.HTTPProtocolKey,
+			networking.TagHeaderBasedRoutingKey,
+		),
+		Parse: func(data map[string]string) error {
+			_, err := networking.NewConfigFromMap(data)
+			return err
+		},
+	},
+	"deployment": {
+		Keys: sets.NewString(
+			"queueSidecarImage",
+			"registriesSkippingTagResolving",
+			"progressDeadline",
+			"digestResolutionTimeout",
+			"queueSidecarCPURequest",
+			"queueSidecarCPULimit",
+			"queueSidecarMemoryRequest",
+			"queueSidecarMemoryLimit",
+			"queueSidecarEphemeralStorageRequest",
+			"queueSidecarEphemeralStorageLimit",
+		),
+		Parse: parseDeployment,
+	},
+	"gc": {
+		Keys: sets.NewString(
+			"retain-since-create-time",
+			"retain-since-last-active-time",
+			"min-non-active-revisions",
+			"max-non-active-revisions",
+		),
+		Parse: func(data map[string]string) error {
+			_, err := gc.NewConfigFromConfigMapFunc(context.Background())(&corev1.ConfigMap{Data: data})
+			return err
+		},
+	},
+	"features": {
+		Keys: sets.NewString(
+			"multi-container",
+			"kubernetes.podspec-affinity",
+			"kubernetes.podspec-dryrun",
+			"kubernetes.podspec-hostaliases",
+			"kubernetes.podspec-fieldref",
+			"kubernetes.podspec-nodeselector",
+			"kubernetes.podspec-runtimeclassname",
+			"kubernetes.podspec-securitycontext",
+			"kubernetes.podspec-tolerations",
+			"tag-header-based-routing",
+			"autodetect-http2",
+		),
+		Parse: func(data map[string]string) error {
+			_, err := servingconfig.NewFeaturesConfigFromMap(data)
+			return err
+		},
+	},
+	"observability": validation.ObservabilitySchema,
+}
+
+// parseDeployment checks the values of config-deployment, whose parser isn't vendored.
+func parseDeployment(data map[string]string) error {
+	for _, key := range []string{"progressDeadline", "digestResolutionTimeout"} {
+		if v, ok := data[key]; ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", key, err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("%s must be positive, was: %v", key, d)
+			}
+		}
+	}
+	for _, key := range []string{
+		"queueSidecarCPURequest", "queueSidecarCPULimit",
+		"queueSidecarMemoryRequest", "queueSidecarMemoryLimit",
+		"queueSidecarEphemeralStorageRequest", "queueSidecarEphemeralStorageLimit",
+	} {
+		if v, ok := data[key]; ok {
+			if _, err := resource.ParseQuantity(v); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/validation"
+	"github.com/openshift-knative/serverless-operator/pkg/managed"
 	servingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -14,13 +16,16 @@ import (
 // Configurator annotates Kss
 type Configurator struct {
 	client  client.Client
+	catalog client.Reader
 	decoder *admission.Decoder
 }
 
 // NewConfigurator creates a new Configurator instance to configure KnativeServing CRs.
-func NewConfigurator(client client.Client, decoder *admission.Decoder) *Configurator {
+// The image catalog is read through the given reader.
+func NewConfigurator(client client.Client, catalog client.Reader, decoder *admission.Decoder) *Configurator {
 	return &Configurator{
 		client:  client,
+		catalog: catalog,
 		decoder: decoder,
 	}
 }
@@ -37,14 +42,29 @@ func (v *Configurator) Handle(ctx context.Context, req admission.Request) admiss
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	err = common.Mutate(ks, v.client)
+	user := ks.Spec.CommonSpec.DeepCopy()
+	err = common.Mutate(ks, v.client, v.catalog)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
+	var old map[string]string
+	if len(req.OldObject.Raw) > 0 {
+		oldObj := &servingv1alpha1.KnativeServing{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		old = managed.Values(&oldObj.Spec.CommonSpec, managed.ServingFields)
+	}
+	result := validation.ManagedFields(managed.Restore(ks, user, &ks.Spec.CommonSpec, managed.ServingFields), old)
+	if !result.Allowed() {
+		return result.Response()
+	}
+
 	marshaled, err := json.Marshal(ks)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
-	return admission.PatchResponseFromRaw(req.AdmissionRequest.Object.Raw, marshaled)
+	resp := admission.PatchResponseFromRaw(req.AdmissionRequest.Object.Raw, marshaled)
+	return common.KeepSpecExtension(resp).WithWarnings(result.Warnings...)
 }
@@ -0,0 +1,62 @@
+package knativeserving
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/testutil"
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfiguratorKeepsSpecExtension(t *testing.T) {
+	os.Clearenv()
+
+	cl := fake.NewClientBuilder().WithObjects(
+		&configv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       configv1.IngressSpec{Domain: "example.com"},
+		},
+		&configv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       configv1.NetworkSpec{ServiceNetwork: []string{"172.30.0.0/16"}},
+		},
+	).Build()
+	configurator := NewConfigurator(cl, cl, decoder)
+
+	req, err := testutil.RequestFor(ks1)
+	if err != nil {
+		t.Fatalf("Failed to generate a request for %v: %v", ks1, err)
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		t.Fatal("Failed to unmarshal request:", err)
+	}
+	obj["spec"] = map[string]interface{}{
+		"openshift": map[string]interface{}{
+			"monitoring": map[string]interface{}{
+				"metrics": map[string]interface{}{"backend": "opencensus"},
+			},
+		},
+	}
+	if req.Object.Raw, err = json.Marshal(obj); err != nil {
+		t.Fatal("Failed to marshal request:", err)
+	}
+
+	result := configurator.Handle(context.Background(), req)
+	if !result.Allowed {
+		t.Fatalf("The request is not allowed: %v", result.AdmissionResponse)
+	}
+	if len(result.Patches) == 0 {
+		t.Fatal("Expected the defaults to be patched in")
+	}
+	for _, patch := range result.Patches {
+		if strings.HasPrefix(patch.Path, "/spec/openshift") {
+			t.Errorf("Patch %v touches /spec/openshift", patch)
+		}
+	}
+}
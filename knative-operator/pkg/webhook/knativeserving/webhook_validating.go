@@ -2,30 +2,48 @@ package knativeserving
 
 import (
 	"context"
-	"fmt"
 	"net/http"
-	"os"
 
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/webhook/validation"
+	networking "knative.dev/networking/pkg"
 	servingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// deprecatedConfig are the deprecated keys of spec.config and their replacements.
+var deprecatedConfig = map[string]map[string]string{
+	"network": {
+		networking.DeprecatedDefaultIngressClassKey: networking.DefaultIngressClassKey,
+	},
+}
+
 // Validator validates KnativeServing CR's
 type Validator struct {
-	client  client.Client
+	rules   []validation.Rule
 	decoder *admission.Decoder
 }
 
 // NewValidator creates a new Validator instance to validate KnativeServing CRs.
 func NewValidator(client client.Client, decoder *admission.Decoder) *Validator {
 	return &Validator{
-		client:  client,
+		rules:   Rules(client),
 		decoder: decoder,
 	}
 }
 
+// Rules returns the rules KnativeServing CRs are validated with.
+func Rules(c client.Reader) []validation.Rule {
+	return []validation.Rule{
+		validation.RequiredNamespace("REQUIRED_SERVING_NAMESPACE"),
+		validation.Singleton(c, func() client.ObjectList { return &servingv1alpha1.KnativeServingList{} }),
+		validation.HighAvailability(validation.ComponentHighAvailability),
+		validation.DeprecatedConfig(deprecatedConfig),
+		validation.Config(configSchemas),
+	}
+}
+
 // Implement admission.Handler so the controller can handle admission request.
 var _ admission.Handler = (*Validator)(nil)
 
@@ -38,55 +56,10 @@ func (v *Validator) Handle(ctx context.Context, req admission.Request) admission
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	allowed, reason, err := v.validate(ctx, ks)
+	result, err := validation.Validate(ctx, ks, v.rules...)
 	if err != nil {
+		common.Log.WithName("validate").Error(err, "Failed to validate KnativeServing")
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
-	return admission.ValidationResponse(allowed, reason)
-}
-
-// Validator checks for a minimum OpenShift version
-func (v *Validator) validate(ctx context.Context, ks *servingv1alpha1.KnativeServing) (allowed bool, reason string, err error) {
-	log := common.Log.WithName("validate")
-	stages := []func(context.Context, *servingv1alpha1.KnativeServing) (bool, string, error){
-		v.validateNamespace,
-		v.validateLoneliness,
-	}
-	for _, stage := range stages {
-		allowed, reason, err = stage(ctx, ks)
-		if len(reason) > 0 {
-			if err != nil {
-				log.Error(err, reason)
-			} else {
-				log.Info(reason)
-			}
-		}
-		if !allowed {
-			return
-		}
-	}
-	return
-}
-
-// validate required namespace, if any
-func (v *Validator) validateNamespace(ctx context.Context, ks *servingv1alpha1.KnativeServing) (bool, string, error) {
-	ns, required := os.LookupEnv("REQUIRED_SERVING_NAMESPACE")
-	if required && ns != ks.Namespace {
-		return false, fmt.Sprintf("KnativeServing may only be created in %s namespace", ns), nil
-	}
-	return true, "", nil
-}
-
-// validate this is the only KS in this namespace
-func (v *Validator) validateLoneliness(ctx context.Context, ks *servingv1alpha1.KnativeServing) (bool, string, error) {
-	list := &servingv1alpha1.KnativeServingList{}
-	if err := v.client.List(ctx, list, &client.ListOptions{Namespace: ks.Namespace}); err != nil {
-		return false, "Unable to list KnativeServings", err
-	}
-	for _, v := range list.Items {
-		if ks.Name != v.Name {
-			return false, "Only one KnativeServing allowed per namespace", nil
-		}
-	}
-	return true, "", nil
+	return result.Response()
 }
@@ -38,7 +38,7 @@ func TestInvalidNamespace(t *testing.T) {
 	os.Clearenv()
 	os.Setenv("REQUIRED_SERVING_NAMESPACE", "knative-serving")
 
-	validator := NewValidator(nil, decoder)
+	validator := NewValidator(fake.NewClientBuilder().Build(), decoder)
 
 	req, err := testutil.RequestFor(ks1)
 	if err != nil {
@@ -66,3 +66,84 @@ func TestLoneliness(t *testing.T) {
 		t.Errorf("Too many KnativeServings: %v", result.AdmissionResponse)
 	}
 }
+
+func TestDeprecatedConfigWarning(t *testing.T) {
+	os.Clearenv()
+
+	validator := NewValidator(fake.NewClientBuilder().Build(), decoder)
+
+	ks := ks1.DeepCopy()
+	ks.Spec.Config = servingv1alpha1.ConfigMapData{"network": {"clusteringress.class": "kourier.ingress.networking.knative.dev"}}
+	req, err := testutil.RequestFor(ks)
+	if err != nil {
+		t.Fatalf("Failed to generate a request for %v: %v", ks, err)
+	}
+
+	result := validator.Handle(context.Background(), req)
+	if !result.Allowed {
+		t.Errorf("The request is not allowed but should be: %v", result.Result)
+	}
+	want := []string{"spec.config.network.clusteringress.class is deprecated, use ingress.class instead"}
+	if len(result.Warnings) != 1 || result.Warnings[0] != want[0] {
+		t.Errorf("Got warnings %v, want %v", result.Warnings, want)
+	}
+}
+
+func TestConfigSchemas(t *testing.T) {
+	os.Clearenv()
+
+	cases := []struct {
+		name     string
+		config   servingv1alpha1.ConfigMapData
+		allowed  bool
+		warnings []string
+	}{{
+		name: "valid",
+		config: servingv1alpha1.ConfigMapData{
+			"autoscaler":    {"stable-window": "120s", "container-concurrency-target-default": "50"},
+			"network":       {"domainTemplate": "{{.Name}}-{{.Namespace}}.{{.Domain}}", "ingress.class": "kourier.ingress.networking.knative.dev"},
+			"deployment":    {"queueSidecarImage": "quay.io/queue:latest", "progressDeadline": "10m", "queueSidecarCPURequest": "25m"},
+			"observability": {"metrics.backend-destination": "prometheus", "_example": "docs"},
+			"domain":        {"example.com": ""},
+		},
+		allowed: true,
+	}, {
+		name:     "typo",
+		config:   servingv1alpha1.ConfigMapData{"autoscaler": {"container-concurency-target-default": "50"}},
+		allowed:  true,
+		warnings: []string{"spec.config.autoscaler.container-concurency-target-default is not a known key, did you mean container-concurrency-target-default?"},
+	}, {
+		name:    "autoscaler out of range",
+		config:  servingv1alpha1.ConfigMapData{"autoscaler": {"stable-window": "1s"}},
+		allowed: false,
+	}, {
+		name:    "gc invalid",
+		config:  servingv1alpha1.ConfigMapData{"gc": {"min-non-active-revisions": "many"}},
+		allowed: false,
+	}, {
+		name:    "deployment invalid",
+		config:  servingv1alpha1.ConfigMapData{"config-deployment": {"queueSidecarMemoryLimit": "lots"}},
+		allowed: false,
+	}}
+
+	validator := NewValidator(fake.NewClientBuilder().Build(), decoder)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ks := ks1.DeepCopy()
+			ks.Spec.HighAvailability = &servingv1alpha1.HighAvailability{Replicas: 2}
+			ks.Spec.Config = tc.config
+			req, err := testutil.RequestFor(ks)
+			if err != nil {
+				t.Fatalf("Failed to generate a request for %v: %v", ks, err)
+			}
+
+			result := validator.Handle(context.Background(), req)
+			if result.Allowed != tc.allowed {
+				t.Errorf("Allowed = %v, want %v: %v", result.Allowed, tc.allowed, result.Result)
+			}
+			if len(result.Warnings) != len(tc.warnings) || (len(tc.warnings) > 0 && result.Warnings[0] != tc.warnings[0]) {
+				t.Errorf("Got warnings %v, want %v", result.Warnings, tc.warnings)
+			}
+		})
+	}
+}
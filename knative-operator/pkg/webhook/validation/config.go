@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// exampleKey documents a ConfigMap, it's allowed in all of them.
+const exampleKey = "_example"
+
+// ConfigSchema describes the data of a ConfigMap configured through spec.config.
+type ConfigSchema struct {
+	// Keys are the keys the component reads from the ConfigMap.
+	Keys sets.String
+	// Parse returns an error if a value has the wrong type or is out of range. It's usually
+	// the parser of the component itself.
+	Parse func(data map[string]string) error
+}
+
+// ObservabilitySchema is the schema of config-observability, which all components share.
+var ObservabilitySchema = ConfigSchema{
+	Keys: sets.NewString(
+		"logging.enable-var-log-collection",
+		"logging.revision-url-template",
+		metrics.ReqLogTemplateKey,
+		metrics.EnableReqLogKey,
+		metrics.EnableProbeReqLogKey,
+		metrics.BackendDestinationKey,
+		"metrics.request-metrics-backend-destination",
+		"metrics.allow-stackdriver-custom-metrics",
+		"metrics.opencensus-address",
+		"metrics.opencensus-require-tls",
+		"metrics.reporting-period-seconds",
+		"metrics.stackdriver-cluster-name",
+		"metrics.stackdriver-custom-metrics-subdomain",
+		"metrics.stackdriver-gcp-location",
+		"metrics.stackdriver-project-id",
+		"metrics.stackdriver-use-secret",
+		"profiling.enable",
+	),
+	Parse: func(data map[string]string) error {
+		_, err := metrics.NewObservabilityConfigFromConfigMap(&corev1.ConfigMap{Data: data})
+		return err
+	},
+}
+
+// Config validates spec.config against the schemas of the ConfigMaps, which are keyed by
+// their name without the "config-" prefix. Unknown keys are likely typos that are silently
+// ignored, so they're warned about. Invalid values are denied. ConfigMaps without schema
+// aren't checked.
+func Config(schemas map[string]ConfigSchema) Rule {
+	return func(_ context.Context, obj client.Object, result *Result) error {
+		component, ok := obj.(operatorv1alpha1.KComponent)
+		if !ok {
+			return nil
+		}
+		config := component.GetSpec().GetConfig()
+		for _, name := range sets.StringKeySet(config).List() {
+			schema, ok := schemas[strings.TrimPrefix(name, "config-")]
+			if !ok {
+				continue
+			}
+			data := config[name]
+			for _, key := range sets.StringKeySet(data).Difference(schema.Keys).Delete(exampleKey).List() {
+				if suggestion := closest(key, schema.Keys); suggestion != "" {
+					result.Warn("spec.config.%s.%s is not a known key, did you mean %s?", name, key, suggestion)
+				} else {
+					result.Warn("spec.config.%s.%s is not a known key", name, key)
+				}
+			}
+			if schema.Parse != nil {
+				if err := schema.Parse(data); err != nil {
+					result.Deny("spec.config.%s is invalid: %v", name, err)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// maxTypoDistance is the largest edit distance of a key considered a typo of a known key.
+const maxTypoDistance = 3
+
+// closest returns the known key closest to the given one, if it's likely a typo of it.
+func closest(key string, known sets.String) string {
+	best, bestDistance := "", maxTypoDistance+1
+	for _, k := range known.List() {
+		if d := distance(key, k); d < bestDistance {
+			best, bestDistance = k, d
+		}
+	}
+	return best
+}
+
+// distance returns the Levenshtein distance of the given strings.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
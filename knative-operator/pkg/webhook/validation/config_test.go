@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/sets"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestConfig(t *testing.T) {
+	schemas := map[string]ConfigSchema{
+		"autoscaler": {
+			Keys: sets.NewString("stable-window", "max-scale"),
+			Parse: func(data map[string]string) error {
+				if data["max-scale"] == "-1" {
+					return errors.New("max-scale must be non-negative")
+				}
+				return nil
+			},
+		},
+	}
+
+	cases := []struct {
+		name   string
+		config operatorv1alpha1.ConfigMapData
+		want   Result
+	}{{
+		name:   "valid",
+		config: operatorv1alpha1.ConfigMapData{"autoscaler": {"stable-window": "60s", "_example": "docs"}},
+	}, {
+		name:   "no schema",
+		config: operatorv1alpha1.ConfigMapData{"domain": {"example.com": ""}},
+	}, {
+		name:   "typo",
+		config: operatorv1alpha1.ConfigMapData{"config-autoscaler": {"stable-windw": "60s"}},
+		want: Result{Warnings: []string{
+			"spec.config.config-autoscaler.stable-windw is not a known key, did you mean stable-window?",
+		}},
+	}, {
+		name:   "unknown",
+		config: operatorv1alpha1.ConfigMapData{"autoscaler": {"enable-magic": "true"}},
+		want:   Result{Warnings: []string{"spec.config.autoscaler.enable-magic is not a known key"}},
+	}, {
+		name:   "invalid",
+		config: operatorv1alpha1.ConfigMapData{"autoscaler": {"max-scale": "-1"}},
+		want:   Result{Denials: []string{"spec.config.autoscaler is invalid: max-scale must be non-negative"}},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ks := &operatorv1alpha1.KnativeServing{
+				Spec: operatorv1alpha1.KnativeServingSpec{CommonSpec: operatorv1alpha1.CommonSpec{Config: tc.config}},
+			}
+			got, err := Validate(context.Background(), ks, Config(schemas))
+			if err != nil {
+				t.Fatal("Validate() =", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("got unexpected result (-want, +got): %s", diff)
+			}
+		})
+	}
+}
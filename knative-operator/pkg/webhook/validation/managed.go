@@ -0,0 +1,25 @@
+package validation
+
+import (
+	"github.com/openshift-knative/serverless-operator/pkg/managed"
+)
+
+// ManagedFields reports the values of managed fields the operator overwrote while mutating an
+// object. Values the request changed compared to the old object are denied, as the operator
+// would silently revert them. Other conflicts, like the ones of a new object, are warned
+// about. The old values are nil for new objects.
+func ManagedFields(conflicts []managed.Conflict, old map[string]string) Result {
+	result := Result{}
+	for _, conflict := range conflicts {
+		if old == nil {
+			result.Warn("%v, add it to the %s annotation to keep it", conflict, managed.UnmanagedFieldsAnnotation)
+			continue
+		}
+		if value, ok := old[conflict.Path]; ok && value == conflict.Value {
+			// Not changed by the user, the operator just updates its own value.
+			continue
+		}
+		result.Deny("%v, add it to the %s annotation to change it", conflict, managed.UnmanagedFieldsAnnotation)
+	}
+	return result
+}
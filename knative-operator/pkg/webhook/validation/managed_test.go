@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/pkg/managed"
+)
+
+func TestManagedFields(t *testing.T) {
+	conflicts := []managed.Conflict{{
+		Path:    "spec.config.deployment.queueSidecarImage",
+		Value:   "mine",
+		Managed: "operator",
+	}, {
+		Path:    "spec.registry.override.activator",
+		Value:   "stale",
+		Removed: true,
+	}}
+
+	cases := []struct {
+		name string
+		old  map[string]string
+		want Result
+	}{{
+		name: "create",
+		want: Result{Warnings: []string{
+			`spec.config.deployment.queueSidecarImage is managed by the operator, the value "mine" is replaced by "operator", add it to the operator.knative.openshift.io/unmanaged-fields annotation to keep it`,
+			`spec.registry.override.activator is managed by the operator, the value "stale" is removed, add it to the operator.knative.openshift.io/unmanaged-fields annotation to keep it`,
+		}},
+	}, {
+		name: "update",
+		old: map[string]string{
+			"spec.config.deployment.queueSidecarImage": "operator",
+			"spec.registry.override.activator":         "stale",
+		},
+		want: Result{Denials: []string{
+			`spec.config.deployment.queueSidecarImage is managed by the operator, the value "mine" is replaced by "operator", add it to the operator.knative.openshift.io/unmanaged-fields annotation to change it`,
+		}},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ManagedFields(conflicts, tc.old)); diff != "" {
+				t.Errorf("got unexpected result (-want, +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RequiredNamespace denies objects outside of the namespace set in the given environment
+// variable, if any.
+func RequiredNamespace(env string) Rule {
+	return func(_ context.Context, obj client.Object, result *Result) error {
+		ns, required := os.LookupEnv(env)
+		if required && ns != obj.GetNamespace() {
+			result.Deny("%s may only be created in %s namespace", kind(obj), ns)
+		}
+		return nil
+	}
+}
+
+// Singleton denies a second object of the same kind in a namespace. The list returned by
+// newList selects the kind.
+func Singleton(c client.Reader, newList func() client.ObjectList) Rule {
+	return func(ctx context.Context, obj client.Object, result *Result) error {
+		list := newList()
+		if err := c.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+			return fmt.Errorf("unable to list %ss: %w", kind(obj), err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return fmt.Errorf("unable to extract %ss: %w", kind(obj), err)
+		}
+		for _, item := range items {
+			other, err := meta.Accessor(item)
+			if err != nil {
+				return err
+			}
+			if other.GetName() != obj.GetName() {
+				result.Deny("Only one %s allowed per namespace", kind(obj))
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// HighAvailability warns about control planes that are scaled to a single replica. The
+// given function returns the high availability settings of the object.
+func HighAvailability(settings func(client.Object) *operatorv1alpha1.HighAvailability) Rule {
+	return func(_ context.Context, obj client.Object, result *Result) error {
+		if ha := settings(obj); ha != nil && ha.Replicas == 1 {
+			result.Warn("spec.high-availability.replicas is 1, the control plane of %s isn't highly available", kind(obj))
+		}
+		return nil
+	}
+}
+
+// ComponentHighAvailability returns the high availability settings of a KnativeServing or a
+// KnativeEventing.
+func ComponentHighAvailability(obj client.Object) *operatorv1alpha1.HighAvailability {
+	if component, ok := obj.(operatorv1alpha1.KComponent); ok {
+		return component.GetSpec().GetHighAvailability()
+	}
+	return nil
+}
+
+// DeprecatedConfig warns about the deprecated keys of spec.config. The keys are given by
+// ConfigMap, without the "config-" prefix, and map to their replacement.
+func DeprecatedConfig(deprecated map[string]map[string]string) Rule {
+	return func(_ context.Context, obj client.Object, result *Result) error {
+		component, ok := obj.(operatorv1alpha1.KComponent)
+		if !ok {
+			return nil
+		}
+		config := component.GetSpec().GetConfig()
+		for _, name := range sets.StringKeySet(config).List() {
+			keys := deprecated[strings.TrimPrefix(name, "config-")]
+			for _, key := range sets.StringKeySet(config[name]).List() {
+				if replacement, ok := keys[key]; ok {
+					result.Warn("spec.config.%s.%s is deprecated, use %s instead", name, key, replacement)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// kind returns the kind of the object, which might not be set on typed objects.
+func kind(obj client.Object) string {
+	if k := obj.GetObjectKind().GroupVersionKind().Kind; k != "" {
+		return k
+	}
+	t := fmt.Sprintf("%T", obj)
+	return t[strings.LastIndex(t, ".")+1:]
+}
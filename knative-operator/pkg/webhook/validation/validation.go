@@ -0,0 +1,64 @@
+// Package validation validates the component CRs with composable rules. The rules deny an
+// object or warn about it, all of them are evaluated so every problem is reported at once.
+// They run in the validating webhooks and in the validate command's dry-run.
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Rule checks an object and records its findings in the result. It only returns an error if
+// the object couldn't be checked.
+type Rule func(ctx context.Context, obj client.Object, result *Result) error
+
+// Result is the outcome of validating an object.
+type Result struct {
+	// Denials are the reasons the object isn't allowed.
+	Denials []string
+	// Warnings are returned to the user, the object is allowed nevertheless.
+	Warnings []string
+}
+
+// Deny records a reason the object isn't allowed.
+func (r *Result) Deny(format string, args ...interface{}) {
+	r.Denials = append(r.Denials, fmt.Sprintf(format, args...))
+}
+
+// Warn records a warning about the object.
+func (r *Result) Warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Allowed returns true if no rule denied the object.
+func (r Result) Allowed() bool {
+	return len(r.Denials) == 0
+}
+
+// Reason returns the reasons the object isn't allowed.
+func (r Result) Reason() string {
+	return strings.Join(r.Denials, "; ")
+}
+
+// Response returns the admission response of the result.
+func (r Result) Response() admission.Response {
+	return admission.ValidationResponse(r.Allowed(), r.Reason()).WithWarnings(r.Warnings...)
+}
+
+// Validate checks the object with all of the given rules. The errors of rules that couldn't
+// check the object are aggregated, the findings of the others are returned nevertheless.
+func Validate(ctx context.Context, obj client.Object, rules ...Rule) (Result, error) {
+	result := Result{}
+	var errs []error
+	for _, rule := range rules {
+		if err := rule(ctx, obj, &result); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return result, utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/apis"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	operatorv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	apis.AddToScheme(scheme.Scheme)
+}
+
+func TestValidateAggregates(t *testing.T) {
+	deny := func(reason string) Rule {
+		return func(_ context.Context, _ client.Object, result *Result) error {
+			result.Deny(reason)
+			return nil
+		}
+	}
+	warn := func(_ context.Context, _ client.Object, result *Result) error {
+		result.Warn("careful")
+		return nil
+	}
+	fail := func(msg string) Rule {
+		return func(context.Context, client.Object, *Result) error {
+			return errors.New(msg)
+		}
+	}
+
+	result, err := Validate(context.Background(), &operatorv1alpha1.KnativeServing{}, deny("first"), warn, deny("second"))
+	if err != nil {
+		t.Fatal("Validate() =", err)
+	}
+	want := Result{Denials: []string{"first", "second"}, Warnings: []string{"careful"}}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("got unexpected result (-want, +got): %s", diff)
+	}
+
+	resp := result.Response()
+	if resp.Allowed {
+		t.Error("got an allowed response, want it denied")
+	}
+	if got, want := resp.Result.Reason, "first; second"; string(got) != want {
+		t.Errorf("got reason %q, want %q", got, want)
+	}
+	if diff := cmp.Diff([]string{"careful"}, resp.Warnings); diff != "" {
+		t.Errorf("got unexpected warnings (-want, +got): %s", diff)
+	}
+
+	result, err = Validate(context.Background(), &operatorv1alpha1.KnativeServing{}, fail("unable to list"), deny("denied"), fail("unable to get"))
+	if err == nil || err.Error() != "[unable to list, unable to get]" {
+		t.Errorf("Validate() = %v, want the errors of both rules", err)
+	}
+	if result.Allowed() {
+		t.Error("got an allowed result, want the findings of the other rules")
+	}
+}
+
+func TestRules(t *testing.T) {
+	ks := func(name, namespace string, replicas int32, config operatorv1alpha1.ConfigMapData) *operatorv1alpha1.KnativeServing {
+		return &operatorv1alpha1.KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: operatorv1alpha1.KnativeServingSpec{CommonSpec: operatorv1alpha1.CommonSpec{
+				HighAvailability: &operatorv1alpha1.HighAvailability{Replicas: replicas},
+				Config:           config,
+			}},
+		}
+	}
+	existing := ks("existing", "knative-serving", 2, nil)
+	deprecated := map[string]map[string]string{"network": {"clusteringress.class": "ingress.class"}}
+
+	cases := []struct {
+		name string
+		obj  client.Object
+		want Result
+	}{{
+		name: "valid",
+		obj:  ks("existing", "knative-serving", 2, nil),
+	}, {
+		name: "all findings",
+		obj:  ks("second", "default", 1, operatorv1alpha1.ConfigMapData{"config-network": {"clusteringress.class": "kourier.ingress.networking.knative.dev"}}),
+		want: Result{
+			Denials: []string{
+				"KnativeServing may only be created in knative-serving namespace",
+			},
+			Warnings: []string{
+				"spec.high-availability.replicas is 1, the control plane of KnativeServing isn't highly available",
+				"spec.config.config-network.clusteringress.class is deprecated, use ingress.class instead",
+			},
+		},
+	}, {
+		name: "second in namespace",
+		obj:  ks("second", "knative-serving", 2, operatorv1alpha1.ConfigMapData{"network": {"ingress.class": "kourier.ingress.networking.knative.dev"}}),
+		want: Result{Denials: []string{"Only one KnativeServing allowed per namespace"}},
+	}}
+
+	os.Setenv("REQUIRED_TEST_NAMESPACE", "knative-serving")
+	defer os.Unsetenv("REQUIRED_TEST_NAMESPACE")
+	c := fake.NewClientBuilder().WithObjects(existing).Build()
+	rules := []Rule{
+		RequiredNamespace("REQUIRED_TEST_NAMESPACE"),
+		Singleton(c, func() client.ObjectList { return &operatorv1alpha1.KnativeServingList{} }),
+		HighAvailability(ComponentHighAvailability),
+		DeprecatedConfig(deprecated),
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Validate(context.Background(), tc.obj, rules...)
+			if err != nil {
+				t.Fatal("Validate() =", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("got unexpected result (-want, +got): %s", diff)
+			}
+		})
+	}
+}
@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+
+	"k8s.io/client-go/tools/cache"
+	knativeeventinginformer "knative.dev/operator/pkg/client/injection/informers/operator/v1alpha1/knativeeventing"
+	knativeservinginformer "knative.dev/operator/pkg/client/injection/informers/operator/v1alpha1/knativeserving"
 	"knative.dev/operator/pkg/reconciler/knativeeventing"
 	"knative.dev/operator/pkg/reconciler/knativeserving"
 	"knative.dev/pkg/injection/sharedmain"
 
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/eventing"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/serving"
 )
 
 func main() {
 	sharedmain.Main("knative-operator",
-		knativeeventing.NewExtendedController(eventing.NewExtension),
-		knativeserving.NewExtendedController(serving.NewExtension),
+		common.ResyncOnImageCatalogChange(knativeeventing.NewExtendedController(eventing.NewExtension),
+			func(ctx context.Context) cache.SharedInformer { return knativeeventinginformer.Get(ctx).Informer() }),
+		common.ResyncOnImageCatalogChange(knativeserving.NewExtendedController(serving.NewExtension),
+			func(ctx context.Context) cache.SharedInformer { return knativeservinginformer.Get(ctx).Informer() }),
 	)
 }
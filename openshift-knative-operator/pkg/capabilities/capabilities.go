@@ -0,0 +1,199 @@
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openshift-knative/serverless-operator/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextension "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// LoggingAvailable is a Condition indicating whether the Kibana route of OpenShift
+	// Logging exists, which is needed to configure the revision log URL template.
+	LoggingAvailable apis.ConditionType = "LoggingAvailable"
+	// DashboardsAvailable is a Condition indicating whether the namespace the console
+	// dashboards are installed into exists.
+	DashboardsAvailable apis.ConditionType = "DashboardsAvailable"
+	// QuickStartsAvailable is a Condition indicating whether the console supports
+	// ConsoleQuickStarts.
+	QuickStartsAvailable apis.ConditionType = "QuickStartsAvailable"
+	// MonitoringAvailable is a Condition indicating whether the monitoring stack's
+	// ServiceMonitor API is installed.
+	MonitoringAvailable apis.ConditionType = "MonitoringAvailable"
+	// KafkaChannelAvailable is a Condition indicating whether KnativeKafka installed the
+	// KafkaChannel API.
+	KafkaChannelAvailable apis.ConditionType = "KafkaChannelAvailable"
+
+	loggingNamespace       = "openshift-logging"
+	loggingRouteName       = "kibana"
+	configManagedNamespace = "openshift-config-managed"
+	quickStartCRDName      = "consolequickstarts.console.openshift.io"
+	serviceMonitorCRDName  = "servicemonitors.monitoring.coreos.com"
+	kafkaChannelCRDName    = "kafkachannels.messaging.knative.dev"
+
+	// resyncPeriod bounds how long a cached result can be stale for capabilities that
+	// aren't backed by a CRD and thus aren't invalidated by CRD events.
+	resyncPeriod = 10 * time.Minute
+)
+
+var (
+	// Serving lists the capabilities published on KnativeServing.
+	Serving = []apis.ConditionType{LoggingAvailable, DashboardsAvailable, QuickStartsAvailable, MonitoringAvailable}
+	// Eventing lists the capabilities published on KnativeEventing.
+	Eventing = []apis.ConditionType{DashboardsAvailable, MonitoringAvailable}
+
+	// condSet has no dependents, so setting conditions through it never affects readiness.
+	condSet = apis.NewLivingConditionSet()
+)
+
+// Result is the outcome of probing the cluster for a single capability.
+type Result struct {
+	Status  corev1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+// Available returns true if the capability has been found on the cluster.
+func (r Result) Available() bool {
+	return r.Status == corev1.ConditionTrue
+}
+
+// Set maps capabilities to the result of probing for them.
+type Set map[apis.ConditionType]Result
+
+// Has returns true if the given capability is available.
+func (s Set) Has(t apis.ConditionType) bool {
+	return s[t].Available()
+}
+
+// MarkConditions publishes the given capabilities as informational conditions.
+func (s Set) MarkConditions(status apis.ConditionsAccessor, types ...apis.ConditionType) {
+	manager := condSet.Manage(status)
+	for _, t := range types {
+		result, ok := s[t]
+		if !ok {
+			continue
+		}
+		manager.SetCondition(apis.Condition{
+			Type:     t,
+			Status:   result.Status,
+			Severity: apis.ConditionSeverityInfo,
+			Reason:   result.Reason,
+			Message:  result.Message,
+		})
+	}
+}
+
+// Detector probes the cluster for optional capabilities. Results are cached until a
+// CustomResourceDefinition is added, updated or removed.
+type Detector struct {
+	kubeclient   kubernetes.Interface
+	ocpclient    versioned.Interface
+	apiextclient apiextension.Interface
+
+	mu     sync.Mutex
+	cached Set
+}
+
+// NewDetector creates a new Detector using the given clients.
+func NewDetector(kubeclient kubernetes.Interface, ocpclient versioned.Interface, apiextclient apiextension.Interface) *Detector {
+	return &Detector{
+		kubeclient:   kubeclient,
+		ocpclient:    ocpclient,
+		apiextclient: apiextclient,
+	}
+}
+
+// Get returns the cluster's capabilities, probing for them if there are no cached results.
+func (d *Detector) Get(ctx context.Context) Set {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cached != nil {
+		return d.cached
+	}
+
+	set := Set{
+		LoggingAvailable:     d.probeLogging(ctx),
+		DashboardsAvailable:  d.probeNamespace(ctx, configManagedNamespace),
+		QuickStartsAvailable: d.probeCRD(ctx, quickStartCRDName),
+		MonitoringAvailable:  d.probeCRD(ctx, serviceMonitorCRDName),
+		// Not published as a condition by itself but needed to validate the default channel.
+		KafkaChannelAvailable: d.probeCRD(ctx, kafkaChannelCRDName),
+	}
+	// Don't cache transient errors, so they're retried on the next call.
+	for _, result := range set {
+		if result.Status == corev1.ConditionUnknown {
+			return set
+		}
+	}
+	d.cached = set
+	return set
+}
+
+// Invalidate drops the cached results, causing the next call to Get to probe again.
+func (d *Detector) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cached = nil
+}
+
+// informer returns an informer that invalidates the cached results whenever a
+// CustomResourceDefinition is added, updated or removed. Updates include the periodic
+// resyncs.
+func (d *Detector) informer(ctx context.Context) cache.SharedIndexInformer {
+	crds := d.apiextclient.ApiextensionsV1().CustomResourceDefinitions()
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return crds.List(ctx, opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return crds.Watch(ctx, opts)
+		},
+	}, &apiextensionsv1.CustomResourceDefinition{}, resyncPeriod, cache.Indexers{})
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { d.Invalidate() },
+		UpdateFunc: func(interface{}, interface{}) { d.Invalidate() },
+		DeleteFunc: func(interface{}) { d.Invalidate() },
+	})
+	return informer
+}
+
+func (d *Detector) probeLogging(ctx context.Context) Result {
+	_, err := d.ocpclient.RouteV1().Routes(loggingNamespace).Get(ctx, loggingRouteName, metav1.GetOptions{})
+	return toResult(err, "RouteNotFound", fmt.Sprintf("Route %s/%s not found", loggingNamespace, loggingRouteName))
+}
+
+func (d *Detector) probeNamespace(ctx context.Context, name string) Result {
+	_, err := d.kubeclient.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	return toResult(err, "NamespaceNotFound", fmt.Sprintf("Namespace %q not found", name))
+}
+
+func (d *Detector) probeCRD(ctx context.Context, name string) Result {
+	_, err := d.apiextclient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+	return toResult(err, "CRDNotFound", fmt.Sprintf("CustomResourceDefinition %q not found", name))
+}
+
+// toResult converts the error of a probe into a Result.
+func toResult(err error, notFoundReason, notFoundMessage string) Result {
+	if err == nil {
+		return Result{Status: corev1.ConditionTrue}
+	}
+	if apierrors.IsNotFound(err) {
+		return Result{Status: corev1.ConditionFalse, Reason: notFoundReason, Message: notFoundMessage}
+	}
+	return Result{Status: corev1.ConditionUnknown, Reason: "ProbeFailed", Message: err.Error()}
+}
@@ -0,0 +1,163 @@
+package capabilities
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ocpfake "github.com/openshift-knative/serverless-operator/pkg/client/clientset/versioned/fake"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+var (
+	loggingRoute = &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: loggingNamespace,
+			Name:      loggingRouteName,
+		},
+	}
+	configManaged = &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: configManagedNamespace,
+		},
+	}
+	quickStartCRD = &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: quickStartCRDName,
+		},
+	}
+	serviceMonitorCRD = &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: serviceMonitorCRDName,
+		},
+	}
+	kafkaChannelCRD = &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: kafkaChannelCRDName,
+		},
+	}
+)
+
+func TestGet(t *testing.T) {
+	cases := []struct {
+		name     string
+		kube     []runtime.Object
+		ocp      []runtime.Object
+		apiext   []runtime.Object
+		expected Set
+	}{{
+		name: "nothing available",
+		expected: Set{
+			LoggingAvailable: {
+				Status:  corev1.ConditionFalse,
+				Reason:  "RouteNotFound",
+				Message: "Route openshift-logging/kibana not found",
+			},
+			DashboardsAvailable: {
+				Status:  corev1.ConditionFalse,
+				Reason:  "NamespaceNotFound",
+				Message: `Namespace "openshift-config-managed" not found`,
+			},
+			QuickStartsAvailable: {
+				Status:  corev1.ConditionFalse,
+				Reason:  "CRDNotFound",
+				Message: `CustomResourceDefinition "consolequickstarts.console.openshift.io" not found`,
+			},
+			MonitoringAvailable: {
+				Status:  corev1.ConditionFalse,
+				Reason:  "CRDNotFound",
+				Message: `CustomResourceDefinition "servicemonitors.monitoring.coreos.com" not found`,
+			},
+			KafkaChannelAvailable: {
+				Status:  corev1.ConditionFalse,
+				Reason:  "CRDNotFound",
+				Message: `CustomResourceDefinition "kafkachannels.messaging.knative.dev" not found`,
+			},
+		},
+	}, {
+		name:   "everything available",
+		kube:   []runtime.Object{configManaged},
+		ocp:    []runtime.Object{loggingRoute},
+		apiext: []runtime.Object{quickStartCRD, serviceMonitorCRD, kafkaChannelCRD},
+		expected: Set{
+			LoggingAvailable:      {Status: corev1.ConditionTrue},
+			DashboardsAvailable:   {Status: corev1.ConditionTrue},
+			QuickStartsAvailable:  {Status: corev1.ConditionTrue},
+			MonitoringAvailable:   {Status: corev1.ConditionTrue},
+			KafkaChannelAvailable: {Status: corev1.ConditionTrue},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := NewDetector(kubefake.NewSimpleClientset(c.kube...), ocpfake.NewSimpleClientset(c.ocp...),
+				apiextensionfake.NewSimpleClientset(c.apiext...))
+
+			got := d.Get(context.Background())
+			if !cmp.Equal(got, c.expected) {
+				t.Errorf("Got = %v, want: %v, diff:\n%s", got, c.expected, cmp.Diff(got, c.expected))
+			}
+		})
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	apiext := apiextensionfake.NewSimpleClientset()
+	d := NewDetector(kubefake.NewSimpleClientset(), ocpfake.NewSimpleClientset(), apiext)
+
+	if d.Get(ctx).Has(MonitoringAvailable) {
+		t.Fatal("Monitoring is available before the CRD exists")
+	}
+
+	if _, err := apiext.ApiextensionsV1().CustomResourceDefinitions().Create(ctx, serviceMonitorCRD, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create CRD", err)
+	}
+	if d.Get(ctx).Has(MonitoringAvailable) {
+		t.Fatal("Monitoring is available although the results should be cached")
+	}
+
+	d.Invalidate()
+	if !d.Get(ctx).Has(MonitoringAvailable) {
+		t.Fatal("Monitoring is not available after invalidating the cache")
+	}
+}
+
+func TestMarkConditions(t *testing.T) {
+	set := Set{
+		LoggingAvailable: {Status: corev1.ConditionTrue},
+		MonitoringAvailable: {
+			Status:  corev1.ConditionFalse,
+			Reason:  "CRDNotFound",
+			Message: "not found",
+		},
+	}
+
+	ks := &v1alpha1.KnativeServing{}
+	ks.Status.InitializeConditions()
+	ks.Status.MarkInstallSucceeded()
+	ks.Status.MarkDeploymentsAvailable()
+	ks.Status.MarkVersionMigrationEligible()
+	set.MarkConditions(&ks.Status, LoggingAvailable, MonitoringAvailable, DashboardsAvailable)
+
+	if !ks.Status.IsReady() {
+		t.Error("Capability conditions must not affect readiness")
+	}
+	if got := ks.Status.GetCondition(LoggingAvailable); !got.IsTrue() || got.Severity != apis.ConditionSeverityInfo {
+		t.Errorf("Unexpected condition %v", got)
+	}
+	if got := ks.Status.GetCondition(MonitoringAvailable); !got.IsFalse() || got.Reason != "CRDNotFound" {
+		t.Errorf("Unexpected condition %v", got)
+	}
+	if got := ks.Status.GetCondition(DashboardsAvailable); got != nil {
+		t.Errorf("Unprobed capability must not be published, got %v", got)
+	}
+}
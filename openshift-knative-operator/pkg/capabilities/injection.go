@@ -0,0 +1,35 @@
+package capabilities
+
+import (
+	"context"
+
+	ocpclient "github.com/openshift-knative/serverless-operator/pkg/client/injection/client"
+	apiextclient "knative.dev/pkg/client/injection/apiextensions/client"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterInformer(With)
+}
+
+// Key is used as the key for associating information with a context.Context.
+type Key struct{}
+
+// With adds a Detector, shared by all controllers, to the context. The returned
+// informer keeps its cache up to date and is started along with the other informers.
+func With(ctx context.Context) (context.Context, controller.Informer) {
+	d := NewDetector(kubeclient.Get(ctx), ocpclient.Get(ctx), apiextclient.Get(ctx))
+	return context.WithValue(ctx, Key{}, d), d.informer(ctx)
+}
+
+// Get extracts the Detector from the context.
+func Get(ctx context.Context) *Detector {
+	untyped := ctx.Value(Key{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic("Unable to fetch *capabilities.Detector from context.")
+	}
+	return untyped.(*Detector)
+}
@@ -2,6 +2,12 @@ package common
 
 import "knative.dev/operator/pkg/apis/operator/v1alpha1"
 
+// ExtensionConfigName is the spec.config section holding the settings that are specific
+// to OpenShift. The KnativeServing and KnativeEventing types are owned upstream, so this
+// section carries the settings that don't have a field of their own. It doesn't match
+// any ConfigMap and is thus never rendered into one.
+const ExtensionConfigName = "openshift"
+
 // Configure sets a value in the given ConfigMap under the given key.
 func Configure(s *v1alpha1.CommonSpec, cm, key, value string) {
 	if s.Config == nil {
@@ -1,34 +1,166 @@
 package common
 
 import (
-	"strings"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/openshift-knative/serverless-operator/pkg/images"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	operator "knative.dev/operator/pkg/reconciler/common"
+	"knative.dev/operator/pkg/reconciler/knativeserving/ingress"
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 )
 
-const imagePrefix = "IMAGE_"
-
-// ImageMapFromEnvironment generates a map of deployment/container to image from
-// the passed environment variables.
-func ImageMapFromEnvironment(env []string) map[string]string {
-	overrideMap := map[string]string{}
-
-	for _, e := range env {
-		pair := strings.SplitN(e, "=", 2)
-		if strings.HasPrefix(pair[0], imagePrefix) {
-			if pair[1] == "" {
-				continue
-			}
-
-			/*
-				converts:
-				IMAGE_container=foo             -> container: foo
-				IMAGE_deployment__container=foo -> deployment/container: foo
-				IMAGE_env_var=foo               -> env_var: foo
-				IMAGE_deployment__env_var=foo   -> deployment/env_var: foo
-			*/
-			name := strings.TrimPrefix(pair[0], imagePrefix)
-			name = strings.Replace(name, "__", "/", 1)
-			overrideMap[name] = pair[1]
-		}
-	}
-	return overrideMap
+// knownImages are consumed by the operators directly rather than through the manifests.
+var knownImages = []string{"queue-proxy", "KUBE_RBAC_PROXY", "KN_CLI_ARTIFACTS"}
+
+// ReconcileImages overrides the images in the given spec with the image catalog and reports
+// invalid and unused overrides on the component's status. Invalid overrides fail the
+// installation with a permanent error.
+func ReconcileImages(ctx context.Context, kube kubernetes.Interface, comp v1alpha1.KComponent, spec *v1alpha1.CommonSpec) (images.Catalog, error) {
+	cm, err := imageCatalogConfigMap(ctx, kube)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, problems := images.Resolve(os.Environ(), cm, images.Prefix)
+	if err := images.PolicyError(problems); err != nil {
+		images.MarkCondition(comp.GetStatus().(apis.ConditionsAccessor), problems, nil)
+		comp.GetStatus().MarkInstallFailed(err.Error())
+		return nil, controller.NewPermanentError(err)
+	}
+	spec.Registry.Override = catalog
+	spec.Registry.Default = catalog[images.DefaultKey]
+
+	var unused []string
+	if resources, err := imageTargets(ctx, comp); err != nil {
+		logging.FromContext(ctx).Warnw("Unable to determine unused image overrides", "error", err)
+	} else {
+		unused = catalog.Unused(resources, knownImages...)
+	}
+	images.MarkCondition(comp.GetStatus().(apis.ConditionsAccessor), problems, unused)
+
+	return catalog, nil
+}
+
+// imageCatalogConfigMap returns the image catalog ConfigMap from the operator's namespace
+// or nil if there is none.
+func imageCatalogConfigMap(ctx context.Context, kube kubernetes.Interface) (*corev1.ConfigMap, error) {
+	ns := os.Getenv(system.NamespaceEnvKey)
+	if ns == "" {
+		return nil, nil
+	}
+
+	cm, err := kube.CoreV1().ConfigMaps(ns).Get(ctx, images.ConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get image catalog ConfigMap: %w", err)
+	}
+	return cm, nil
+}
+
+// targets caches the resources returned by imageTargets, keyed by the manifests they're
+// loaded from, so the manifests aren't parsed on every reconcile.
+var targets = struct {
+	sync.Mutex
+	resources map[string][]unstructured.Unstructured
+}{resources: map[string][]unstructured.Unstructured{}}
+
+// imageTargets returns the resources of all components the image catalog applies to, as
+// the catalog is shared between them. The returned resources must not be modified.
+func imageTargets(ctx context.Context, comp v1alpha1.KComponent) ([]unstructured.Unstructured, error) {
+	if os.Getenv(operator.KoEnvKey) == "" {
+		return nil, fmt.Errorf("%s is not set", operator.KoEnvKey)
+	}
+
+	ks, ok := comp.(*v1alpha1.KnativeServing)
+	if !ok {
+		ks = &v1alpha1.KnativeServing{}
+	}
+	ke, ok := comp.(*v1alpha1.KnativeEventing)
+	if !ok {
+		ke = &v1alpha1.KnativeEventing{}
+	}
+
+	key := fmt.Sprintf("%s|%s%v|%s%v", os.Getenv(operator.KoEnvKey),
+		operator.TargetVersion(ks), ks.Spec.Manifests, operator.TargetVersion(ke), ke.Spec.Manifests)
+	targets.Lock()
+	defer targets.Unlock()
+	if resources, ok := targets.resources[key]; ok {
+		return resources, nil
+	}
+
+	serving, err := operator.TargetManifest(ks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the Serving manifest: %w", err)
+	}
+	if err := ingress.AppendTargetIngresses(ctx, &serving, ks); err != nil {
+		return nil, fmt.Errorf("failed to load the ingress manifests: %w", err)
+	}
+	eventing, err := operator.TargetManifest(ke)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the Eventing manifest: %w", err)
+	}
+	resources := append(serving.Resources(), eventing.Resources()...)
+	targets.resources[key] = resources
+	return resources, nil
+}
+
+// ResyncOnImageCatalogChange wraps the given controller constructor to reconcile all
+// objects of the informer returned by getInformer whenever the image catalog ConfigMap
+// changes, as the catalog applies to all of them.
+func ResyncOnImageCatalogChange(ctor injection.ControllerConstructor, getInformer func(context.Context) cache.SharedInformer) injection.ControllerConstructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		impl := ctor(ctx, cmw)
+		informer := getInformer(ctx)
+		WatchImageCatalog(ctx, kubeclient.Get(ctx), func() { impl.GlobalResync(informer) })
+		return impl
+	}
+}
+
+// WatchImageCatalog calls resync whenever the image catalog ConfigMap in the operator's
+// namespace is added, updated or removed until the context is done.
+func WatchImageCatalog(ctx context.Context, kube kubernetes.Interface, resync func()) {
+	ns := os.Getenv(system.NamespaceEnvKey)
+	if ns == "" {
+		return
+	}
+
+	configMaps := kube.CoreV1().ConfigMaps(ns)
+	selector := fields.OneTermEqualSelector("metadata.name", images.ConfigMapName).String()
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = selector
+			return configMaps.List(ctx, opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = selector
+			return configMaps.Watch(ctx, opts)
+		},
+	}, &corev1.ConfigMap{}, 0, cache.Indexers{})
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { resync() },
+		UpdateFunc: func(interface{}, interface{}) { resync() },
+		DeleteFunc: func(interface{}) { resync() },
+	})
+	go informer.Run(ctx.Done())
 }
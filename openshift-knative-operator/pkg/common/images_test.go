@@ -1,90 +1,180 @@
 package common
 
 import (
-	"fmt"
+	"context"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/pkg/images"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	operator "knative.dev/operator/pkg/reconciler/common"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/system"
 )
 
-func TestImageMapFromEnvironment(t *testing.T) {
+func TestReconcileImages(t *testing.T) {
 	cases := []struct {
-		name     string
-		envMap   map[string]string
-		expected map[string]string
+		name              string
+		env               map[string]string
+		koData            string
+		objs              []runtime.Object
+		expectedOverride  map[string]string
+		expectedCondition *corev1.ConditionStatus
+		expectedMessage   string
+		expectedErr       bool
 	}{{
-		name: "Simple container name",
-		envMap: map[string]string{
-			"IMAGE_foo": "quay.io/myimage",
+		name: "environment only",
+		env: map[string]string{
+			"IMAGE_default":             "quay.io/${NAME}:latest",
+			"IMAGE_controller__webhook": "quay.io/webhook:latest",
 		},
-		expected: map[string]string{
-			"foo": "quay.io/myimage",
+		expectedOverride: map[string]string{
+			"default":            "quay.io/${NAME}:latest",
+			"controller/webhook": "quay.io/webhook:latest",
 		},
+		expectedCondition: conditionStatus(corev1.ConditionTrue),
 	}, {
-		name: "Simple env var",
-		envMap: map[string]string{
-			"IMAGE_CRONJOB_RA_IMAGE": "quay.io/myimage",
+		name: "ConfigMap takes precedence and sets the policy",
+		env: map[string]string{
+			"IMAGE_activator":  "quay.io/activator:latest",
+			"IMAGE_autoscaler": "registry.redhat.io/autoscaler:latest",
 		},
-		expected: map[string]string{
-			"CRONJOB_RA_IMAGE": "quay.io/myimage",
+		objs: []runtime.Object{&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-serverless",
+				Name:      images.ConfigMapName,
+			},
+			Data: map[string]string{
+				"IMAGE_activator":           "registry.redhat.io/activator:latest",
+				images.AllowedRegistriesKey: "registry.redhat.io",
+			},
+		}},
+		expectedOverride: map[string]string{
+			"activator":  "registry.redhat.io/activator:latest",
+			"autoscaler": "registry.redhat.io/autoscaler:latest",
 		},
+		expectedCondition: conditionStatus(corev1.ConditionTrue),
 	}, {
-		name: "Simple env var with deployment name",
-		envMap: map[string]string{
-			"IMAGE_eventing-controller__CRONJOB_RA_IMAGE": "quay.io/myimage",
-		},
-		expected: map[string]string{
-			"eventing-controller/CRONJOB_RA_IMAGE": "quay.io/myimage",
-		},
-	}, {
-		name: "Deployment+container name",
-		envMap: map[string]string{
-			"IMAGE_foo__bar": "quay.io/myimage",
-		},
-		expected: map[string]string{
-			"foo/bar": "quay.io/myimage",
-		},
-	}, {
-		name: "Deployment+container and container name",
-		envMap: map[string]string{
-			"IMAGE_foo__bar": "quay.io/myimage1",
-			"IMAGE_bar":      "quay.io/myimage2",
-		},
-		expected: map[string]string{
-			"foo/bar": "quay.io/myimage1",
-			"bar":     "quay.io/myimage2",
+		name: "invalid override fails the installation",
+		env: map[string]string{
+			"IMAGE_activator":  "quay.io/activator:latest",
+			"IMAGE_autoscaler": "registry.redhat.io/autoscaler:latest",
 		},
+		objs: []runtime.Object{&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-serverless",
+				Name:      images.ConfigMapName,
+			},
+			Data: map[string]string{
+				images.AllowedRegistriesKey: "registry.redhat.io",
+			},
+		}},
+		expectedCondition: conditionStatus(corev1.ConditionFalse),
+		expectedMessage:   `activator: image "quay.io/activator:latest" is not from an allowed registry`,
+		expectedErr:       true,
 	}, {
-		name: "Different prefix",
-		envMap: map[string]string{
-			"X_foo": "quay.io/myimage",
+		name:   "unused override",
+		koData: "../../cmd/operator/kodata",
+		env: map[string]string{
+			"IMAGE_activator":                          "quay.io/activator:latest",
+			"IMAGE_eventing-webhook__eventing-webhook": "quay.io/eventing-webhook:latest",
+			"IMAGE_kourier-gateway":                    "quay.io/kourier:latest",
+			"IMAGE_queue-proxy":                        "quay.io/queue:latest",
+			"IMAGE_foo":                                "quay.io/foo:latest",
 		},
-		expected: map[string]string{},
-	}, {
-		name: "No env var value",
-		envMap: map[string]string{
-			"IMAGE_foo": "",
+		expectedOverride: map[string]string{
+			"activator":                         "quay.io/activator:latest",
+			"eventing-webhook/eventing-webhook": "quay.io/eventing-webhook:latest",
+			"kourier-gateway":                   "quay.io/kourier:latest",
+			"queue-proxy":                       "quay.io/queue:latest",
+			"foo":                               "quay.io/foo:latest",
 		},
-		expected: map[string]string{},
+		expectedCondition: conditionStatus(corev1.ConditionFalse),
+		expectedMessage:   "unused overrides: foo",
 	}}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			environ := environFromMap(c.envMap)
-			overrideMap := ImageMapFromEnvironment(environ)
+			env := map[string]string{
+				system.NamespaceEnvKey: "openshift-serverless",
+				operator.KoEnvKey:      c.koData,
+			}
+			for k, v := range c.env {
+				env[k] = v
+			}
+			for k, v := range env {
+				os.Setenv(k, v)
+			}
+			defer func() {
+				for k := range env {
+					os.Unsetenv(k)
+				}
+			}()
 
-			if !cmp.Equal(overrideMap, c.expected) {
-				t.Errorf("Got = %v, want: %v, diff:\n%s", overrideMap, c.expected, cmp.Diff(overrideMap, c.expected))
+			ks := &v1alpha1.KnativeServing{}
+			catalog, err := ReconcileImages(context.Background(), fake.NewSimpleClientset(c.objs...), ks, &ks.Spec.CommonSpec)
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("ReconcileImages() = %v, want an error: %v", err, c.expectedErr)
+			}
+			if c.expectedErr && !controller.IsPermanentError(err) {
+				t.Errorf("ReconcileImages() = %v, want a permanent error", err)
+			}
+			if c.expectedErr && !ks.Status.GetCondition(v1alpha1.InstallSucceeded).IsFalse() {
+				t.Errorf("Installation hasn't failed: %v", ks.Status.GetCondition(v1alpha1.InstallSucceeded))
+			}
+
+			if !cmp.Equal(ks.Spec.Registry.Override, c.expectedOverride) {
+				t.Errorf("Got = %v, want: %v, diff:\n%s", ks.Spec.Registry.Override, c.expectedOverride, cmp.Diff(ks.Spec.Registry.Override, c.expectedOverride))
+			}
+			if !cmp.Equal(map[string]string(catalog), c.expectedOverride) {
+				t.Errorf("Returned catalog = %v, want: %v", catalog, c.expectedOverride)
+			}
+			if ks.Spec.Registry.Default != c.expectedOverride[images.DefaultKey] {
+				t.Errorf("Default = %q, want: %q", ks.Spec.Registry.Default, c.expectedOverride[images.DefaultKey])
+			}
+
+			cond := ks.Status.GetCondition(images.OverridesValid)
+			if cond == nil || cond.Status != *c.expectedCondition || cond.Message != c.expectedMessage {
+				t.Errorf("Unexpected condition %v", cond)
 			}
 		})
 	}
 }
 
-func environFromMap(envMap map[string]string) []string {
-	e := make([]string, 0, len(envMap))
-	for k, v := range envMap {
-		e = append(e, fmt.Sprintf("%s=%s", k, v))
+func conditionStatus(s corev1.ConditionStatus) *corev1.ConditionStatus {
+	return &s
+}
+
+func TestWatchImageCatalog(t *testing.T) {
+	os.Setenv(system.NamespaceEnvKey, "openshift-serverless")
+	defer os.Unsetenv(system.NamespaceEnvKey)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kube := fake.NewSimpleClientset()
+
+	resyncs := make(chan struct{}, 10)
+	WatchImageCatalog(ctx, kube, func() { resyncs <- struct{}{} })
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-serverless",
+			Name:      images.ConfigMapName,
+		},
+	}
+	if _, err := kube.CoreV1().ConfigMaps(cm.Namespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create ConfigMap:", err)
 	}
 
-	return e
+	select {
+	case <-resyncs:
+	case <-time.After(5 * time.Second):
+		t.Error("No resync after the image catalog has been created")
+	}
 }
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	mf "github.com/manifestival/manifestival"
+	"github.com/openshift-knative/serverless-operator/pkg/images"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
+)
+
+// imageContentSourcePolicies is the resource carrying the cluster's mirror rules.
+var imageContentSourcePolicies = schema.GroupVersionResource{
+	Group:    "operator.openshift.io",
+	Version:  "v1alpha1",
+	Resource: "imagecontentsourcepolicies",
+}
+
+func init() {
+	injection.Default.RegisterInformer(WithImageMirrors)
+}
+
+// imageMirrorsKey is used as the key for associating the ImageMirrors with a context.
+type imageMirrorsKey struct{}
+
+// WithImageMirrors adds ImageMirrors, shared by all controllers, to the context. They're
+// an informer themselves and are started along with the other informers.
+func WithImageMirrors(ctx context.Context) (context.Context, controller.Informer) {
+	m := NewImageMirrors(ctx, kubeclient.Get(ctx), dynamicclient.Get(ctx))
+	return context.WithValue(ctx, imageMirrorsKey{}, m), m
+}
+
+// GetImageMirrors extracts the ImageMirrors from the context.
+func GetImageMirrors(ctx context.Context) *ImageMirrors {
+	untyped := ctx.Value(imageMirrorsKey{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic("Unable to fetch *common.ImageMirrors from context.")
+	}
+	return untyped.(*ImageMirrors)
+}
+
+// ImageMirrors serves the mirror rules from the image catalog ConfigMap and from the
+// cluster's ImageContentSourcePolicies out of informer caches.
+type ImageMirrors struct {
+	// catalog is nil if the operator's namespace is unknown.
+	catalog  cache.SharedIndexInformer
+	policies cache.SharedIndexInformer
+}
+
+// NewImageMirrors creates ImageMirrors, whose informers list and watch with the given
+// context and clients. Clusters without ImageContentSourcePolicies have no such rules.
+func NewImageMirrors(ctx context.Context, kube kubernetes.Interface, dyn dynamic.Interface) *ImageMirrors {
+	m := &ImageMirrors{}
+	if ns := os.Getenv(system.NamespaceEnvKey); ns != "" {
+		configMaps := kube.CoreV1().ConfigMaps(ns)
+		selector := fields.OneTermEqualSelector("metadata.name", images.ConfigMapName).String()
+		m.catalog = cache.NewSharedIndexInformer(&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				opts.FieldSelector = selector
+				return configMaps.List(ctx, opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				opts.FieldSelector = selector
+				return configMaps.Watch(ctx, opts)
+			},
+		}, &corev1.ConfigMap{}, 0, cache.Indexers{})
+	}
+
+	policies := dyn.Resource(imageContentSourcePolicies)
+	m.policies = cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			list, err := policies.List(ctx, opts)
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				return &unstructured.UnstructuredList{}, nil
+			}
+			return list, err
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return policies.Watch(ctx, opts)
+		},
+	}, &unstructured.Unstructured{}, 0, cache.Indexers{})
+	return m
+}
+
+// Run starts the informers until the given channel is closed.
+func (m *ImageMirrors) Run(stopCh <-chan struct{}) {
+	if m.catalog != nil {
+		go m.catalog.Run(stopCh)
+	}
+	m.policies.Run(stopCh)
+}
+
+// HasSynced returns true once all informers have synced.
+func (m *ImageMirrors) HasSynced() bool {
+	return (m.catalog == nil || m.catalog.HasSynced()) && m.policies.HasSynced()
+}
+
+// Get returns the mirror rules, waiting for the informers to sync until the context is
+// done.
+func (m *ImageMirrors) Get(ctx context.Context) (images.Mirrors, error) {
+	if !cache.WaitForCacheSync(ctx.Done(), m.HasSynced) {
+		return nil, fmt.Errorf("failed to wait for the image mirror caches to sync")
+	}
+	return m.cached()
+}
+
+// cached returns the mirror rules from the informers' caches.
+func (m *ImageMirrors) cached() (images.Mirrors, error) {
+	var cm *corev1.ConfigMap
+	if m.catalog != nil {
+		if objs := m.catalog.GetStore().List(); len(objs) > 0 {
+			cm, _ = objs[0].(*corev1.ConfigMap)
+		}
+	}
+	mirrors, err := images.MirrorsFromConfigMap(cm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort the policies, so rules for the same source are always picked the same way.
+	objs := m.policies.GetStore().List()
+	policies := make([]unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			policies = append(policies, *u)
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].GetName() < policies[j].GetName() })
+	return append(mirrors, images.MirrorsFromPolicies(policies)...), nil
+}
+
+// Transform rewrites images to the configured mirrors and reports the rewritten images on
+// the component's status. The rules are read from the caches, which the reconciler has
+// waited for through Get.
+func (m *ImageMirrors) Transform(comp v1alpha1.KComponent) mf.Transformer {
+	mirrors, err := m.cached()
+	if err == nil && !m.HasSynced() {
+		err = fmt.Errorf("image mirror caches haven't synced yet")
+	}
+	if err != nil {
+		return func(*unstructured.Unstructured) error {
+			return err
+		}
+	}
+	return mirrors.Transform(comp.GetStatus().(apis.ConditionsAccessor))
+}
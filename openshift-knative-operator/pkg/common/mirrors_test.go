@@ -0,0 +1,72 @@
+package common
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/pkg/images"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/system"
+)
+
+func TestImageMirrors(t *testing.T) {
+	os.Setenv(system.NamespaceEnvKey, "openshift-serverless")
+	defer os.Unsetenv(system.NamespaceEnvKey)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-serverless",
+			Name:      images.ConfigMapName,
+		},
+		Data: map[string]string{
+			images.MirrorsKey: `
+- source: quay.io/openshift-knative
+  mirrors:
+  - mirror.example.com/knative
+`,
+		},
+	}
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "operator.openshift.io/v1alpha1",
+		"kind":       "ImageContentSourcePolicy",
+		"metadata": map[string]interface{}{
+			"name": "mirrors",
+		},
+		"spec": map[string]interface{}{
+			"repositoryDigestMirrors": []interface{}{
+				map[string]interface{}{
+					"source":  "registry.redhat.io",
+					"mirrors": []interface{}{"mirror.example.com/redhat"},
+				},
+			},
+		},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewImageMirrors(ctx, fake.NewSimpleClientset(cm), dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), policy))
+	go m.Run(ctx.Done())
+
+	mirrors, err := m.Get(ctx)
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+
+	want := images.Mirrors{{
+		Source:  "quay.io/openshift-knative",
+		Mirrors: []string{"mirror.example.com/knative"},
+	}, {
+		Source:  "registry.redhat.io",
+		Mirrors: []string{"mirror.example.com/redhat"},
+	}}
+	if !cmp.Equal(mirrors, want) {
+		t.Errorf("Got = %v, want: %v", mirrors, want)
+	}
+}
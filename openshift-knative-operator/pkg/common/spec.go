@@ -0,0 +1,96 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+// SpecExtensionField is the field of the KnativeServing and KnativeEventing specs that
+// holds the settings specific to OpenShift Serverless. They're not part of the upstream
+// types, so they are read from the unstructured resources.
+const SpecExtensionField = "openshift"
+
+// SpecExtensions serves the OpenShift specific settings of one kind of component from an
+// informer's cache.
+type SpecExtensions struct {
+	gvr       schema.GroupVersionResource
+	resource  dynamic.NamespaceableResourceInterface
+	store     cache.Store
+	hasSynced cache.InformerSynced
+}
+
+// NewSpecExtensions returns the OpenShift specific settings of the components of the given
+// resource. They're kept up to date until the context is done.
+func NewSpecExtensions(ctx context.Context, dyn dynamic.Interface, gvr schema.GroupVersionResource) *SpecExtensions {
+	resource := dyn.Resource(gvr)
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return resource.List(ctx, opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return resource.Watch(ctx, opts)
+		},
+	}, &unstructured.Unstructured{}, 0, cache.Indexers{})
+	go informer.Run(ctx.Done())
+
+	return &SpecExtensions{
+		gvr:       gvr,
+		resource:  resource,
+		store:     informer.GetStore(),
+		hasSynced: informer.HasSynced,
+	}
+}
+
+// Get reads the OpenShift specific settings of the given component into the given value.
+// If the cache hasn't caught up with the given component yet, it's read from the API
+// server instead. A component that's gone has no settings.
+func (s *SpecExtensions) Get(ctx context.Context, comp v1alpha1.KComponent, into interface{}) error {
+	if !cache.WaitForCacheSync(ctx.Done(), s.hasSynced) {
+		return fmt.Errorf("failed to wait for the %s cache to sync", s.gvr.Resource)
+	}
+	obj, exists, err := s.store.GetByKey(comp.GetNamespace() + "/" + comp.GetName())
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s/%s from cache: %w", s.gvr.Resource, comp.GetNamespace(), comp.GetName(), err)
+	}
+	if u, ok := obj.(*unstructured.Unstructured); exists && ok && u.GetResourceVersion() == comp.GetResourceVersion() {
+		return SpecExtensionFrom(u, into)
+	}
+
+	u, err := s.resource.Namespace(comp.GetNamespace()).Get(ctx, comp.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s/%s: %w", s.gvr.Resource, comp.GetNamespace(), comp.GetName(), err)
+	}
+	return SpecExtensionFrom(u, into)
+}
+
+// SpecExtensionFrom reads the OpenShift specific settings of the given unstructured
+// component into the given value. They're decoded as JSON, so whole numbers can be read
+// into floats.
+func SpecExtensionFrom(u *unstructured.Unstructured, into interface{}) error {
+	raw, found, err := unstructured.NestedFieldNoCopy(u.Object, "spec", SpecExtensionField)
+	if err != nil || !found {
+		return err
+	}
+	data, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(data, into)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid spec.%s: %w", SpecExtensionField, err)
+	}
+	return nil
+}
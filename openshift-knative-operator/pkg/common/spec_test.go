@@ -0,0 +1,70 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestSpecExtensions(t *testing.T) {
+	stored := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "operator.knative.dev/v1alpha1",
+		"kind":       "KnativeServing",
+		"metadata": map[string]interface{}{
+			"namespace": "knative-serving",
+			"name":      "knative-serving",
+		},
+		"spec": map[string]interface{}{
+			SpecExtensionField: map[string]interface{}{"setting": "value"},
+		},
+	}}
+	gvr := schema.GroupVersionResource{Group: "operator.knative.dev", Version: "v1alpha1", Resource: "knativeservings"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	specs := NewSpecExtensions(ctx, dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), stored), gvr)
+
+	type extension struct {
+		Setting string `json:"setting"`
+	}
+	cases := []struct {
+		name     string
+		comp     *v1alpha1.KnativeServing
+		expected extension
+	}{{
+		name: "cached",
+		comp: &v1alpha1.KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "knative-serving"},
+		},
+		expected: extension{Setting: "value"},
+	}, {
+		name: "newer than the cache",
+		comp: &v1alpha1.KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "knative-serving", ResourceVersion: "2"},
+		},
+		expected: extension{Setting: "value"},
+	}, {
+		name: "gone",
+		comp: &v1alpha1.KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "gone"},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := extension{}
+			if err := specs.Get(ctx, c.comp, &got); err != nil {
+				t.Fatal("Get() =", err)
+			}
+			if got != c.expected {
+				t.Errorf("Got = %v, want: %v", got, c.expected)
+			}
+		})
+	}
+}
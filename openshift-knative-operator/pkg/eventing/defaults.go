@@ -0,0 +1,108 @@
+package eventing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/capabilities"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// DefaultsAvailable is a Condition indicating whether the implementations of the
+	// configured default channel and broker class are installed.
+	DefaultsAvailable apis.ConditionType = "DefaultsAvailable"
+
+	inMemoryChannel      = "InMemoryChannel"
+	kafkaChannel         = "KafkaChannel"
+	mtChannelBasedBroker = "MTChannelBasedBroker"
+
+	defaultChannelCMName    = "default-ch-webhook"
+	defaultChannelCMKey     = "default-ch-config"
+	brokerDefaultChannelCM  = "config-br-default-channel"
+	brokerDefaultChannelKey = "channelTemplateSpec"
+)
+
+// channel describes an installable channel implementation.
+type channel struct {
+	apiVersion string
+	// capability is the capability needed for the channel to work. Empty if the channel
+	// is always installed.
+	capability apis.ConditionType
+}
+
+var (
+	channels = map[string]channel{
+		inMemoryChannel: {apiVersion: "messaging.knative.dev/v1"},
+		kafkaChannel: {
+			apiVersion: "messaging.knative.dev/v1beta1",
+			capability: capabilities.KafkaChannelAvailable,
+		},
+	}
+
+	defaultsCondSet = apis.NewLivingConditionSet()
+)
+
+// defaults are the cluster wide default implementations for channels and brokers.
+type defaults struct {
+	// Channel is the kind of the default channel. Empty means the manifest's default.
+	Channel string
+	// BrokerClass is the default broker class. Empty means the manifest's default.
+	BrokerClass string
+}
+
+// defaultsFromSpec reads the defaults from the given KnativeEventing and its OpenShift
+// specific settings.
+func defaultsFromSpec(ke *v1alpha1.KnativeEventing, ext SpecExtension) defaults {
+	return defaults{
+		Channel:     ext.DefaultChannel,
+		BrokerClass: ke.Spec.DefaultBrokerClass,
+	}
+}
+
+// reconcileDefaults renders the configured defaults into the respective ConfigMaps.
+// A default channel that isn't installed is reset to the manifest's default so channels
+// keep working. The DefaultsAvailable condition says why, and also flags an unsupported
+// broker class, which is left as the user specified it.
+func reconcileDefaults(ke *v1alpha1.KnativeEventing, ext SpecExtension, caps capabilities.Set) {
+	d := defaultsFromSpec(ke, ext)
+
+	var problems []string
+	if d.Channel != "" {
+		if ch, ok := channels[d.Channel]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown default channel %q, must be one of %s or %s", d.Channel, inMemoryChannel, kafkaChannel))
+		} else if ch.capability != "" && !caps.Has(ch.capability) {
+			problems = append(problems, fmt.Sprintf("default channel %s is not installed, install it through KnativeKafka", d.Channel))
+		} else {
+			common.Configure(&ke.Spec.CommonSpec, defaultChannelCMName, defaultChannelCMKey,
+				fmt.Sprintf("clusterDefault:\n  apiVersion: %s\n  kind: %s\n", ch.apiVersion, d.Channel))
+			common.Configure(&ke.Spec.CommonSpec, brokerDefaultChannelCM, brokerDefaultChannelKey,
+				fmt.Sprintf("apiVersion: %s\nkind: %s\n", ch.apiVersion, d.Channel))
+		}
+	}
+
+	// The broker class itself is rendered into config-br-defaults upstream.
+	if d.BrokerClass != "" && d.BrokerClass != mtChannelBasedBroker {
+		problems = append(problems, fmt.Sprintf("default broker class %q is not installed, only %s is supported", d.BrokerClass, mtChannelBasedBroker))
+	}
+
+	manager := defaultsCondSet.Manage(&ke.Status)
+	if len(problems) == 0 {
+		manager.SetCondition(apis.Condition{
+			Type:     DefaultsAvailable,
+			Status:   corev1.ConditionTrue,
+			Severity: apis.ConditionSeverityInfo,
+		})
+		return
+	}
+	manager.SetCondition(apis.Condition{
+		Type:     DefaultsAvailable,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "DefaultsNotInstalled",
+		Message:  strings.Join(problems, "; "),
+	})
+}
@@ -6,39 +6,72 @@ import (
 	"os"
 
 	mf "github.com/manifestival/manifestival"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/capabilities"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
+	"github.com/openshift-knative/serverless-operator/pkg/managed"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
 	operator "knative.dev/operator/pkg/reconciler/common"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/clients/dynamicclient"
 )
 
 const requiredNsEnvName = "REQUIRED_EVENTING_NAMESPACE"
 
 // NewExtension creates a new extension for a Knative Eventing controller.
 func NewExtension(ctx context.Context) operator.Extension {
+	sinkBindings := &sinkBindingNamespaces{kubeclient: kubeclient.Get(ctx)}
+	sinkBindings.Watch(ctx)
+
 	return &extension{
-		kubeclient: kubeclient.Get(ctx),
+		kubeclient:   kubeclient.Get(ctx),
+		capabilities: capabilities.Get(ctx),
+		sinkBindings: sinkBindings,
+		specs:        common.NewSpecExtensions(ctx, dynamicclient.Get(ctx), knativeEventingGVR),
+		mirrors:      common.GetImageMirrors(ctx),
+		tlsProfile:   monitoring.GetTLSProfile(ctx),
 	}
 }
 
 type extension struct {
-	kubeclient kubernetes.Interface
+	kubeclient   kubernetes.Interface
+	capabilities *capabilities.Detector
+	sinkBindings *sinkBindingNamespaces
+	specs        *common.SpecExtensions
+	mirrors      *common.ImageMirrors
+	tlsProfile   *monitoring.TLSProfile
 }
 
 func (e *extension) Manifests(ke v1alpha1.KComponent) ([]mf.Manifest, error) {
-	return monitoring.GetEventingMonitoringPlatformManifests(ke)
+	spec, err := e.spec(context.TODO(), ke)
+	if err != nil {
+		return nil, err
+	}
+	return monitoring.GetEventingMonitoringPlatformManifests(ke, spec.Monitoring)
 }
 
 func (e *extension) Transformers(ke v1alpha1.KComponent) []mf.Transformer {
-	return monitoring.GetEventingTransformers(ke)
+	spec, err := e.spec(context.TODO(), ke)
+	if err != nil {
+		return []mf.Transformer{func(*unstructured.Unstructured) error {
+			return err
+		}}
+	}
+	return append(monitoring.GetEventingTransformers(context.TODO(), e.tlsProfile, ke, spec.Monitoring),
+		e.mirrors.Transform(ke))
 }
 
 func (e *extension) Reconcile(ctx context.Context, comp v1alpha1.KComponent) error {
 	ke := comp.(*v1alpha1.KnativeEventing)
+	user := ke.Spec.CommonSpec.DeepCopy()
+
+	// Surface optional cluster features so it's visible why a feature is missing.
+	caps := e.capabilities.Get(ctx)
+	caps.MarkConditions(&ke.Status, capabilities.Eventing...)
 
 	requiredNs := os.Getenv(requiredNsEnvName)
 	if requiredNs != "" && ke.Namespace != requiredNs {
@@ -46,11 +79,17 @@ func (e *extension) Reconcile(ctx context.Context, comp v1alpha1.KComponent) err
 		return controller.NewPermanentError(fmt.Errorf("deployed Knative Eventing into unsupported namespace %q", ke.Namespace))
 	}
 
+	spec, err := e.spec(ctx, ke)
+	if err != nil {
+		return err
+	}
+
 	// Override images.
-	// TODO(SRVCOM-1069): Rethink overriding behavior and/or error surfacing.
-	images := common.ImageMapFromEnvironment(os.Environ())
-	ke.Spec.Registry.Override = images
-	ke.Spec.Registry.Default = images["default"]
+	if _, err := common.ReconcileImages(ctx, e.kubeclient, ke, &ke.Spec.CommonSpec); err != nil {
+		return err
+	}
+	// Keep the user's values of the managed fields they opted out of.
+	managed.Restore(ke, user, &ke.Spec.CommonSpec, managed.EventingFields)
 
 	// Ensure webhook has 1G of memory.
 	common.EnsureContainerMemoryLimit(&ke.Spec.CommonSpec, "eventing-webhook", resource.MustParse("1024Mi"))
@@ -60,6 +99,19 @@ func (e *extension) Reconcile(ctx context.Context, comp v1alpha1.KComponent) err
 		ke.Spec.SinkBindingSelectionMode = "inclusion"
 	}
 
+	// Opt the selected namespaces into SinkBinding.
+	selection, err := sinkBindingSelectionFromSpec(ke, spec)
+	if err != nil {
+		ke.Status.MarkInstallFailed(err.Error())
+		return controller.NewPermanentError(err)
+	}
+	if err := e.sinkBindings.apply(ctx, selection); err != nil {
+		return err
+	}
+
+	// Render the default channel and broker class if their implementations are installed.
+	reconcileDefaults(ke, spec, caps)
+
 	// Default to 2 replicas.
 	if ke.Spec.HighAvailability == nil {
 		ke.Spec.HighAvailability = &v1alpha1.HighAvailability{
@@ -67,9 +119,24 @@ func (e *extension) Reconcile(ctx context.Context, comp v1alpha1.KComponent) err
 		}
 	}
 
-	return monitoring.ReconcileMonitoringForEventing(ctx, e.kubeclient, ke)
+	return monitoring.ReconcileMonitoringForEventing(ctx, e.kubeclient, ke, spec.Monitoring)
+}
+
+func (e *extension) Finalize(ctx context.Context, comp v1alpha1.KComponent) error {
+	ke := comp.(*v1alpha1.KnativeEventing)
+
+	// Remove the SinkBinding include labels added by the operator.
+	if err := e.sinkBindings.apply(ctx, noNamespaces); err != nil {
+		return err
+	}
+
+	// Remove cluster-scoped monitoring resources that aren't part of the installed manifest.
+	return monitoring.FinalizeMonitoringForEventing(ctx, e.kubeclient, ke)
 }
 
-func (e *extension) Finalize(context.Context, v1alpha1.KComponent) error {
-	return nil
+// spec returns the OpenShift specific settings of the given KnativeEventing.
+func (e *extension) spec(ctx context.Context, ke v1alpha1.KComponent) (SpecExtension, error) {
+	spec := SpecExtension{}
+	err := e.specs.Get(ctx, ke, &spec)
+	return spec, err
 }
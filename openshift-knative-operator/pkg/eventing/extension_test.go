@@ -7,16 +7,23 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/capabilities"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
 	ocpfake "github.com/openshift-knative/serverless-operator/pkg/client/injection/client/fake"
+	"github.com/openshift-knative/serverless-operator/pkg/images"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	operator "knative.dev/operator/pkg/reconciler/common"
 	"knative.dev/pkg/apis"
+	apiextfake "knative.dev/pkg/client/injection/apiextensions/client/fake"
 	kubefake "knative.dev/pkg/client/injection/kube/client/fake"
+	dynamicfake "knative.dev/pkg/injection/clients/dynamicclient/fake"
 )
 
 const requiredNs = "knative-eventing"
@@ -33,6 +40,7 @@ func init() {
 	os.Setenv("IMAGE_foo", "bar")
 	os.Setenv("IMAGE_default", "bar2")
 	os.Setenv(requiredNsEnvName, requiredNs)
+	os.Setenv(operator.KoEnvKey, "../../cmd/operator/kodata")
 }
 
 func TestReconcile(t *testing.T) {
@@ -108,7 +116,109 @@ func TestReconcile(t *testing.T) {
 			}
 
 			ke := c.in.DeepCopy()
-			ctx, _ := kubefake.With(context.Background(), &eventingNamespace)
+			ctx, _ := ocpfake.With(context.Background())
+			ctx, _ = kubefake.With(ctx, &eventingNamespace)
+			ctx, _ = apiextfake.With(ctx)
+			ctx, _ = dynamicfake.With(ctx, runtime.NewScheme())
+			ctx, _ = capabilities.With(ctx)
+			ctx, mirrors := common.WithImageMirrors(ctx)
+			go mirrors.Run(ctx.Done())
+			ctx, profile := monitoring.WithTLSProfile(ctx)
+			go profile.Run(ctx.Done())
+			ext := NewExtension(ctx)
+			ext.Reconcile(context.Background(), ke)
+
+			// Ignore time differences.
+			opt := cmp.Comparer(func(apis.VolatileTime, apis.VolatileTime) bool {
+				return true
+			})
+
+			if !cmp.Equal(ke, c.expected, opt) {
+				t.Errorf("Got = %v, want: %v, diff:\n%s", ke, c.expected, cmp.Diff(ke, c.expected, opt))
+			}
+		})
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	kafkaChannelCRD := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "kafkachannels.messaging.knative.dev",
+		},
+	}
+
+	cases := []struct {
+		name     string
+		in       *v1alpha1.KnativeEventing
+		spec     SpecExtension
+		apiext   []runtime.Object
+		expected *v1alpha1.KnativeEventing
+	}{{
+		name: "InMemoryChannel",
+		in:   ke(),
+		spec: SpecExtension{DefaultChannel: "InMemoryChannel"},
+		expected: ke(func(ke *v1alpha1.KnativeEventing) {
+			common.Configure(&ke.Spec.CommonSpec, "default-ch-webhook", "default-ch-config",
+				"clusterDefault:\n  apiVersion: messaging.knative.dev/v1\n  kind: InMemoryChannel\n")
+			common.Configure(&ke.Spec.CommonSpec, "config-br-default-channel", "channelTemplateSpec",
+				"apiVersion: messaging.knative.dev/v1\nkind: InMemoryChannel\n")
+		}),
+	}, {
+		name:   "KafkaChannel installed",
+		in:     ke(),
+		spec:   SpecExtension{DefaultChannel: "KafkaChannel"},
+		apiext: []runtime.Object{kafkaChannelCRD},
+		expected: ke(func(ke *v1alpha1.KnativeEventing) {
+			common.Configure(&ke.Spec.CommonSpec, "default-ch-webhook", "default-ch-config",
+				"clusterDefault:\n  apiVersion: messaging.knative.dev/v1beta1\n  kind: KafkaChannel\n")
+			common.Configure(&ke.Spec.CommonSpec, "config-br-default-channel", "channelTemplateSpec",
+				"apiVersion: messaging.knative.dev/v1beta1\nkind: KafkaChannel\n")
+		}),
+	}, {
+		name: "KafkaChannel not installed",
+		in:   ke(),
+		spec: SpecExtension{DefaultChannel: "KafkaChannel"},
+		expected: ke(func(ke *v1alpha1.KnativeEventing) {
+			markDefaultsNotInstalled(ke, "default channel KafkaChannel is not installed, install it through KnativeKafka")
+		}),
+	}, {
+		name: "unknown channel",
+		in:   ke(),
+		spec: SpecExtension{DefaultChannel: "FooChannel"},
+		expected: ke(func(ke *v1alpha1.KnativeEventing) {
+			markDefaultsNotInstalled(ke, `unknown default channel "FooChannel", must be one of InMemoryChannel or KafkaChannel`)
+		}),
+	}, {
+		name: "MTChannelBasedBroker",
+		in: ke(func(ke *v1alpha1.KnativeEventing) {
+			ke.Spec.DefaultBrokerClass = "MTChannelBasedBroker"
+		}),
+		expected: ke(func(ke *v1alpha1.KnativeEventing) {
+			ke.Spec.DefaultBrokerClass = "MTChannelBasedBroker"
+		}),
+	}, {
+		name: "broker class not installed",
+		in: ke(func(ke *v1alpha1.KnativeEventing) {
+			ke.Spec.DefaultBrokerClass = "Kafka"
+		}),
+		expected: ke(func(ke *v1alpha1.KnativeEventing) {
+			ke.Spec.DefaultBrokerClass = "Kafka"
+			markDefaultsNotInstalled(ke, `default broker class "Kafka" is not installed, only MTChannelBasedBroker is supported`)
+		}),
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ke := c.in.DeepCopy()
+			ctx, _ := ocpfake.With(context.Background())
+			ctx, _ = kubefake.With(ctx, &eventingNamespace)
+			ctx, _ = apiextfake.With(ctx, c.apiext...)
+			ctx, _ = dynamicfake.With(ctx, runtime.NewScheme(), withSpecExtension(t, ke, c.spec))
+			ctx, _ = capabilities.With(ctx)
+			ctx, mirrors := common.WithImageMirrors(ctx)
+			go mirrors.Run(ctx.Done())
+			ctx, profile := monitoring.WithTLSProfile(ctx)
+			go profile.Run(ctx.Done())
 			ext := NewExtension(ctx)
 			ext.Reconcile(context.Background(), ke)
 
@@ -242,11 +352,18 @@ func TestMonitoring(t *testing.T) {
 			c.expected.Namespace = ke.Namespace
 			ctx, _ := ocpfake.With(context.Background(), objs...)
 			ctx, kube := kubefake.With(ctx, &eventingNamespace)
+			ctx, _ = apiextfake.With(ctx)
+			ctx, _ = dynamicfake.With(ctx, runtime.NewScheme())
+			ctx, _ = capabilities.With(ctx)
+			ctx, mirrors := common.WithImageMirrors(ctx)
+			go mirrors.Run(ctx.Done())
+			ctx, profile := monitoring.WithTLSProfile(ctx)
+			go profile.Run(ctx.Done())
 			ext := NewExtension(ctx)
 			shouldEnableMonitoring, err := c.setupMonitoringToggle()
 
 			if err != nil {
-				t.Errorf("Failed to setup the monitoring toggle %w", err)
+				t.Errorf("Failed to setup the monitoring toggle %v", err)
 			}
 			ext.Reconcile(context.Background(), ke)
 
@@ -268,6 +385,26 @@ func TestMonitoring(t *testing.T) {
 	}
 }
 
+// withSpecExtension returns the given KnativeEventing as stored in the cluster, with
+// the given OpenShift specific settings.
+func withSpecExtension(t *testing.T, ke *v1alpha1.KnativeEventing, spec SpecExtension) *unstructured.Unstructured {
+	t.Helper()
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ke)
+	if err != nil {
+		t.Fatal("Failed to convert KnativeEventing:", err)
+	}
+	ext, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&spec)
+	if err != nil {
+		t.Fatal("Failed to convert spec extension:", err)
+	}
+	u := &unstructured.Unstructured{Object: obj}
+	u.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("KnativeEventing"))
+	if err := unstructured.SetNestedMap(u.Object, ext, "spec", common.SpecExtensionField); err != nil {
+		t.Fatal("Failed to set spec extension:", err)
+	}
+	return u
+}
+
 func ke(mods ...func(*v1alpha1.KnativeEventing)) *v1alpha1.KnativeEventing {
 	base := &v1alpha1.KnativeEventing{
 		ObjectMeta: metav1.ObjectMeta{
@@ -298,9 +435,41 @@ func ke(mods ...func(*v1alpha1.KnativeEventing)) *v1alpha1.KnativeEventing {
 		},
 	}
 
+	// None of the optional capabilities are available in the fake cluster by default.
+	capabilities.Set{
+		capabilities.DashboardsAvailable: {
+			Status:  corev1.ConditionFalse,
+			Reason:  "NamespaceNotFound",
+			Message: `Namespace "openshift-config-managed" not found`,
+		},
+		capabilities.MonitoringAvailable: {
+			Status:  corev1.ConditionFalse,
+			Reason:  "CRDNotFound",
+			Message: `CustomResourceDefinition "servicemonitors.monitoring.coreos.com" not found`,
+		},
+	}.MarkConditions(&base.Status, capabilities.Eventing...)
+	// The kodata manifests have no image named foo.
+	images.MarkCondition(&base.Status, nil, []string{"foo"})
+	monitoring.MarkComponentsDiscovered(&base.Status, nil)
+	defaultsCondSet.Manage(&base.Status).SetCondition(apis.Condition{
+		Type:     DefaultsAvailable,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	})
+
 	for _, mod := range mods {
 		mod(base)
 	}
 
 	return base
 }
+
+func markDefaultsNotInstalled(ke *v1alpha1.KnativeEventing, msg string) {
+	defaultsCondSet.Manage(&ke.Status).SetCondition(apis.Condition{
+		Type:     DefaultsAvailable,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "DefaultsNotInstalled",
+		Message:  msg,
+	})
+}
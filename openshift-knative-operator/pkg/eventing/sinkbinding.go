@@ -0,0 +1,197 @@
+package eventing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/apis/duck"
+	"knative.dev/pkg/logging"
+)
+
+// sinkBindingManagedAnnotation marks namespaces whose include label has been set by the
+// operator. Labels set by anybody else are never removed.
+const sinkBindingManagedAnnotation = "operator.serverless.openshift.io/sinkbinding-include"
+
+// namespaceSelection selects namespaces by name or by label.
+type namespaceSelection struct {
+	names    sets.String
+	selector labels.Selector
+}
+
+// noNamespaces selects no namespace at all.
+var noNamespaces = namespaceSelection{names: sets.NewString(), selector: labels.Nothing()}
+
+func (s namespaceSelection) matches(ns *corev1.Namespace) bool {
+	return s.names.Has(ns.Name) || s.selector.Matches(labels.Set(ns.Labels))
+}
+
+// SinkBindingSelection selects the namespaces to opt into SinkBinding, by name or by
+// label. A namespace matching either is selected.
+type SinkBindingSelection struct {
+	// Namespaces are the names of the selected namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects namespaces by their labels.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// sinkBindingSelectionFromSpec reads the namespaces opted into SinkBinding from the
+// given KnativeEventing and its OpenShift specific settings. They only matter if the
+// selection mode is inclusion.
+func sinkBindingSelectionFromSpec(ke *v1alpha1.KnativeEventing, ext SpecExtension) (namespaceSelection, error) {
+	if ke.Spec.SinkBindingSelectionMode != "inclusion" || ext.SinkBinding == nil {
+		return noNamespaces, nil
+	}
+
+	// A nil selector selects nothing.
+	selector, err := metav1.LabelSelectorAsSelector(ext.SinkBinding.NamespaceSelector)
+	if err != nil {
+		return noNamespaces, fmt.Errorf("invalid spec.%s.sinkBinding.namespaceSelector: %w", common.SpecExtensionField, err)
+	}
+	return namespaceSelection{names: sets.NewString(ext.SinkBinding.Namespaces...), selector: selector}, nil
+}
+
+// sinkBindingNamespaces maintains the SinkBinding include label on the namespaces
+// selected in KnativeEventing.
+type sinkBindingNamespaces struct {
+	kubeclient kubernetes.Interface
+	// lister and hasSynced are set up by Watch.
+	lister    corelisters.NamespaceLister
+	hasSynced cache.InformerSynced
+
+	mu sync.Mutex
+	// selection is nil until the first reconcile, which causes namespace events to be
+	// ignored until the desired selection is known.
+	selection *namespaceSelection
+}
+
+// apply updates the selection and applies it to all namespaces.
+func (s *sinkBindingNamespaces) apply(ctx context.Context, selection namespaceSelection) error {
+	s.mu.Lock()
+	s.selection = &selection
+	s.mu.Unlock()
+
+	// Namespaces that aren't in the cache yet are handled once they're added.
+	if !cache.WaitForCacheSync(ctx.Done(), s.hasSynced) {
+		return fmt.Errorf("failed to wait for the namespace cache to sync")
+	}
+	namespaces, err := s.lister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for _, ns := range namespaces {
+		if err := s.sync(ctx, selection, ns.DeepCopy()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Watch applies the current selection to every namespace that's added or updated until
+// the context is done.
+func (s *sinkBindingNamespaces) Watch(ctx context.Context) {
+	namespaces := s.kubeclient.CoreV1().Namespaces()
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return namespaces.List(ctx, opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return namespaces.Watch(ctx, opts)
+		},
+	}, &corev1.Namespace{}, 0, cache.Indexers{})
+	s.lister = corelisters.NewNamespaceLister(informer.GetIndexer())
+	s.hasSynced = informer.HasSynced
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { s.handle(ctx, obj) },
+		UpdateFunc: func(_, obj interface{}) { s.handle(ctx, obj) },
+	})
+	go informer.Run(ctx.Done())
+}
+
+func (s *sinkBindingNamespaces) handle(ctx context.Context, obj interface{}) {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	selection := s.selection
+	s.mu.Unlock()
+	if selection == nil {
+		return
+	}
+
+	if err := s.sync(ctx, *selection, ns.DeepCopy()); err != nil {
+		logging.FromContext(ctx).Errorw("Failed to sync SinkBinding include label", "namespace", ns.Name, "error", err)
+	}
+}
+
+// sync adds or removes the include label on the given namespace, depending on whether
+// it's selected. Conflicting updates are retried with the latest namespace.
+func (s *sinkBindingNamespaces) sync(ctx context.Context, selection namespaceSelection, ns *corev1.Namespace) error {
+	namespaces := s.kubeclient.CoreV1().Namespaces()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !relabel(selection, ns) {
+			return nil
+		}
+		_, err := namespaces.Update(ctx, ns, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			latest, getErr := namespaces.Get(ctx, ns.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			ns = latest
+		}
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not update label %q on namespace %q: %w", duck.BindingIncludeLabel, ns.Name, err)
+	}
+	return nil
+}
+
+// relabel adds or removes the include label on the given namespace, depending on
+// whether it's selected. It returns false if the namespace doesn't need to be updated.
+func relabel(selection namespaceSelection, ns *corev1.Namespace) bool {
+	if ns.Status.Phase == corev1.NamespaceTerminating {
+		return false
+	}
+
+	managed := ns.Annotations[sinkBindingManagedAnnotation] == "true"
+	_, labeled := ns.Labels[duck.BindingIncludeLabel]
+	switch want := selection.matches(ns); {
+	case want && !labeled:
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string, 1)
+		}
+		if ns.Annotations == nil {
+			ns.Annotations = make(map[string]string, 1)
+		}
+		ns.Labels[duck.BindingIncludeLabel] = "true"
+		ns.Annotations[sinkBindingManagedAnnotation] = "true"
+		return true
+	case !want && managed:
+		delete(ns.Labels, duck.BindingIncludeLabel)
+		delete(ns.Annotations, sinkBindingManagedAnnotation)
+		return true
+	default:
+		return false
+	}
+}
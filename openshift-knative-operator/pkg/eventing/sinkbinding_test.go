@@ -0,0 +1,205 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestSinkBindingNamespaces(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     string
+		spec     *SinkBindingSelection
+		in       []runtime.Object
+		expected map[string]map[string]string
+	}{{
+		name: "selected by name",
+		mode: "inclusion",
+		spec: &SinkBindingSelection{Namespaces: []string{"foo", "bar"}},
+		in:   []runtime.Object{namespace("foo", nil, nil), namespace("bar", nil, nil), namespace("baz", nil, nil)},
+		expected: map[string]map[string]string{
+			"foo": {"bindings.knative.dev/include": "true"},
+			"bar": {"bindings.knative.dev/include": "true"},
+			"baz": nil,
+		},
+	}, {
+		name: "selected by label",
+		mode: "inclusion",
+		spec: &SinkBindingSelection{NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"team": "a"},
+		}},
+		in: []runtime.Object{
+			namespace("foo", map[string]string{"team": "a"}, nil),
+			namespace("bar", map[string]string{"team": "b"}, nil),
+		},
+		expected: map[string]map[string]string{
+			"foo": {"team": "a", "bindings.knative.dev/include": "true"},
+			"bar": {"team": "b"},
+		},
+	}, {
+		name: "no longer selected",
+		mode: "inclusion",
+		spec: &SinkBindingSelection{Namespaces: []string{"bar"}},
+		in: []runtime.Object{
+			namespace("foo", map[string]string{"bindings.knative.dev/include": "true"}, map[string]string{sinkBindingManagedAnnotation: "true"}),
+		},
+		expected: map[string]map[string]string{
+			"foo": {},
+		},
+	}, {
+		name: "labeled by the user",
+		mode: "inclusion",
+		in: []runtime.Object{
+			namespace("foo", map[string]string{"bindings.knative.dev/include": "true"}, nil),
+		},
+		expected: map[string]map[string]string{
+			"foo": {"bindings.knative.dev/include": "true"},
+		},
+	}, {
+		name: "exclusion mode",
+		mode: "exclusion",
+		spec: &SinkBindingSelection{Namespaces: []string{"foo"}},
+		in: []runtime.Object{
+			namespace("foo", map[string]string{"bindings.knative.dev/include": "true"}, map[string]string{sinkBindingManagedAnnotation: "true"}),
+		},
+		expected: map[string]map[string]string{
+			"foo": {},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			kube := fake.NewSimpleClientset(c.in...)
+			ke := &v1alpha1.KnativeEventing{
+				Spec: v1alpha1.KnativeEventingSpec{
+					SinkBindingSelectionMode: c.mode,
+				},
+			}
+
+			selection, err := sinkBindingSelectionFromSpec(ke, SpecExtension{SinkBinding: c.spec})
+			if err != nil {
+				t.Fatal("Failed to read selection", err)
+			}
+			s := &sinkBindingNamespaces{kubeclient: kube}
+			s.Watch(ctx)
+			if err := s.apply(ctx, selection); err != nil {
+				t.Fatal("Failed to apply selection", err)
+			}
+
+			for name, want := range c.expected {
+				ns, err := kube.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Failed to get namespace %q: %v", name, err)
+				}
+				if !cmp.Equal(ns.Labels, want) {
+					t.Errorf("Namespace %q labels = %v, want: %v", name, ns.Labels, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSinkBindingNamespacesNewNamespace(t *testing.T) {
+	ctx := context.Background()
+	kube := fake.NewSimpleClientset()
+	s := &sinkBindingNamespaces{kubeclient: kube}
+
+	ns := namespace("foo", map[string]string{"team": "a"}, nil)
+	if _, err := kube.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create namespace", err)
+	}
+
+	// Events before the first reconcile are ignored.
+	s.handle(ctx, ns)
+	if got := getNamespace(ctx, t, kube, "foo"); got.Labels["bindings.knative.dev/include"] != "" {
+		t.Errorf("Namespace labeled before the selection is known: %v", got.Labels)
+	}
+
+	selection, err := sinkBindingSelectionFromSpec(&v1alpha1.KnativeEventing{
+		Spec: v1alpha1.KnativeEventingSpec{
+			SinkBindingSelectionMode: "inclusion",
+		},
+	}, SpecExtension{SinkBinding: &SinkBindingSelection{NamespaceSelector: &metav1.LabelSelector{
+		MatchLabels: map[string]string{"team": "a"},
+	}}})
+	if err != nil {
+		t.Fatal("Failed to read selection", err)
+	}
+	s.selection = &selection
+
+	s.handle(ctx, ns)
+	if got := getNamespace(ctx, t, kube, "foo"); got.Labels["bindings.knative.dev/include"] != "true" {
+		t.Errorf("Namespace not labeled after it has been added: %v", got.Labels)
+	}
+}
+
+func TestSinkBindingNamespacesConflict(t *testing.T) {
+	ctx := context.Background()
+	kube := fake.NewSimpleClientset(namespace("foo", nil, nil))
+	s := &sinkBindingNamespaces{kubeclient: kube}
+
+	// Fail the first update as if the namespace had changed in the meantime.
+	conflicted := false
+	kube.PrependReactor("update", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if conflicted {
+			return false, nil, nil
+		}
+		conflicted = true
+		return true, nil, apierrors.NewConflict(corev1.Resource("namespaces"), "foo", errors.New("changed"))
+	})
+
+	selection := namespaceSelection{names: sets.NewString("foo"), selector: labels.Nothing()}
+	if err := s.sync(ctx, selection, namespace("foo", nil, nil)); err != nil {
+		t.Fatal("Failed to sync namespace", err)
+	}
+	if got := getNamespace(ctx, t, kube, "foo"); got.Labels["bindings.knative.dev/include"] != "true" {
+		t.Errorf("Namespace not labeled after a conflict: %v", got.Labels)
+	}
+}
+
+func TestSinkBindingInvalidSelector(t *testing.T) {
+	ke := &v1alpha1.KnativeEventing{
+		Spec: v1alpha1.KnativeEventingSpec{
+			SinkBindingSelectionMode: "inclusion",
+		},
+	}
+	ext := SpecExtension{SinkBinding: &SinkBindingSelection{NamespaceSelector: &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Foo"}},
+	}}}
+	if _, err := sinkBindingSelectionFromSpec(ke, ext); err == nil {
+		t.Error("Expected an error for an invalid selector")
+	}
+}
+
+func namespace(name string, labels, annotations map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+}
+
+func getNamespace(ctx context.Context, t *testing.T, kube *fake.Clientset, name string) *corev1.Namespace {
+	t.Helper()
+	ns, err := kube.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get namespace %q: %v", name, err)
+	}
+	return ns
+}
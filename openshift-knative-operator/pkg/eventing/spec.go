@@ -0,0 +1,25 @@
+package eventing
+
+import (
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var knativeEventingGVR = schema.GroupVersionResource{
+	Group:    "operator.knative.dev",
+	Version:  "v1alpha1",
+	Resource: "knativeeventings",
+}
+
+// SpecExtension are the settings in spec.openshift of KnativeEventing. They're not part
+// of the upstream type, so they are read from the unstructured resource.
+type SpecExtension struct {
+	// DefaultChannel is the kind of the cluster wide default channel. Empty means the
+	// manifest's default.
+	DefaultChannel string `json:"defaultChannel,omitempty"`
+	// SinkBinding selects the namespaces that are opted into SinkBinding if the selection
+	// mode is inclusion.
+	SinkBinding *SinkBindingSelection `json:"sinkBinding,omitempty"`
+	// Monitoring configures the monitoring of the Eventing components.
+	Monitoring monitoring.Spec `json:"monitoring,omitempty"`
+}
@@ -0,0 +1,284 @@
+package monitoring
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	mf "github.com/manifestival/manifestival"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+// alert is the template of an alerting rule. The expression is a text/template rendered
+// with the install namespace, the alert's threshold and the jobs of the scraped components.
+type alert struct {
+	name string
+	// component is the deployment whose metrics the alert is based on. The alert is only
+	// rendered if the component's metrics are scraped. Alerts without a component are
+	// rendered whenever any metrics are scraped.
+	component string
+	// exporter is the external exporter providing the alert's metric. The operator can't
+	// tell whether it's deployed, so such alerts are only rendered if they're explicitly
+	// enabled by setting disabled to false.
+	exporter  string
+	expr      string
+	threshold string
+	duration  string
+	severity  string
+	summary   string
+}
+
+// alertData is passed to the templates of the alert expressions.
+type alertData struct {
+	Namespace string
+	Threshold string
+	Jobs      string
+}
+
+var servingAlerts = []alert{{
+	name:     "KnativeServingDown",
+	expr:     `knative_up{type="serving_status"} == 0`,
+	duration: "10m",
+	severity: "critical",
+	summary:  "Knative Serving is not ready.",
+}, {
+	name:     "KnativeServingComponentDown",
+	expr:     `up{namespace="{{.Namespace}}", job=~"{{.Jobs}}"} == 0`,
+	duration: "5m",
+	severity: "critical",
+	summary:  "A Knative Serving component in {{.Namespace}} can't be scraped.",
+}, {
+	name:      "KnativeServingWebhookLatencyHigh",
+	component: "webhook",
+	expr:      `histogram_quantile(0.99, sum by (le) (rate(webhook_request_latencies_bucket{namespace="{{.Namespace}}"}[5m]))) > {{.Threshold}}`,
+	threshold: "1000",
+	duration:  "10m",
+	severity:  "warning",
+	summary:   "The 99th percentile of the Knative Serving webhook latency is above {{.Threshold}}ms.",
+}, {
+	// The webhook doesn't report response codes, so this counts denied requests, which are
+	// mostly legitimate rejections of invalid resources. It's informational only.
+	name:      "KnativeServingWebhookDenialsHigh",
+	component: "webhook",
+	expr:      `sum(rate(webhook_request_count{namespace="{{.Namespace}}", admission_allowed="false"}[5m])) / sum(rate(webhook_request_count{namespace="{{.Namespace}}"}[5m])) > {{.Threshold}}`,
+	threshold: "0.1",
+	duration:  "10m",
+	severity:  "info",
+	summary:   "More than {{.Threshold}} of the requests to the Knative Serving webhook are denied.",
+}, {
+	name:      "KnativeServingActivatorRequestErrorsHigh",
+	component: "activator",
+	expr:      `sum(rate(activator_request_count{namespace="{{.Namespace}}", response_code_class="5xx"}[5m])) / sum(rate(activator_request_count{namespace="{{.Namespace}}"}[5m])) > {{.Threshold}}`,
+	threshold: "0.05",
+	duration:  "10m",
+	severity:  "warning",
+	summary:   "More than {{.Threshold}} of the requests proxied by the activator fail with 5xx.",
+}, {
+	name:      "KnativeServingAutoscalerPanicModeStuck",
+	component: "autoscaler",
+	expr:      `max by (namespace_name, configuration_name, revision_name) (autoscaler_panic_mode{namespace="{{.Namespace}}"}) == 1`,
+	duration:  "15m",
+	severity:  "warning",
+	summary:   "A revision has been in panic mode for a long time.",
+}}
+
+var eventingAlerts = []alert{{
+	name:     "KnativeEventingDown",
+	expr:     `knative_up{type="eventing_status"} == 0`,
+	duration: "10m",
+	severity: "critical",
+	summary:  "Knative Eventing is not ready.",
+}, {
+	name:     "KnativeEventingComponentDown",
+	expr:     `up{namespace="{{.Namespace}}", job=~"{{.Jobs}}"} == 0`,
+	duration: "5m",
+	severity: "critical",
+	summary:  "A Knative Eventing component in {{.Namespace}} can't be scraped.",
+}, {
+	name:      "KnativeEventingWebhookLatencyHigh",
+	component: "eventing-webhook",
+	expr:      `histogram_quantile(0.99, sum by (le) (rate(eventing_webhook_request_latencies_bucket{namespace="{{.Namespace}}"}[5m]))) > {{.Threshold}}`,
+	threshold: "1000",
+	duration:  "10m",
+	severity:  "warning",
+	summary:   "The 99th percentile of the Knative Eventing webhook latency is above {{.Threshold}}ms.",
+}, {
+	// The webhook doesn't report response codes, so this counts denied requests, which are
+	// mostly legitimate rejections of invalid resources. It's informational only.
+	name:      "KnativeEventingWebhookDenialsHigh",
+	component: "eventing-webhook",
+	expr:      `sum(rate(eventing_webhook_request_count{namespace="{{.Namespace}}", admission_allowed="false"}[5m])) / sum(rate(eventing_webhook_request_count{namespace="{{.Namespace}}"}[5m])) > {{.Threshold}}`,
+	threshold: "0.1",
+	duration:  "10m",
+	severity:  "info",
+	summary:   "More than {{.Threshold}} of the requests to the Knative Eventing webhook are denied.",
+}, {
+	name:      "KnativeEventingBrokerIngressErrorsHigh",
+	component: "mt-broker-ingress",
+	expr:      `sum(rate(mt_broker_ingress_event_count{namespace="{{.Namespace}}", response_code_class="5xx"}[5m])) / sum(rate(mt_broker_ingress_event_count{namespace="{{.Namespace}}"}[5m])) > {{.Threshold}}`,
+	threshold: "0.05",
+	duration:  "10m",
+	severity:  "warning",
+	summary:   "More than {{.Threshold}} of the events sent to brokers are rejected with 5xx.",
+}}
+
+var kafkaAlerts = []alert{{
+	name:     "KnativeKafkaDown",
+	expr:     `knative_up{type="kafka_status"} == 0`,
+	duration: "10m",
+	severity: "critical",
+	summary:  "Knative Kafka is not ready.",
+}, {
+	name:     "KnativeKafkaComponentDown",
+	expr:     `up{namespace="{{.Namespace}}", job=~"{{.Jobs}}"} == 0`,
+	duration: "5m",
+	severity: "critical",
+	summary:  "A Knative Kafka component in {{.Namespace}} can't be scraped.",
+}, {
+	// The dispatcher doesn't report the lag of its consumer groups, it's exported by the
+	// Kafka cluster's exporter instead, for example the one deployed by Strimzi.
+	name:      "KnativeKafkaDispatcherConsumerLagHigh",
+	component: "kafka-ch-dispatcher",
+	exporter:  "Kafka exporter",
+	expr:      `sum by (consumergroup, topic) (kafka_consumergroup_lag{consumergroup=~"kafka\\..*"}) > {{.Threshold}}`,
+	threshold: "1000",
+	duration:  "10m",
+	severity:  "warning",
+	summary:   "A KafkaChannel subscription lags more than {{.Threshold}} messages behind.",
+}}
+
+// validateAlertSettings validates the settings tuning the alerts.
+func validateAlertSettings(spec Spec) error {
+	names := make([]string, 0, len(spec.Alerts))
+	for name := range spec.Alerts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if duration := spec.Alerts[name].For; duration != "" {
+			if _, err := model.ParseDuration(duration); err != nil {
+				return fmt.Errorf("invalid spec.%s.monitoring.alerts.%s.for %q: %w", common.SpecExtensionField, name, duration, err)
+			}
+		}
+	}
+	return nil
+}
+
+// tune returns the alert with the given settings applied and whether it's enabled.
+func (a alert) tune(settings Alert) (alert, bool) {
+	enabled := a.exporter == ""
+	if settings.Disabled != nil {
+		enabled = !*settings.Disabled
+	}
+	if settings.Threshold != nil {
+		a.threshold = strconv.FormatFloat(*settings.Threshold, 'f', -1, 64)
+	}
+	if settings.For != "" {
+		a.duration = settings.For
+	}
+	if settings.Severity != "" {
+		a.severity = settings.Severity
+	}
+	return a, enabled
+}
+
+// alertRulesManifest returns a PrometheusRule with the given alerts. Following the rbac-proxy,
+// the rule is kept around without any alerts when no metrics are scraped.
+func alertRulesManifest(name, ns string, config v1alpha1.ConfigMapData, spec Spec, components Components, alerts []alert) (mf.Manifest, error) {
+	if err := validateAlertSettings(spec); err != nil {
+		return mf.Manifest{}, err
+	}
+
+	rule := &monitoringv1.PrometheusRule{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: monitoringv1.SchemeGroupVersion.String(),
+			Kind:       monitoringv1.PrometheusRuleKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-alerts",
+			Namespace: ns,
+		},
+	}
+
+	scraped := ScrapedDeployments(config, spec, components.Names())
+	if scraped.Len() > 0 {
+		jobs := make([]string, 0, scraped.Len())
+		for _, c := range scraped.List() {
+			jobs = append(jobs, serviceMonitorServiceName(c))
+		}
+
+		var rules []monitoringv1.Rule
+		for _, a := range alerts {
+			a, enabled := a.tune(spec.Alerts[a.name])
+			if !enabled || (a.component != "" && !scraped.Has(a.component)) {
+				continue
+			}
+			r, err := a.render(alertData{
+				Namespace: ns,
+				Threshold: a.threshold,
+				Jobs:      strings.Join(jobs, "|"),
+			})
+			if err != nil {
+				return mf.Manifest{}, err
+			}
+			rules = append(rules, r)
+		}
+		rule.Spec.Groups = []monitoringv1.RuleGroup{{
+			Name:  name + ".rules",
+			Rules: rules,
+		}}
+	}
+
+	u := unstructured.Unstructured{}
+	if err := scheme.Scheme.Convert(rule, &u, nil); err != nil {
+		return mf.Manifest{}, err
+	}
+	return mf.ManifestFrom(mf.Slice([]unstructured.Unstructured{u}))
+}
+
+// KafkaAlertRulesManifest returns the PrometheusRule with the alerts of the Kafka components,
+// tuned and enabled through the monitoring settings of Knative Eventing.
+func KafkaAlertRulesManifest(ns string, config v1alpha1.ConfigMapData, spec Spec, components Components) (mf.Manifest, error) {
+	return alertRulesManifest("knative-kafka", ns, config, spec, components, kafkaAlerts)
+}
+
+// render renders the alert's expression and summary with the given data.
+func (a alert) render(data alertData) (monitoringv1.Rule, error) {
+	expr, err := renderTemplate(a.name, a.expr, data)
+	if err != nil {
+		return monitoringv1.Rule{}, err
+	}
+	summary, err := renderTemplate(a.name, a.summary, data)
+	if err != nil {
+		return monitoringv1.Rule{}, err
+	}
+	return monitoringv1.Rule{
+		Alert:       a.name,
+		Expr:        intstr.FromString(expr),
+		For:         a.duration,
+		Labels:      map[string]string{"severity": a.severity},
+		Annotations: map[string]string{"summary": summary},
+	}, nil
+}
+
+func renderTemplate(name, text string, data alertData) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the template of alert %q: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render alert %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,198 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/ptr"
+)
+
+var servingComponents = Components{
+	"activator":  {Name: "activator", MetricsPort: "9090"},
+	"autoscaler": {Name: "autoscaler", MetricsPort: "9090"},
+	"webhook":    {Name: "webhook", MetricsPort: "9090"},
+}
+
+func TestAlertRules(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   v1alpha1.ConfigMapData
+		spec     Spec
+		expected []monitoringv1.Rule
+	}{{
+		name: "default",
+		expected: []monitoringv1.Rule{
+			rule("KnativeServingDown", `knative_up{type="serving_status"} == 0`, "10m", "critical",
+				"Knative Serving is not ready."),
+			rule("KnativeServingComponentDown",
+				`up{namespace="knative-serving", job=~"activator-sm-service|autoscaler-sm-service|webhook-sm-service"} == 0`,
+				"5m", "critical", "A Knative Serving component in knative-serving can't be scraped."),
+			rule("KnativeServingWebhookLatencyHigh",
+				`histogram_quantile(0.99, sum by (le) (rate(webhook_request_latencies_bucket{namespace="knative-serving"}[5m]))) > 1000`,
+				"10m", "warning", "The 99th percentile of the Knative Serving webhook latency is above 1000ms."),
+			rule("KnativeServingWebhookDenialsHigh",
+				`sum(rate(webhook_request_count{namespace="knative-serving", admission_allowed="false"}[5m])) / sum(rate(webhook_request_count{namespace="knative-serving"}[5m])) > 0.1`,
+				"10m", "info", "More than 0.1 of the requests to the Knative Serving webhook are denied."),
+			rule("KnativeServingActivatorRequestErrorsHigh",
+				`sum(rate(activator_request_count{namespace="knative-serving", response_code_class="5xx"}[5m])) / sum(rate(activator_request_count{namespace="knative-serving"}[5m])) > 0.05`,
+				"10m", "warning", "More than 0.05 of the requests proxied by the activator fail with 5xx."),
+			rule("KnativeServingAutoscalerPanicModeStuck",
+				`max by (namespace_name, configuration_name, revision_name) (autoscaler_panic_mode{namespace="knative-serving"}) == 1`,
+				"15m", "warning", "A revision has been in panic mode for a long time."),
+		},
+	}, {
+		name: "tuned",
+		spec: Spec{
+			Metrics: Metrics{DeploymentBackends: map[string]string{
+				"webhook":    BackendNone,
+				"autoscaler": BackendNone,
+			}},
+			Alerts: map[string]Alert{
+				"KnativeServingDown": {Disabled: ptr.Bool(true)},
+				"KnativeServingActivatorRequestErrorsHigh": {
+					Threshold: ptr.Float64(0.2),
+					For:       "1h",
+					Severity:  "critical",
+				},
+			},
+		},
+		expected: []monitoringv1.Rule{
+			rule("KnativeServingComponentDown",
+				`up{namespace="knative-serving", job=~"activator-sm-service"} == 0`,
+				"5m", "critical", "A Knative Serving component in knative-serving can't be scraped."),
+			rule("KnativeServingActivatorRequestErrorsHigh",
+				`sum(rate(activator_request_count{namespace="knative-serving", response_code_class="5xx"}[5m])) / sum(rate(activator_request_count{namespace="knative-serving"}[5m])) > 0.2`,
+				"1h", "critical", "More than 0.2 of the requests proxied by the activator fail with 5xx."),
+		},
+	}, {
+		name: "disabled",
+		spec: Spec{Metrics: Metrics{
+			Backend:   BackendOpenCensus,
+			Collector: Collector{Address: "otel-collector.observability:55678"},
+		}},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			manifest, err := alertRulesManifest("knative-serving", servingNamespace, c.config, c.spec, servingComponents, servingAlerts)
+			if err != nil {
+				t.Fatal("alertRulesManifest() =", err)
+			}
+			if len(manifest.Resources()) != 1 {
+				t.Fatalf("Got %d resources, want 1", len(manifest.Resources()))
+			}
+			pr := &monitoringv1.PrometheusRule{}
+			if err := scheme.Scheme.Convert(&manifest.Resources()[0], pr, nil); err != nil {
+				t.Fatal("Unable to convert to PrometheusRule", err)
+			}
+			if pr.Name != "knative-serving-alerts" || pr.Namespace != servingNamespace {
+				t.Errorf("Got PrometheusRule %s/%s, want %s/knative-serving-alerts", pr.Namespace, pr.Name, servingNamespace)
+			}
+
+			if c.expected == nil {
+				if len(pr.Spec.Groups) != 0 {
+					t.Errorf("Got groups %v, want none", pr.Spec.Groups)
+				}
+				return
+			}
+			if len(pr.Spec.Groups) != 1 || pr.Spec.Groups[0].Name != "knative-serving.rules" {
+				t.Fatalf("Got groups %v, want a single group knative-serving.rules", pr.Spec.Groups)
+			}
+			if !cmp.Equal(pr.Spec.Groups[0].Rules, c.expected) {
+				t.Errorf("Got = %v, want: %v, diff: %s", pr.Spec.Groups[0].Rules, c.expected,
+					cmp.Diff(pr.Spec.Groups[0].Rules, c.expected))
+			}
+		})
+	}
+}
+
+func TestKafkaAlertRules(t *testing.T) {
+	components := Components{
+		"kafka-ch-dispatcher": {Name: "kafka-ch-dispatcher", MetricsPort: "9090"},
+	}
+	lag := rule("KnativeKafkaDispatcherConsumerLagHigh",
+		`sum by (consumergroup, topic) (kafka_consumergroup_lag{consumergroup=~"kafka\\..*"}) > 1000`,
+		"10m", "warning", "A KafkaChannel subscription lags more than 1000 messages behind.")
+
+	cases := []struct {
+		name string
+		spec Spec
+		want []string
+	}{{
+		name: "default",
+		want: []string{"KnativeKafkaDown", "KnativeKafkaComponentDown"},
+	}, {
+		// The consumer lag needs the Kafka exporter, which must be declared by the user.
+		name: "consumer lag enabled",
+		spec: Spec{Alerts: map[string]Alert{
+			"KnativeKafkaDispatcherConsumerLagHigh": {Disabled: ptr.Bool(false)},
+		}},
+		want: []string{"KnativeKafkaDown", "KnativeKafkaComponentDown", lag.Alert},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			manifest, err := KafkaAlertRulesManifest(eventingNamespace, nil, c.spec, components)
+			if err != nil {
+				t.Fatal("KafkaAlertRulesManifest() =", err)
+			}
+			pr := &monitoringv1.PrometheusRule{}
+			if err := scheme.Scheme.Convert(&manifest.Resources()[0], pr, nil); err != nil {
+				t.Fatal("Unable to convert to PrometheusRule", err)
+			}
+
+			rules := pr.Spec.Groups[0].Rules
+			got := make([]string, 0, len(rules))
+			for _, r := range rules {
+				got = append(got, r.Alert)
+				if r.Alert == lag.Alert && !cmp.Equal(r, lag) {
+					t.Errorf("Got = %v, want: %v", r, lag)
+				}
+			}
+			if !cmp.Equal(got, c.want) {
+				t.Errorf("Got alerts %v, want: %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidateAlertSettings(t *testing.T) {
+	cases := []struct {
+		name    string
+		alert   Alert
+		wantErr bool
+	}{{
+		name:  "threshold",
+		alert: Alert{Threshold: ptr.Float64(0.5)},
+	}, {
+		name:  "duration",
+		alert: Alert{For: "1h30m"},
+	}, {
+		name:    "invalid duration",
+		alert:   Alert{For: "an hour"},
+		wantErr: true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spec := Spec{Alerts: map[string]Alert{"KnativeServingDown": c.alert}}
+			if err := validateAlertSettings(spec); (err != nil) != c.wantErr {
+				t.Errorf("validateAlertSettings() = %v, wantErr: %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func rule(name, expr, duration, severity, summary string) monitoringv1.Rule {
+	return monitoringv1.Rule{
+		Alert:       name,
+		Expr:        intstr.FromString(expr),
+		For:         duration,
+		Labels:      map[string]string{"severity": severity},
+		Annotations: map[string]string{"summary": summary},
+	}
+}
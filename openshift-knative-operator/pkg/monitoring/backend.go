@@ -0,0 +1,202 @@
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	mf "github.com/manifestival/manifestival"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+const (
+	// BackendPrometheus exposes the metrics to be scraped through the rbac-proxy.
+	BackendPrometheus = "prometheus"
+	// BackendOpenCensus pushes the metrics to the collector.
+	BackendOpenCensus = "opencensus"
+	// BackendNone disables metrics.
+	BackendNone = "none"
+
+	requestMetricsBackendKey = "metrics.request-metrics-backend-destination"
+	collectorAddressKey      = "metrics.opencensus-address"
+	collectorRequireTLSKey   = "metrics.opencensus-require-tls"
+
+	// observabilityNameEnvVar points a component to the observability ConfigMap to use.
+	observabilityNameEnvVar = "CONFIG_OBSERVABILITY_NAME"
+)
+
+var backends = sets.NewString(BackendPrometheus, BackendOpenCensus, BackendNone)
+
+// metricsBackend returns the metrics backend configured for all components.
+func metricsBackend(config v1alpha1.ConfigMapData, spec Spec) string {
+	if backend := spec.Metrics.Backend; backend != "" {
+		return backend
+	}
+	return config[ObservabilityCMName][ObservabilityBackendKey]
+}
+
+// metricsBackendOverrides returns the metrics backends of the deployments that override
+// the backend of all components.
+func metricsBackendOverrides(spec Spec) map[string]string {
+	overrides := map[string]string{}
+	for deployment, backend := range spec.Metrics.DeploymentBackends {
+		if backend != "" {
+			overrides[deployment] = backend
+		}
+	}
+	return overrides
+}
+
+// ScrapedDeployments returns those of the given deployments whose metrics are scraped by
+// Prometheus through the rbac-proxy.
+func ScrapedDeployments(config v1alpha1.ConfigMapData, spec Spec, deployments sets.String) sets.String {
+	enabled := ShouldEnableMonitoring(config, spec)
+	overrides := metricsBackendOverrides(spec)
+
+	scraped := sets.NewString()
+	for deployment := range deployments {
+		backend, overridden := overrides[deployment]
+		if backend == BackendPrometheus || (!overridden && enabled) {
+			scraped.Insert(deployment)
+		}
+	}
+	return scraped
+}
+
+// scrapesAny returns true if the metrics of any deployment are scraped by Prometheus.
+func scrapesAny(config v1alpha1.ConfigMapData, spec Spec) bool {
+	if ShouldEnableMonitoring(config, spec) {
+		return true
+	}
+	for _, backend := range metricsBackendOverrides(spec) {
+		if backend == BackendPrometheus {
+			return true
+		}
+	}
+	return false
+}
+
+// configureMetricsBackend validates the typed metrics settings and renders them into the
+// observability ConfigMap.
+func configureMetricsBackend(commonSpec *v1alpha1.CommonSpec, spec Spec) error {
+	config := commonSpec.GetConfig()
+	metrics := spec.Metrics
+
+	fields := map[string]string{
+		"monitoring.metrics.backend":        metrics.Backend,
+		"monitoring.metrics.requestBackend": metrics.RequestBackend,
+	}
+	for deployment, backend := range metrics.DeploymentBackends {
+		fields["monitoring.metrics.deploymentBackends."+deployment] = backend
+	}
+	for _, field := range sets.StringKeySet(fields).List() {
+		if backend := fields[field]; backend != "" && !backends.Has(backend) {
+			return fmt.Errorf("invalid metrics backend %q for spec.%s.%s, must be one of %s",
+				backend, common.SpecExtensionField, field, strings.Join(backends.List(), ", "))
+		}
+	}
+
+	pushed := metricsBackend(config, spec) == BackendOpenCensus || metrics.RequestBackend == BackendOpenCensus
+	for _, backend := range metricsBackendOverrides(spec) {
+		pushed = pushed || backend == BackendOpenCensus
+	}
+	if pushed && metrics.Collector.Address == "" && config[ObservabilityCMName][collectorAddressKey] == "" {
+		return fmt.Errorf("spec.%s.monitoring.metrics.collector.address must be set to push metrics to a collector", common.SpecExtensionField)
+	}
+
+	if backend := metrics.Backend; backend != "" {
+		common.Configure(commonSpec, ObservabilityCMName, ObservabilityBackendKey, backend)
+	}
+	if backend := metrics.RequestBackend; backend != "" {
+		common.Configure(commonSpec, ObservabilityCMName, requestMetricsBackendKey, backend)
+	}
+	if address := metrics.Collector.Address; address != "" {
+		common.Configure(commonSpec, ObservabilityCMName, collectorAddressKey, address)
+	}
+	if tls := metrics.Collector.RequireTLS; tls != nil {
+		common.Configure(commonSpec, ObservabilityCMName, collectorRequireTLSKey, strconv.FormatBool(*tls))
+	}
+	return nil
+}
+
+// observabilityOverrideName is the name of the observability ConfigMap of a deployment
+// overriding the metrics backend.
+func observabilityOverrideName(deployment string) string {
+	return fmt.Sprintf("config-%s-%s", ObservabilityCMName, deployment)
+}
+
+// ObservabilityOverridesManifest returns a copy of the observability ConfigMap for each of
+// the given deployments that overrides the metrics backend, with the backend replaced.
+func ObservabilityOverridesManifest(comp v1alpha1.KComponent, spec Spec, deployments sets.String) (mf.Manifest, error) {
+	config := comp.GetSpec().GetConfig()
+	overrides := metricsBackendOverrides(spec)
+
+	names := make([]string, 0, len(overrides))
+	for deployment := range overrides {
+		if deployments.Has(deployment) {
+			names = append(names, deployment)
+		}
+	}
+	sort.Strings(names)
+
+	resources := make([]unstructured.Unstructured, 0, len(names))
+	for _, deployment := range names {
+		data := make(map[string]string, len(config[ObservabilityCMName])+1)
+		for key, value := range config[ObservabilityCMName] {
+			data[key] = value
+		}
+		data[ObservabilityBackendKey] = overrides[deployment]
+
+		cm := &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      observabilityOverrideName(deployment),
+				Namespace: comp.GetNamespace(),
+			},
+			Data: data,
+		}
+		u := unstructured.Unstructured{}
+		if err := scheme.Scheme.Convert(cm, &u, nil); err != nil {
+			return mf.Manifest{}, err
+		}
+		resources = append(resources, u)
+	}
+	return mf.ManifestFrom(mf.Slice(resources))
+}
+
+// InjectObservabilityOverrides points the given components that override the metrics
+// backend to their own observability ConfigMap.
+func InjectObservabilityOverrides(spec Spec, components Components) mf.Transformer {
+	overrides := metricsBackendOverrides(spec)
+	return func(u *unstructured.Unstructured) error {
+		component, ok := components[u.GetName()]
+		if u.GetKind() != "Deployment" || !ok {
+			return nil
+		}
+		if _, ok := overrides[u.GetName()]; !ok {
+			return nil
+		}
+
+		dep := &appsv1.Deployment{}
+		if err := scheme.Scheme.Convert(u, dep, nil); err != nil {
+			return err
+		}
+		container, err := component.container(dep)
+		if err != nil {
+			return err
+		}
+		container.Env = upsertEnv(container.Env, corev1.EnvVar{
+			Name:  observabilityNameEnvVar,
+			Value: observabilityOverrideName(u.GetName()),
+		})
+		return scheme.Scheme.Convert(dep, u, nil)
+	}
+}
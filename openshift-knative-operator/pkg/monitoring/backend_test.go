@@ -0,0 +1,213 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mf "github.com/manifestival/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestConfigureMetricsBackend(t *testing.T) {
+	requireTLS := true
+
+	cases := []struct {
+		name     string
+		config   v1alpha1.ConfigMapData
+		spec     Spec
+		expected map[string]string
+		wantErr  bool
+	}{{
+		name:     "typed backend",
+		spec:     Spec{Metrics: Metrics{Backend: BackendPrometheus}},
+		expected: map[string]string{ObservabilityBackendKey: BackendPrometheus},
+	}, {
+		name: "push to collector",
+		spec: Spec{Metrics: Metrics{
+			Backend: BackendOpenCensus,
+			Collector: Collector{
+				Address:    "otel-collector.observability:55678",
+				RequireTLS: &requireTLS,
+			},
+		}},
+		expected: map[string]string{
+			ObservabilityBackendKey: BackendOpenCensus,
+			collectorAddressKey:     "otel-collector.observability:55678",
+			collectorRequireTLSKey:  "true",
+		},
+	}, {
+		name: "scrape and push request metrics",
+		spec: Spec{Metrics: Metrics{
+			Backend:        BackendPrometheus,
+			RequestBackend: BackendOpenCensus,
+			Collector:      Collector{Address: "otel-collector.observability:55678"},
+		}},
+		expected: map[string]string{
+			ObservabilityBackendKey:  BackendPrometheus,
+			requestMetricsBackendKey: BackendOpenCensus,
+			collectorAddressKey:      "otel-collector.observability:55678",
+		},
+	}, {
+		name:     "collector address from the observability config",
+		config:   v1alpha1.ConfigMapData{ObservabilityCMName: {collectorAddressKey: "otel-collector.observability:55678"}},
+		spec:     Spec{Metrics: Metrics{DeploymentBackends: map[string]string{"activator": BackendOpenCensus}}},
+		expected: map[string]string{collectorAddressKey: "otel-collector.observability:55678"},
+	}, {
+		name:    "push without collector",
+		spec:    Spec{Metrics: Metrics{Backend: BackendOpenCensus}},
+		wantErr: true,
+	}, {
+		name:    "invalid backend",
+		spec:    Spec{Metrics: Metrics{DeploymentBackends: map[string]string{"activator": "stackdriver"}}},
+		wantErr: true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spec := &v1alpha1.CommonSpec{Config: c.config}
+			err := configureMetricsBackend(spec, c.spec)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("configureMetricsBackend() = %v, wantErr: %v", err, c.wantErr)
+			}
+			if c.wantErr {
+				return
+			}
+			if got := spec.Config[ObservabilityCMName]; !cmp.Equal(got, c.expected) {
+				t.Errorf("Got = %v, want: %v", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestScrapedDeployments(t *testing.T) {
+	deployments := sets.NewString("activator", "autoscaler", "controller")
+
+	cases := []struct {
+		name     string
+		config   v1alpha1.ConfigMapData
+		spec     Spec
+		expected sets.String
+	}{{
+		name:     "default",
+		expected: deployments,
+	}, {
+		name:     "pushed",
+		spec:     Spec{Metrics: Metrics{Backend: BackendOpenCensus}},
+		expected: sets.NewString(),
+	}, {
+		name: "pushed from the observability config",
+		config: v1alpha1.ConfigMapData{ObservabilityCMName: {
+			ObservabilityBackendKey: BackendOpenCensus,
+		}},
+		expected: sets.NewString(),
+	}, {
+		name:     "single deployment pushed",
+		spec:     Spec{Metrics: Metrics{DeploymentBackends: map[string]string{"activator": BackendOpenCensus}}},
+		expected: sets.NewString("autoscaler", "controller"),
+	}, {
+		name: "single deployment scraped",
+		spec: Spec{Metrics: Metrics{
+			Backend:            BackendNone,
+			DeploymentBackends: map[string]string{"activator": BackendPrometheus},
+		}},
+		expected: sets.NewString("activator"),
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ScrapedDeployments(c.config, c.spec, deployments); !got.Equal(c.expected) {
+				t.Errorf("Got = %v, want: %v", got.List(), c.expected.List())
+			}
+		})
+	}
+}
+
+func TestObservabilityOverrides(t *testing.T) {
+	ks := &v1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: servingNamespace},
+		Spec: v1alpha1.KnativeServingSpec{
+			CommonSpec: v1alpha1.CommonSpec{
+				Config: v1alpha1.ConfigMapData{
+					ObservabilityCMName: {
+						ObservabilityBackendKey: BackendPrometheus,
+						collectorAddressKey:     "otel-collector.observability:55678",
+					},
+				},
+			},
+		},
+	}
+
+	spec := Spec{Metrics: Metrics{DeploymentBackends: map[string]string{
+		"activator": BackendOpenCensus,
+		"unknown":   BackendOpenCensus,
+	}}}
+
+	overrides, err := ObservabilityOverridesManifest(ks, spec, servingDeployments)
+	if err != nil {
+		t.Fatal("ObservabilityOverridesManifest() =", err)
+	}
+	if len(overrides.Resources()) != 1 {
+		t.Fatalf("Got %d resources, want 1", len(overrides.Resources()))
+	}
+	cm := &corev1.ConfigMap{}
+	if err := scheme.Scheme.Convert(&overrides.Resources()[0], cm, nil); err != nil {
+		t.Fatal("Unable to convert to ConfigMap", err)
+	}
+	if cm.Name != "config-observability-activator" || cm.Namespace != servingNamespace {
+		t.Errorf("Got ConfigMap %s/%s, want %s/config-observability-activator", cm.Namespace, cm.Name, servingNamespace)
+	}
+	want := map[string]string{
+		ObservabilityBackendKey: BackendOpenCensus,
+		collectorAddressKey:     "otel-collector.observability:55678",
+	}
+	if !cmp.Equal(cm.Data, want) {
+		t.Errorf("Got = %v, want: %v", cm.Data, want)
+	}
+
+	manifest, err := mf.NewManifest("../testdata/serving-core-deployment.yaml")
+	if err != nil {
+		t.Fatal("Unable to load test manifest", err)
+	}
+	if manifest, err = manifest.Transform(InjectObservabilityOverrides(spec, servingComponents)); err != nil {
+		t.Fatal("Unable to transform test manifest", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := scheme.Scheme.Convert(&manifest.Resources()[0], deployment, nil); err != nil {
+		t.Fatal("Unable to convert to Deployment", err)
+	}
+	if !envToString(deployment.Spec.Template.Spec.Containers[0].Env).Has(observabilityNameEnvVar + ":config-observability-activator") {
+		t.Error("Component container does not point to its observability ConfigMap")
+	}
+}
+
+func TestReconcileMonitoringKeepsOpenCensus(t *testing.T) {
+	ks := &v1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: servingNamespace},
+	}
+	spec := Spec{Metrics: Metrics{
+		Backend:   BackendOpenCensus,
+		Collector: Collector{Address: "otel-collector.observability:55678"},
+	}}
+	api := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: servingNamespace}})
+
+	if err := ReconcileMonitoringForServing(context.Background(), api, ks, spec); err != nil {
+		t.Fatal("ReconcileMonitoringForServing() =", err)
+	}
+	if got := ks.Spec.Config[ObservabilityCMName][ObservabilityBackendKey]; got != BackendOpenCensus {
+		t.Errorf("Got backend %q, want %q", got, BackendOpenCensus)
+	}
+	ns, err := api.CoreV1().Namespaces().Get(context.Background(), servingNamespace, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get namespace", err)
+	}
+	if got := ns.Labels[EnableMonitoringLabel]; got != "false" {
+		t.Errorf("Got monitoring label %q, want %q", got, "false")
+	}
+}
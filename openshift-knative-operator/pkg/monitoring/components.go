@@ -0,0 +1,224 @@
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	mf "github.com/manifestival/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	operator "knative.dev/operator/pkg/reconciler/common"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// MetricsPortAnnotation sets the port a deployment exposes its metrics on, if it
+	// doesn't declare a container port named "metrics".
+	MetricsPortAnnotation = "operator.serverless.openshift.io/metrics-port"
+	metricsPortName       = "metrics"
+
+	// ComponentsDiscovered is a Condition indicating whether the metrics of all
+	// deployments of the manifest can be scraped.
+	ComponentsDiscovered apis.ConditionType = "MonitoringComponentsDiscovered"
+)
+
+// knownMetricsPorts are the metrics ports of the deployments in the manifests shipped with
+// the operator that don't declare a port named "metrics", keyed by deployment name.
+var knownMetricsPorts = map[string]string{
+	"kafka-controller-manager": "9090",
+}
+
+// condSet has no dependents, so the condition never affects readiness.
+var condSet = apis.NewLivingConditionSet()
+
+// Component is a deployment whose metrics can be scraped through the rbac-proxy.
+type Component struct {
+	// Name is the name of the deployment.
+	Name string
+	// ServiceAccount is the service account the deployment's pods run as.
+	ServiceAccount string
+	// Selector are the labels selecting the deployment's pods.
+	Selector map[string]string
+	// MetricsPort is the port the deployment exposes its metrics on.
+	MetricsPort string
+	// Container is the name of the container exposing the metrics. The deployment's name is
+	// assumed if it's empty.
+	Container string
+}
+
+// Components are the monitored components by deployment name.
+type Components map[string]Component
+
+// Names returns the deployment names of the components.
+func (c Components) Names() sets.String {
+	names := sets.NewString()
+	for name := range c {
+		names.Insert(name)
+	}
+	return names
+}
+
+// ServiceAccounts returns the service accounts of the components.
+func (c Components) ServiceAccounts() sets.String {
+	serviceAccounts := sets.NewString()
+	for _, component := range c {
+		serviceAccounts.Insert(component.ServiceAccount)
+	}
+	return serviceAccounts
+}
+
+// Only returns the components with the given names.
+func (c Components) Only(names sets.String) Components {
+	only := make(Components, names.Len())
+	for name := range names {
+		if component, ok := c[name]; ok {
+			only[name] = component
+		}
+	}
+	return only
+}
+
+// DiscoverComponents derives the monitored components from the deployments of the given
+// resources. A deployment's metrics port is read from MetricsPortAnnotation, from the
+// container port named "metrics" or from the known ports of the shipped manifests. Deployments without a metrics port or without selector
+// labels can't be monitored and are returned as unknown.
+func DiscoverComponents(resources []unstructured.Unstructured) (Components, []string, error) {
+	components := Components{}
+	var unknown []string
+	for i := range resources {
+		u := &resources[i]
+		if u.GetKind() != "Deployment" {
+			continue
+		}
+
+		dep := &appsv1.Deployment{}
+		if err := scheme.Scheme.Convert(u, dep, nil); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert deployment %q: %w", u.GetName(), err)
+		}
+		port, container := metricsPort(dep)
+		if port == "" || dep.Spec.Selector == nil || len(dep.Spec.Selector.MatchLabels) == 0 {
+			unknown = append(unknown, dep.Name)
+			continue
+		}
+
+		serviceAccount := dep.Spec.Template.Spec.ServiceAccountName
+		if serviceAccount == "" {
+			serviceAccount = dep.Spec.Template.Spec.DeprecatedServiceAccount
+		}
+		if serviceAccount == "" {
+			serviceAccount = "default"
+		}
+		components[dep.Name] = Component{
+			Name:           dep.Name,
+			ServiceAccount: serviceAccount,
+			Selector:       dep.Spec.Selector.MatchLabels,
+			MetricsPort:    port,
+			Container:      container,
+		}
+	}
+	sort.Strings(unknown)
+	return components, unknown, nil
+}
+
+// TargetComponents discovers the monitored components of the manifest targeted by the
+// given component and reports the unknown ones on its status.
+func TargetComponents(comp v1alpha1.KComponent) (Components, error) {
+	manifest, err := operator.TargetManifest(comp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the target manifest: %w", err)
+	}
+	components, unknown, err := DiscoverComponents(manifest.Resources())
+	if err != nil {
+		return nil, err
+	}
+	MarkComponentsDiscovered(comp.GetStatus().(apis.ConditionsAccessor), unknown)
+	return components, nil
+}
+
+// MarkComponentsDiscovered reports the deployments that can't be monitored on the given
+// status. They are skipped rather than failing the installation.
+func MarkComponentsDiscovered(status apis.ConditionsAccessor, unknown []string) {
+	if len(unknown) == 0 {
+		condSet.Manage(status).SetCondition(apis.Condition{
+			Type:     ComponentsDiscovered,
+			Status:   corev1.ConditionTrue,
+			Severity: apis.ConditionSeverityInfo,
+		})
+		return
+	}
+	condSet.Manage(status).SetCondition(apis.Condition{
+		Type:     ComponentsDiscovered,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "UnknownComponents",
+		Message: fmt.Sprintf("deployments without a %q port or selector labels are not monitored: %s",
+			metricsPortName, strings.Join(unknown, ", ")),
+	})
+}
+
+// failedTransformer fails transforming any resource with the given error. It surfaces
+// errors of building transformers, which can't return errors themselves.
+func failedTransformer(err error) mf.Transformer {
+	return func(*unstructured.Unstructured) error {
+		return err
+	}
+}
+
+// metricsPort returns the port the given deployment exposes its metrics on, if any, and
+// the container exposing them. Ports from MetricsPortAnnotation or knownMetricsPorts are
+// exposed by the container named after the deployment or its first container.
+func metricsPort(dep *appsv1.Deployment) (string, string) {
+	if port, ok := dep.Annotations[MetricsPortAnnotation]; ok {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return "", ""
+		}
+		return defaultContainer(dep, port)
+	}
+	for _, container := range dep.Spec.Template.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == metricsPortName {
+				return strconv.Itoa(int(port.ContainerPort)), container.Name
+			}
+		}
+	}
+	if port, ok := knownMetricsPorts[dep.Name]; ok {
+		return defaultContainer(dep, port)
+	}
+	return "", ""
+}
+
+// defaultContainer returns the given port along with the container named after the given
+// deployment or its first container. The port is dropped if there are no containers.
+func defaultContainer(dep *appsv1.Deployment, port string) (string, string) {
+	containers := dep.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return "", ""
+	}
+	for _, container := range containers {
+		if container.Name == dep.Name {
+			return port, container.Name
+		}
+	}
+	return port, containers[0].Name
+}
+
+// container returns the component's container of the given deployment.
+func (c Component) container(dep *appsv1.Deployment) (*corev1.Container, error) {
+	name := c.Container
+	if name == "" {
+		name = c.Name
+	}
+	containers := dep.Spec.Template.Spec.Containers
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("deployment %q has no container %q", dep.Name, name)
+}
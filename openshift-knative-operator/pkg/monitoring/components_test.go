@@ -0,0 +1,136 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestDiscoverComponents(t *testing.T) {
+	cases := []struct {
+		name        string
+		deployment  *appsv1.Deployment
+		expected    Components
+		wantUnknown []string
+	}{{
+		name:       "metrics port",
+		deployment: deployment("controller", map[string]string{"app": "controller"}, nil, "controller", 9090),
+		expected: Components{"controller": {
+			Name:           "controller",
+			ServiceAccount: "controller",
+			Selector:       map[string]string{"app": "controller"},
+			MetricsPort:    "9090",
+			Container:      "controller",
+		}},
+	}, {
+		name: "metrics port annotation",
+		deployment: deployment("sources-controller", map[string]string{"app": "sources-controller"},
+			map[string]string{MetricsPortAnnotation: "9092"}, "", 0),
+		expected: Components{"sources-controller": {
+			Name:           "sources-controller",
+			ServiceAccount: "default",
+			Selector:       map[string]string{"app": "sources-controller"},
+			MetricsPort:    "9092",
+			Container:      "sources-controller",
+		}},
+	}, {
+		name: "known metrics port",
+		deployment: deployment("kafka-controller-manager", map[string]string{"control-plane": "kafka-controller-manager"},
+			nil, "", 0),
+		expected: Components{"kafka-controller-manager": {
+			Name:           "kafka-controller-manager",
+			ServiceAccount: "default",
+			Selector:       map[string]string{"control-plane": "kafka-controller-manager"},
+			MetricsPort:    "9090",
+			Container:      "kafka-controller-manager",
+		}},
+	}, {
+		name: "invalid metrics port annotation",
+		deployment: deployment("controller", map[string]string{"app": "controller"},
+			map[string]string{MetricsPortAnnotation: "metrics"}, "controller", 9090),
+		expected:    Components{},
+		wantUnknown: []string{"controller"},
+	}, {
+		name:        "no metrics port",
+		deployment:  deployment("gateway", map[string]string{"app": "gateway"}, nil, "gateway", 0),
+		expected:    Components{},
+		wantUnknown: []string{"gateway"},
+	}, {
+		name:        "no selector labels",
+		deployment:  deployment("controller", nil, nil, "controller", 9090),
+		expected:    Components{},
+		wantUnknown: []string{"controller"},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			u := unstructured.Unstructured{}
+			if err := scheme.Scheme.Convert(c.deployment, &u, nil); err != nil {
+				t.Fatal("Unable to convert deployment", err)
+			}
+			service := unstructured.Unstructured{}
+			service.SetKind("Service")
+			service.SetName("controller")
+
+			components, unknown, err := DiscoverComponents([]unstructured.Unstructured{u, service})
+			if err != nil {
+				t.Fatal("DiscoverComponents() =", err)
+			}
+			if !cmp.Equal(components, c.expected) {
+				t.Errorf("Got = %v, want: %v, diff: %s", components, c.expected, cmp.Diff(components, c.expected))
+			}
+			if !cmp.Equal(unknown, c.wantUnknown) {
+				t.Errorf("Got unknown = %v, want: %v", unknown, c.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestMarkComponentsDiscovered(t *testing.T) {
+	status := &duckv1.Status{}
+	MarkComponentsDiscovered(status, nil)
+	if cond := status.GetCondition(ComponentsDiscovered); cond == nil || !cond.IsTrue() {
+		t.Errorf("Condition = %v, want it to be true", cond)
+	}
+
+	MarkComponentsDiscovered(status, []string{"gateway", "proxy"})
+	cond := status.GetCondition(ComponentsDiscovered)
+	want := `deployments without a "metrics" port or selector labels are not monitored: gateway, proxy`
+	if cond == nil || !cond.IsFalse() || cond.Message != want {
+		t.Errorf("Condition = %v, want a message %q", cond, want)
+	}
+}
+
+func deployment(name string, selector, annotations map[string]string, serviceAccount string, metricsPort int32) *appsv1.Deployment {
+	dep := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					ServiceAccountName: serviceAccount,
+					Containers:         []corev1.Container{{Name: name}},
+				},
+			},
+		},
+	}
+	if selector != nil {
+		dep.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+	}
+	if metricsPort != 0 {
+		dep.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{{
+			Name:          metricsPortName,
+			ContainerPort: metricsPort,
+		}}
+	}
+	return dep
+}
@@ -12,11 +12,14 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 )
 
@@ -29,6 +32,9 @@ const (
 	prometheusRoleName           = "knative-prometheus-k8s"
 	prometheusClusterRoleName    = "rbac-proxy-metrics-prom"
 	smRbacManifestPath           = "SERVICE_MONITOR_RBAC_MANIFEST_PATH"
+	// previousLabelAnnotation records the value the monitoring label had before the operator
+	// changed it for the first time. An empty value means the label wasn't present.
+	previousLabelAnnotation = "operator.serverless.openshift.io/previous-cluster-monitoring"
 )
 
 func init() {
@@ -60,25 +66,60 @@ func injectNamespaceWithSubject(resourceNamespace string, subjectNamespace strin
 	}
 }
 
-func reconcileMonitoring(ctx context.Context, api kubernetes.Interface, spec *v1alpha1.CommonSpec, ns string) error {
-	if ShouldEnableMonitoring(spec.GetConfig()) {
-		if err := reconcileMonitoringLabelOnNamespace(ctx, ns, api, true); err != nil {
+func reconcileMonitoring(ctx context.Context, api kubernetes.Interface, comp v1alpha1.KComponent, commonSpec *v1alpha1.CommonSpec, spec Spec) error {
+	err := configureMetricsBackend(commonSpec, spec)
+	if err == nil {
+		err = validateMonitoringSettings(commonSpec.GetConfig(), spec)
+	}
+	if err != nil {
+		comp.GetStatus().MarkInstallFailed(err.Error())
+		return controller.NewPermanentError(fmt.Errorf("invalid metrics configuration: %w", err))
+	}
+
+	// Only label the namespace if any component is scraped. If "opencensus" is used we still don't want to
+	// scrape from a Serverless controlled namespace.
+	enable := scrapesAny(commonSpec.GetConfig(), spec)
+	if err := reconcileMonitoringLabelOnNamespace(ctx, comp.GetNamespace(), api, enable); err != nil {
+		if enable {
 			return fmt.Errorf("failed to enable monitoring %w ", err)
 		}
-		return nil
-	}
-	// If "opencensus" is used we still dont want to scrape from a Serverless controlled namespace
-	// user can always push to an agent collector in some other namespace and then integrate with OCP monitoring stack
-	if err := reconcileMonitoringLabelOnNamespace(ctx, ns, api, false); err != nil {
 		return fmt.Errorf("failed to disable monitoring %w ", err)
 	}
-	common.Configure(spec, ObservabilityCMName, ObservabilityBackendKey, "none")
-	return nil
+	// Metrics that are neither scraped nor pushed to a collector are disabled.
+	if !ShouldEnableMonitoring(commonSpec.GetConfig(), spec) && metricsBackend(commonSpec.GetConfig(), spec) != BackendOpenCensus {
+		common.Configure(commonSpec, ObservabilityCMName, ObservabilityBackendKey, BackendNone)
+	}
+
+	// The bindings are named after the service accounts, those older versions named after
+	// the deployments would be left behind.
+	components, err := TargetComponents(comp)
+	if err != nil {
+		return err
+	}
+	return deleteClusterRoleBindings(ctx, api, LegacyClusterRoleBindingNames(components))
+}
+
+// validateMonitoringSettings validates the typed monitoring settings of the given config
+// and monitoring settings.
+func validateMonitoringSettings(config v1alpha1.ConfigMapData, spec Spec) error {
+	if err := validateAlertSettings(spec); err != nil {
+		return err
+	}
+	if _, err := RbacProxyConfigFrom(spec); err != nil {
+		return err
+	}
+	if _, err := UserWorkloadMonitoringFrom(config); err != nil {
+		return err
+	}
+	_, err := SourceMonitoringFrom(config)
+	return err
 }
 
-func ShouldEnableMonitoring(config v1alpha1.ConfigMapData) bool {
-	backend := config[ObservabilityCMName][ObservabilityBackendKey]
-	if backend == "none" || backend == "opencensus" {
+// ShouldEnableMonitoring returns true if the metrics of the components are scraped by
+// Prometheus, unless a deployment overrides its backend.
+func ShouldEnableMonitoring(config v1alpha1.ConfigMapData, spec Spec) bool {
+	backend := metricsBackend(config, spec)
+	if backend == BackendNone || backend == BackendOpenCensus {
 		return false
 	}
 
@@ -98,6 +139,72 @@ func ShouldEnableMonitoring(config v1alpha1.ConfigMapData) bool {
 	return parsedEnable
 }
 
+// getTransformers returns the transformers setting up the monitoring of the given components.
+// When monitoring is off we keep around the required resources, only rbac-proxy is removed.
+func getTransformers(ctx context.Context, profile *TLSProfile, comp v1alpha1.KComponent, spec Spec, components Components) []mf.Transformer {
+	transformers := []mf.Transformer{
+		injectNamespaceWithSubject(comp.GetNamespace(), OpenshiftMonitoringNamespace),
+		InjectObservabilityOverrides(spec, components),
+	}
+	if scraped := ScrapedDeployments(comp.GetSpec().GetConfig(), spec, components.Names()); scraped.Len() > 0 {
+		proxy, err := rbacProxyConfig(ctx, profile, spec)
+		if err != nil {
+			return append(transformers, failedTransformer(err))
+		}
+		transformers = append(transformers, InjectRbacProxyContainerToDeployments(components.Only(scraped), proxy))
+	}
+	return transformers
+}
+
+// getMonitoringPlatformManifests returns the RBAC and ServiceMonitor resources to scrape the
+// given components, the rbac-proxy's authorization config and the PrometheusRule with the
+// given alerts.
+func getMonitoringPlatformManifests(comp v1alpha1.KComponent, spec Spec, components Components, name string, alerts []alert) ([]mf.Manifest, error) {
+	rbacManifest, err := getRBACManifest()
+	if err != nil {
+		return nil, err
+	}
+	for sa := range components.ServiceAccounts() {
+		crbM, err := CreateClusterRoleBindingManifest(sa, comp.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		rbacManifest = rbacManifest.Append(*crbM)
+	}
+	for _, c := range components {
+		if err := AppendManifestsForComponent(c, comp.GetNamespace(), &rbacManifest); err != nil {
+			return nil, err
+		}
+	}
+	overrides, err := ObservabilityOverridesManifest(comp, spec, components.Names())
+	if err != nil {
+		return nil, err
+	}
+	rules, err := alertRulesManifest(name, comp.GetNamespace(), comp.GetSpec().GetConfig(), spec, components, alerts)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := RbacProxyConfigFrom(spec)
+	if err != nil {
+		return nil, err
+	}
+	authorization, err := rbacProxyAuthorizationManifest(comp.GetNamespace(), proxy)
+	if err != nil {
+		return nil, err
+	}
+	return []mf.Manifest{rbacManifest.Append(overrides, rules, authorization)}, nil
+}
+
+// finalizeMonitoring removes the cluster-scoped bindings created for the given components,
+// including those of older versions, and reverts the monitoring label on the namespace.
+func finalizeMonitoring(ctx context.Context, api kubernetes.Interface, comp v1alpha1.KComponent, components Components) error {
+	names := clusterRoleBindingNames(components).Union(LegacyClusterRoleBindingNames(components))
+	if err := deleteClusterRoleBindings(ctx, api, names); err != nil {
+		return err
+	}
+	return revertMonitoringLabelOnNamespace(ctx, comp.GetNamespace(), api)
+}
+
 func reconcileMonitoringLabelOnNamespace(ctx context.Context, namespace string, api kubernetes.Interface, enable bool) error {
 	ns, err := api.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	if err != nil {
@@ -115,6 +222,13 @@ func reconcileMonitoringLabelOnNamespace(ctx context.Context, namespace string,
 	if ns.Labels == nil {
 		ns.Labels = make(map[string]string, 1)
 	}
+	if ns.Annotations == nil {
+		ns.Annotations = make(map[string]string, 1)
+	}
+	// Remember the original value once, so it can be restored on finalize.
+	if _, ok := ns.Annotations[previousLabelAnnotation]; !ok {
+		ns.Annotations[previousLabelAnnotation] = ns.Labels[EnableMonitoringLabel]
+	}
 	ns.Labels[EnableMonitoringLabel] = strconv.FormatBool(enable)
 	if _, err = api.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{}); err != nil {
 		return fmt.Errorf("could not add label %q to namespace %q: %w", EnableMonitoringLabel, namespace, err)
@@ -122,7 +236,67 @@ func reconcileMonitoringLabelOnNamespace(ctx context.Context, namespace string,
 	return nil
 }
 
-func AppendManifestsForComponent(c string, ns string, rbacManifest *mf.Manifest) error {
+// revertMonitoringLabelOnNamespace restores the monitoring label to the value it had before
+// the operator changed it. Namespaces whose label hasn't been changed by the operator are
+// left untouched.
+func revertMonitoringLabelOnNamespace(ctx context.Context, namespace string, api kubernetes.Interface) error {
+	ns, err := api.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	previous, ok := ns.Annotations[previousLabelAnnotation]
+	if !ok {
+		return nil
+	}
+	if previous == "" {
+		delete(ns.Labels, EnableMonitoringLabel)
+	} else {
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string, 1)
+		}
+		ns.Labels[EnableMonitoringLabel] = previous
+	}
+	delete(ns.Annotations, previousLabelAnnotation)
+	if _, err = api.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("could not revert label %q on namespace %q: %w", EnableMonitoringLabel, namespace, err)
+	}
+	return nil
+}
+
+// deleteClusterRoleBindings deletes the ClusterRoleBindings with the given names.
+func deleteClusterRoleBindings(ctx context.Context, api kubernetes.Interface, names sets.String) error {
+	for name := range names {
+		err := api.RbacV1().ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ClusterRoleBinding %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// LegacyClusterRoleBindingNames returns the names of the ClusterRoleBindings older versions
+// created per deployment rather than per service account, leaving out those still in use.
+func LegacyClusterRoleBindingNames(components Components) sets.String {
+	names := sets.NewString()
+	for name := range components.Names().Difference(components.ServiceAccounts()) {
+		names.Insert(clusterRoleBindingName(name))
+	}
+	return names
+}
+
+// clusterRoleBindingNames returns the names of the ClusterRoleBindings created for the
+// service accounts of the given components.
+func clusterRoleBindingNames(components Components) sets.String {
+	names := sets.NewString()
+	for sa := range components.ServiceAccounts() {
+		names.Insert(clusterRoleBindingName(sa))
+	}
+	return names
+}
+
+func AppendManifestsForComponent(c Component, ns string, rbacManifest *mf.Manifest) error {
 	smManifest, err := constructServiceMonitorResourceManifests(c, ns)
 	if err != nil {
 		return err
@@ -133,14 +307,14 @@ func AppendManifestsForComponent(c string, ns string, rbacManifest *mf.Manifest)
 	return nil
 }
 
-func constructServiceMonitorResourceManifests(component string, ns string) (*mf.Manifest, error) {
+func constructServiceMonitorResourceManifests(component Component, ns string) (*mf.Manifest, error) {
 	var smU = &unstructured.Unstructured{}
 	var svU = &unstructured.Unstructured{}
 	sms := createServiceMonitorService(component, ns)
 	if err := scheme.Scheme.Convert(&sms, svU, nil); err != nil {
 		return nil, err
 	}
-	sm := createServiceMonitor(component, ns, sms.Name)
+	sm := createServiceMonitor(component.Name, ns, sms.Name)
 	if err := scheme.Scheme.Convert(&sm, smU, nil); err != nil {
 		return nil, err
 	}
@@ -180,8 +354,14 @@ func createServiceMonitor(component string, ns string, serviceName string) monit
 		}}
 }
 
-func createServiceMonitorService(component string, ns string) corev1.Service {
-	serviceName := fmt.Sprintf("%s-sm-service", component)
+// serviceMonitorServiceName is the name of the Service scraped for the given deployment,
+// which is also the job label of its metrics.
+func serviceMonitorServiceName(deployment string) string {
+	return fmt.Sprintf("%s-sm-service", deployment)
+}
+
+func createServiceMonitorService(component Component, ns string) corev1.Service {
+	serviceName := serviceMonitorServiceName(component.Name)
 	return corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        serviceName,
@@ -194,14 +374,14 @@ func createServiceMonitorService(component string, ns string) corev1.Service {
 				Name: "https",
 				Port: 8444,
 			}},
-			Selector: getSelectorLabels(component),
+			Selector: component.Selector,
 		}}
 }
 
 func CreateClusterRoleBindingManifest(serviceAccountName string, ns string) (*mf.Manifest, error) {
 	crb := v1.ClusterRoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: fmt.Sprintf("rbac-proxy-reviews-prom-rb-%s", serviceAccountName),
+			Name: clusterRoleBindingName(serviceAccountName),
 		},
 		RoleRef: v1.RoleRef{
 			APIGroup: "rbac.authorization.k8s.io",
@@ -225,46 +405,8 @@ func CreateClusterRoleBindingManifest(serviceAccountName string, ns string) (*mf
 	return &manifest, nil
 }
 
-// getDefaultMetricsPort returns the expected metrics port under the assumption that this will not change
-// This is static information since observability cm does not allow any changes for the prometheus config
-// TODO(skonto): fix this upstream so ports are aligned if possible
-func getDefaultMetricsPort(name string) string {
-	if name == "mt-broker-ingress" || name == "mt-broker-filter" {
-		return "9092"
-	}
-	return "9090"
-}
-
-// getSelectorLabels returns the correct deployment label to use with the service monitor service.
-// The component is any Serving, Eventing component name eg. activator. Each component's deployment
-// has either a label "app" or some special label or a set of labels that are unique to the component.
-func getSelectorLabels(component string) map[string]string {
-	labels := map[string]string{}
-	switch component {
-	case "sugar-controller":
-		labels["eventing.knative.dev/role"] = component
-	case "imc-controller":
-		labels["messaging.knative.dev/channel"] = "in-memory-channel"
-		labels["messaging.knative.dev/role"] = "controller"
-	case "imc-dispatcher":
-		labels["messaging.knative.dev/channel"] = "in-memory-channel"
-		labels["messaging.knative.dev/role"] = "dispatcher"
-	case "mt-broker-filter":
-		labels["eventing.knative.dev/brokerRole"] = "filter"
-	case "mt-broker-ingress":
-		labels["eventing.knative.dev/brokerRole"] = "ingress"
-	case "kafka-controller-manager":
-		labels["control-plane"] = "kafka-controller-manager"
-	case "kafka-ch-controller":
-		labels["messaging.knative.dev/channel"] = "kafka-channel"
-		labels["messaging.knative.dev/role"] = "controller"
-	case "kafka-ch-dispatcher":
-		labels["messaging.knative.dev/channel"] = "kafka-channel"
-		labels["messaging.knative.dev/role"] = "dispatcher"
-	default:
-		labels["app"] = component
-	}
-	return labels
+func clusterRoleBindingName(serviceAccountName string) string {
+	return fmt.Sprintf("rbac-proxy-reviews-prom-rb-%s", serviceAccountName)
 }
 
 func getRBACManifest() (mf.Manifest, error) {
@@ -4,48 +4,40 @@ import (
 	"context"
 
 	mf "github.com/manifestival/manifestival"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
 )
 
-var (
-	eventingDeployments = sets.NewString("eventing-controller", "eventing-webhook", "imc-controller", "imc-dispatcher", "mt-broker-controller", "mt-broker-filter", "mt-broker-ingress", "sugar-controller")
-)
+// ReconcileMonitoringForEventing validates the monitoring settings of the Eventing components and
+// renders them into the observability config.
+func ReconcileMonitoringForEventing(ctx context.Context, api kubernetes.Interface, ke *v1alpha1.KnativeEventing, spec Spec) error {
+	return reconcileMonitoring(ctx, api, ke, &ke.Spec.CommonSpec, spec)
+}
 
-func ReconcileMonitoringForEventing(ctx context.Context, api kubernetes.Interface, ke *v1alpha1.KnativeEventing) error {
-	return reconcileMonitoring(ctx, api, &ke.Spec.CommonSpec, ke.GetNamespace())
+// FinalizeMonitoringForEventing removes the cluster-scoped bindings created for the Eventing
+// components and reverts the monitoring label on the namespace.
+func FinalizeMonitoringForEventing(ctx context.Context, api kubernetes.Interface, ke *v1alpha1.KnativeEventing) error {
+	components, err := TargetComponents(ke)
+	if err != nil {
+		return err
+	}
+	return finalizeMonitoring(ctx, api, ke, components)
 }
 
-func GetEventingTransformers(comp v1alpha1.KComponent) []mf.Transformer {
-	// When monitoring is off we keep around the required resources, only rbac-proxy is removed
-	transformers := []mf.Transformer{injectNamespaceWithSubject(comp.GetNamespace(), OpenshiftMonitoringNamespace)}
-	if ShouldEnableMonitoring(comp.GetSpec().GetConfig()) {
-		transformers = append(transformers, InjectRbacProxyContainerToDeployments(eventingDeployments))
+// GetEventingTransformers returns the transformers setting up the monitoring of the Eventing components.
+// The rbac-proxy follows the given TLS profile of the cluster.
+func GetEventingTransformers(ctx context.Context, profile *TLSProfile, comp v1alpha1.KComponent, spec Spec) []mf.Transformer {
+	components, err := TargetComponents(comp)
+	if err != nil {
+		return []mf.Transformer{failedTransformer(err)}
 	}
-	return transformers
+	return getTransformers(ctx, profile, comp, spec, components)
 }
 
-func GetEventingMonitoringPlatformManifests(ke v1alpha1.KComponent) ([]mf.Manifest, error) {
-	rbacManifest, err := getRBACManifest()
+func GetEventingMonitoringPlatformManifests(ke v1alpha1.KComponent, spec Spec) ([]mf.Manifest, error) {
+	components, err := TargetComponents(ke)
 	if err != nil {
 		return nil, err
 	}
-	// Only mt-broker-controller has a different than its name sa (eventing-controller)
-	for sa := range eventingDeployments {
-		if sa == "mt-broker-controller" {
-			continue
-		}
-		crbM, err := CreateClusterRoleBindingManifest(sa, ke.GetNamespace())
-		if err != nil {
-			return nil, err
-		}
-		rbacManifest = rbacManifest.Append(*crbM)
-	}
-	for c := range eventingDeployments {
-		if err := AppendManifestsForComponent(c, ke.GetNamespace(), &rbacManifest); err != nil {
-			return nil, err
-		}
-	}
-	return []mf.Manifest{rbacManifest}, nil
+	return getMonitoringPlatformManifests(ke, spec, components, "knative-eventing", eventingAlerts)
 }
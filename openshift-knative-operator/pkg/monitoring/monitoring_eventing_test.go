@@ -1,26 +1,32 @@
 package monitoring
 
 import (
+	"context"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
 )
 
 func TestLoadPlatformEventingMonitoringManifests(t *testing.T) {
 	manifests, err := GetEventingMonitoringPlatformManifests(&v1alpha1.KnativeEventing{
 		ObjectMeta: metav1.ObjectMeta{Namespace: eventingNamespace},
-	})
+	}, Spec{})
 	if err != nil {
-		t.Errorf("Unable to load eventing monitoring platform manifests: %w", err)
+		t.Errorf("Unable to load eventing monitoring platform manifests: %v", err)
 	}
 	if len(manifests) != 1 {
 		t.Errorf("Got %d, want %d", len(manifests), 1)
 	}
 	resources := manifests[0].Resources()
-	if len(resources) != 28 {
-		t.Errorf("Got %d, want %d", len(resources), 28)
+	if len(resources) != 34 {
+		t.Errorf("Got %d, want %d", len(resources), 34)
 	}
 	for _, u := range resources {
 		kind := strings.ToLower(u.GetKind())
@@ -37,14 +43,21 @@ func TestLoadPlatformEventingMonitoringManifests(t *testing.T) {
 			if u.GetName() == "rbac-proxy-metrics-prom-rb" || u.GetName() == "rbac-proxy-reviews-prom-rb" {
 				continue
 			}
-			if !eventingDeployments.Has(strings.TrimPrefix(u.GetName(), "rbac-proxy-reviews-prom-rb-")) {
+			if !eventingServiceAccounts.Has(strings.TrimPrefix(u.GetName(), "rbac-proxy-reviews-prom-rb-")) {
 				t.Errorf("Clusterrolebinding with name %q not found", u.GetName())
 			}
+		case "prometheusrule":
+			if u.GetName() != "knative-eventing-alerts" {
+				t.Errorf("Unknown PrometheusRule %q", u.GetName())
+			}
 		case "role":
-			if u.GetName() != "knative-prometheus-k8s" {
+			if u.GetName() != "knative-prometheus-k8s" && u.GetName() != metricsReaderRoleName {
 				t.Errorf("Uknown role %q", u.GetName())
 			}
 		case "rolebinding":
+			if u.GetName() == metricsReaderRoleName {
+				continue
+			}
 			if u.GetName() != "knative-prometheus-k8s" {
 				t.Errorf("Uknown rolebinding %q", u.GetName())
 			}
@@ -52,3 +65,70 @@ func TestLoadPlatformEventingMonitoringManifests(t *testing.T) {
 		}
 	}
 }
+
+func TestFinalizeMonitoringForEventing(t *testing.T) {
+	cases := []struct {
+		name          string
+		labels        map[string]string
+		expectedLabel string
+		expectedFound bool
+	}{{
+		name:          "label added by the operator",
+		expectedFound: false,
+	}, {
+		name:          "label changed by the operator",
+		labels:        map[string]string{EnableMonitoringLabel: "false"},
+		expectedLabel: "false",
+		expectedFound: true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			ke := &v1alpha1.KnativeEventing{
+				ObjectMeta: metav1.ObjectMeta{Namespace: eventingNamespace},
+			}
+			objs := []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: eventingNamespace, Labels: c.labels}},
+				&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-metrics-prom-rb"}},
+				&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-reviews-prom-rb-activator"}},
+			}
+			for sa := range eventingServiceAccounts {
+				objs = append(objs, &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-reviews-prom-rb-" + sa}})
+			}
+			kube := fake.NewSimpleClientset(objs...)
+
+			if err := reconcileMonitoringLabelOnNamespace(ctx, eventingNamespace, kube, true); err != nil {
+				t.Fatal("Failed to set the monitoring label", err)
+			}
+			if err := FinalizeMonitoringForEventing(ctx, kube, ke); err != nil {
+				t.Fatal("Failed to finalize", err)
+			}
+
+			crbs, err := kube.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatal("Failed to list ClusterRoleBindings", err)
+			}
+			var names []string
+			for _, crb := range crbs.Items {
+				names = append(names, crb.Name)
+			}
+			want := []string{"rbac-proxy-metrics-prom-rb", "rbac-proxy-reviews-prom-rb-activator"}
+			if !cmp.Equal(names, want) {
+				t.Errorf("Got ClusterRoleBindings %v, want %v", names, want)
+			}
+
+			ns, err := kube.CoreV1().Namespaces().Get(ctx, eventingNamespace, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("Failed to get namespace", err)
+			}
+			label, found := ns.Labels[EnableMonitoringLabel]
+			if label != c.expectedLabel || found != c.expectedFound {
+				t.Errorf("Got label %q (found: %v), want %q (found: %v)", label, found, c.expectedLabel, c.expectedFound)
+			}
+			if _, ok := ns.Annotations[previousLabelAnnotation]; ok {
+				t.Errorf("Annotation %q has not been removed", previousLabelAnnotation)
+			}
+		})
+	}
+}
@@ -4,43 +4,40 @@ import (
 	"context"
 
 	mf "github.com/manifestival/manifestival"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
 )
 
-var (
-	servingDeployments = sets.NewString("activator", "autoscaler", "autoscaler-hpa", "controller", "domain-mapping", "domainmapping-webhook", "webhook")
-)
-
-func ReconcileMonitoringForServing(ctx context.Context, api kubernetes.Interface, ks *v1alpha1.KnativeServing) error {
-	return reconcileMonitoring(ctx, api, &ks.Spec.CommonSpec, ks.GetNamespace())
+// ReconcileMonitoringForServing validates the monitoring settings of the Serving components and
+// renders them into the observability config.
+func ReconcileMonitoringForServing(ctx context.Context, api kubernetes.Interface, ks *v1alpha1.KnativeServing, spec Spec) error {
+	return reconcileMonitoring(ctx, api, ks, &ks.Spec.CommonSpec, spec)
 }
 
-func GetServingTransformers(comp v1alpha1.KComponent) []mf.Transformer {
-	// When monitoring is off we keep around the required resources, only rbac-proxy is removed
-	transformers := []mf.Transformer{injectNamespaceWithSubject(comp.GetNamespace(), OpenshiftMonitoringNamespace)}
-	if ShouldEnableMonitoring(comp.GetSpec().GetConfig()) {
-		transformers = append(transformers, InjectRbacProxyContainerToDeployments(servingDeployments))
+// FinalizeMonitoringForServing removes the cluster-scoped bindings created for the Serving
+// components and reverts the monitoring label on the namespace.
+func FinalizeMonitoringForServing(ctx context.Context, api kubernetes.Interface, ks *v1alpha1.KnativeServing) error {
+	components, err := TargetComponents(ks)
+	if err != nil {
+		return err
 	}
-	return transformers
+	return finalizeMonitoring(ctx, api, ks, components)
 }
 
-func GetServingMonitoringPlatformManifests(ks v1alpha1.KComponent) ([]mf.Manifest, error) {
-	rbacManifest, err := getRBACManifest()
+// GetServingTransformers returns the transformers setting up the monitoring of the Serving components.
+// The rbac-proxy follows the given TLS profile of the cluster.
+func GetServingTransformers(ctx context.Context, profile *TLSProfile, comp v1alpha1.KComponent, spec Spec) []mf.Transformer {
+	components, err := TargetComponents(comp)
 	if err != nil {
-		return nil, err
+		return []mf.Transformer{failedTransformer(err)}
 	}
-	// Serving has one common sa for all pods
-	crbM, err := CreateClusterRoleBindingManifest("controller", ks.GetNamespace())
+	return getTransformers(ctx, profile, comp, spec, components)
+}
+
+func GetServingMonitoringPlatformManifests(ks v1alpha1.KComponent, spec Spec) ([]mf.Manifest, error) {
+	components, err := TargetComponents(ks)
 	if err != nil {
 		return nil, err
 	}
-	rbacManifest = rbacManifest.Append(*crbM)
-	for c := range servingDeployments {
-		if err := AppendManifestsForComponent(c, ks.GetNamespace(), &rbacManifest); err != nil {
-			return nil, err
-		}
-	}
-	return []mf.Manifest{rbacManifest}, nil
+	return getMonitoringPlatformManifests(ks, spec, components, "knative-serving", servingAlerts)
 }
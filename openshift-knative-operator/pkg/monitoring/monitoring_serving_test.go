@@ -1,26 +1,35 @@
 package monitoring
 
 import (
+	"context"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
 )
 
 func TestLoadPlatformServingMonitoringManifests(t *testing.T) {
-	manifests, err := GetServingMonitoringPlatformManifests(&v1alpha1.KnativeServing{
+	ks := &v1alpha1.KnativeServing{
 		ObjectMeta: metav1.ObjectMeta{Namespace: servingNamespace},
-	})
+	}
+	manifests, err := GetServingMonitoringPlatformManifests(ks, Spec{})
 	if err != nil {
-		t.Errorf("Unable to load serving monitoring platform manifests: %w", err)
+		t.Errorf("Unable to load serving monitoring platform manifests: %v", err)
+	}
+	if cond := ks.Status.GetCondition(ComponentsDiscovered); cond == nil || !cond.IsTrue() {
+		t.Errorf("Condition = %v, want all components to be discovered", cond)
 	}
 	if len(manifests) != 1 {
 		t.Errorf("Got %d, want %d", len(manifests), 1)
 	}
 	resources := manifests[0].Resources()
-	if len(resources) != 20 {
-		t.Errorf("Got %d, want %d", len(resources), 20)
+	if len(resources) != 24 {
+		t.Errorf("Got %d, want %d", len(resources), 24)
 	}
 	for _, u := range resources {
 		kind := strings.ToLower(u.GetKind())
@@ -40,11 +49,18 @@ func TestLoadPlatformServingMonitoringManifests(t *testing.T) {
 			if strings.TrimPrefix(u.GetName(), "rbac-proxy-reviews-prom-rb-") != "controller" {
 				t.Errorf("Clusterrolebinding with name %q not found", u.GetName())
 			}
+		case "prometheusrule":
+			if u.GetName() != "knative-serving-alerts" {
+				t.Errorf("Unknown PrometheusRule %q", u.GetName())
+			}
 		case "role":
-			if u.GetName() != "knative-prometheus-k8s" {
+			if u.GetName() != "knative-prometheus-k8s" && u.GetName() != metricsReaderRoleName {
 				t.Errorf("Uknown role %q", u.GetName())
 			}
 		case "rolebinding":
+			if u.GetName() == metricsReaderRoleName {
+				continue
+			}
 			if u.GetName() != "knative-prometheus-k8s" {
 				t.Errorf("Uknown rolebinding %q", u.GetName())
 			}
@@ -52,3 +68,78 @@ func TestLoadPlatformServingMonitoringManifests(t *testing.T) {
 		}
 	}
 }
+
+func TestReconcileMonitoringForServingLegacyBindings(t *testing.T) {
+	ctx := context.Background()
+	ks := &v1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: servingNamespace},
+	}
+	kube := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: servingNamespace}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-reviews-prom-rb-activator"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-reviews-prom-rb-controller"}},
+	)
+
+	if err := ReconcileMonitoringForServing(ctx, kube, ks, Spec{}); err != nil {
+		t.Fatal("ReconcileMonitoringForServing() =", err)
+	}
+
+	crbs, err := kube.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("Failed to list ClusterRoleBindings", err)
+	}
+	var names []string
+	for _, crb := range crbs.Items {
+		names = append(names, crb.Name)
+	}
+	// The binding of the service account is still in use.
+	want := []string{"rbac-proxy-reviews-prom-rb-controller"}
+	if !cmp.Equal(names, want) {
+		t.Errorf("Got ClusterRoleBindings %v, want %v", names, want)
+	}
+}
+
+func TestFinalizeMonitoringForServing(t *testing.T) {
+	ctx := context.Background()
+	ks := &v1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: servingNamespace},
+	}
+	kube := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: servingNamespace}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-metrics-prom-rb"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-reviews-prom-rb-controller"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-reviews-prom-rb-activator"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rbac-proxy-reviews-prom-rb-eventing-controller"}},
+	)
+
+	if err := reconcileMonitoringLabelOnNamespace(ctx, servingNamespace, kube, true); err != nil {
+		t.Fatal("Failed to set the monitoring label", err)
+	}
+	if err := FinalizeMonitoringForServing(ctx, kube, ks); err != nil {
+		t.Fatal("Failed to finalize", err)
+	}
+
+	crbs, err := kube.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("Failed to list ClusterRoleBindings", err)
+	}
+	var names []string
+	for _, crb := range crbs.Items {
+		names = append(names, crb.Name)
+	}
+	want := []string{"rbac-proxy-metrics-prom-rb", "rbac-proxy-reviews-prom-rb-eventing-controller"}
+	if !cmp.Equal(names, want) {
+		t.Errorf("Got ClusterRoleBindings %v, want %v", names, want)
+	}
+
+	ns, err := kube.CoreV1().Namespaces().Get(ctx, servingNamespace, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get namespace", err)
+	}
+	if label, found := ns.Labels[EnableMonitoringLabel]; found {
+		t.Errorf("Got label %q, want it to be removed", label)
+	}
+	if _, ok := ns.Annotations[previousLabelAnnotation]; ok {
+		t.Errorf("Annotation %q has not been removed", previousLabelAnnotation)
+	}
+}
@@ -0,0 +1,259 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	mf "github.com/manifestival/manifestival"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const (
+	rbacProxyConfigName      = "kube-rbac-proxy-config"
+	rbacProxyConfigFile      = "config.yaml"
+	rbacProxyConfigMountPath = "/etc/kube-rbac-proxy"
+	// metricsReaderRoleName is the name of the Role and RoleBinding granting the allowed
+	// service accounts access to the metrics of a namespace.
+	metricsReaderRoleName = "knative-metrics-reader"
+)
+
+var (
+	defaultRbacProxyResources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10m"),
+			corev1.ResourceMemory: resource.MustParse("20Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("100Mi"),
+		},
+	}
+
+	tlsVersions = sets.NewString(
+		string(configv1.VersionTLS10),
+		string(configv1.VersionTLS11),
+		string(configv1.VersionTLS12),
+		string(configv1.VersionTLS13),
+	)
+
+	// openSSLToIANACiphers maps the OpenSSL names used by the TLS profiles to the IANA names
+	// understood by the rbac-proxy. Ciphers Go doesn't support are left out.
+	openSSLToIANACiphers = map[string]string{
+		"ECDHE-ECDSA-AES128-GCM-SHA256": "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		"ECDHE-RSA-AES128-GCM-SHA256":   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+		"ECDHE-ECDSA-AES256-GCM-SHA384": "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+		"ECDHE-RSA-AES256-GCM-SHA384":   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+		"ECDHE-ECDSA-CHACHA20-POLY1305": "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+		"ECDHE-RSA-CHACHA20-POLY1305":   "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+		"ECDHE-ECDSA-AES128-SHA256":     "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256",
+		"ECDHE-RSA-AES128-SHA256":       "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256",
+		"ECDHE-ECDSA-AES128-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+		"ECDHE-RSA-AES128-SHA":          "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+		"ECDHE-ECDSA-AES256-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+		"ECDHE-RSA-AES256-SHA":          "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+		"AES128-GCM-SHA256":             "TLS_RSA_WITH_AES_128_GCM_SHA256",
+		"AES256-GCM-SHA384":             "TLS_RSA_WITH_AES_256_GCM_SHA384",
+		"AES128-SHA256":                 "TLS_RSA_WITH_AES_128_CBC_SHA256",
+		"AES128-SHA":                    "TLS_RSA_WITH_AES_128_CBC_SHA",
+		"AES256-SHA":                    "TLS_RSA_WITH_AES_256_CBC_SHA",
+		"DES-CBC3-SHA":                  "TLS_RSA_WITH_3DES_EDE_CBC_SHA",
+	}
+)
+
+// RbacProxyConfig configures the rbac-proxy sidecars.
+type RbacProxyConfig struct {
+	// LogLevel is the log verbosity.
+	LogLevel int
+	// Resources are the resources of the sidecar container.
+	Resources corev1.ResourceRequirements
+	// CipherSuites are the IANA names of the accepted cipher suites. Go's defaults are used
+	// if empty.
+	CipherSuites []string
+	// MinTLSVersion is the minimal TLS version. Go's default is used if empty.
+	MinTLSVersion string
+	// AllowedServiceAccounts are the only service accounts allowed to scrape the metrics.
+	// Any service account allowed to get "/metrics" can scrape them if empty.
+	AllowedServiceAccounts []rbacv1.Subject
+}
+
+// RbacProxyConfigFrom validates the rbac-proxy settings of the given monitoring settings.
+func RbacProxyConfigFrom(spec Spec) (RbacProxyConfig, error) {
+	settings := spec.RbacProxy
+	proxy := RbacProxyConfig{
+		LogLevel:      settings.LogLevel,
+		Resources:     *defaultRbacProxyResources.DeepCopy(),
+		MinTLSVersion: settings.TLS.MinVersion,
+	}
+	if settings.LogLevel < 0 {
+		return RbacProxyConfig{}, fmt.Errorf("invalid spec.%s.monitoring.rbacProxy.logLevel %d, must not be negative",
+			common.SpecExtensionField, settings.LogLevel)
+	}
+
+	for name, q := range settings.Resources.Requests {
+		proxy.Resources.Requests[name] = q
+	}
+	for name, q := range settings.Resources.Limits {
+		proxy.Resources.Limits[name] = q
+	}
+	for name, request := range proxy.Resources.Requests {
+		if limit, ok := proxy.Resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+			return RbacProxyConfig{}, fmt.Errorf("the rbac-proxy %s request %s exceeds its limit %s", name, request.String(), limit.String())
+		}
+	}
+
+	known := supportedCipherSuites()
+	for _, cipher := range settings.TLS.CipherSuites {
+		if !known.Has(cipher) {
+			return RbacProxyConfig{}, fmt.Errorf("unsupported cipher suite %q in spec.%s.monitoring.rbacProxy.tls.cipherSuites",
+				cipher, common.SpecExtensionField)
+		}
+		proxy.CipherSuites = append(proxy.CipherSuites, cipher)
+	}
+	if version := settings.TLS.MinVersion; version != "" && !tlsVersions.Has(version) {
+		return RbacProxyConfig{}, fmt.Errorf("invalid spec.%s.monitoring.rbacProxy.tls.minVersion %q, must be one of %s",
+			common.SpecExtensionField, version, strings.Join(tlsVersions.List(), ", "))
+	}
+
+	for _, account := range settings.AllowedServiceAccounts {
+		if account.Namespace == "" || account.Name == "" {
+			return RbacProxyConfig{}, fmt.Errorf("invalid service account %q in spec.%s.monitoring.rbacProxy.allowedServiceAccounts, must have a namespace and a name",
+				account.Namespace+"/"+account.Name, common.SpecExtensionField)
+		}
+		proxy.AllowedServiceAccounts = append(proxy.AllowedServiceAccounts, rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: account.Namespace,
+			Name:      account.Name,
+		})
+	}
+	return proxy, nil
+}
+
+// DefaultTLS sets the TLS settings that aren't configured from the given TLS profile. A nil
+// profile stands for the intermediate profile, which is the cluster's default.
+func (c *RbacProxyConfig) DefaultTLS(profile *configv1.TLSSecurityProfile) {
+	spec := tlsProfileSpec(profile)
+	if len(c.CipherSuites) == 0 {
+		for _, cipher := range spec.Ciphers {
+			if iana, ok := openSSLToIANACiphers[cipher]; ok {
+				c.CipherSuites = append(c.CipherSuites, iana)
+			} else if supportedCipherSuites().Has(cipher) {
+				// Custom profiles may already use IANA names.
+				c.CipherSuites = append(c.CipherSuites, cipher)
+			}
+		}
+	}
+	if c.MinTLSVersion == "" {
+		c.MinTLSVersion = string(spec.MinTLSVersion)
+	}
+}
+
+// rbacProxyConfig returns the rbac-proxy config of the given monitoring settings with the
+// TLS settings defaulted from the cluster's TLS profile.
+func rbacProxyConfig(ctx context.Context, profile *TLSProfile, spec Spec) (RbacProxyConfig, error) {
+	proxy, err := RbacProxyConfigFrom(spec)
+	if err != nil {
+		return RbacProxyConfig{}, err
+	}
+	p, err := profile.Get(ctx)
+	if err != nil {
+		return RbacProxyConfig{}, err
+	}
+	proxy.DefaultTLS(p)
+	return proxy, nil
+}
+
+// rbacProxyAuthorizationManifest returns the config making the rbac-proxy authorize scrapes
+// as "get services/metrics" in the given namespace and the Role and RoleBinding granting it
+// to the allowed service accounts. The resources are kept around with no subjects if any
+// service account may scrape, the sidecars only use the config if the list is set.
+func rbacProxyAuthorizationManifest(ns string, proxy RbacProxyConfig) (mf.Manifest, error) {
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rbacProxyConfigName,
+			Namespace: ns,
+		},
+		Data: map[string]string{
+			rbacProxyConfigFile: fmt.Sprintf(`authorization:
+  resourceAttributes:
+    namespace: %s
+    apiVersion: v1
+    resource: services
+    subresource: metrics
+`, ns),
+		},
+	}
+	role := &rbacv1.Role{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metricsReaderRoleName,
+			Namespace: ns,
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"services/metrics"},
+			Verbs:     []string{"get"},
+		}},
+	}
+	binding := &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metricsReaderRoleName,
+			Namespace: ns,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     metricsReaderRoleName,
+		},
+		Subjects: proxy.AllowedServiceAccounts,
+	}
+
+	resources := make([]unstructured.Unstructured, 0, 3)
+	for _, obj := range []runtime.Object{cm, role, binding} {
+		u := unstructured.Unstructured{}
+		if err := scheme.Scheme.Convert(obj, &u, nil); err != nil {
+			return mf.Manifest{}, err
+		}
+		resources = append(resources, u)
+	}
+	return mf.ManifestFrom(mf.Slice(resources))
+}
+
+// tlsProfileSpec returns the ciphers and minimal TLS version of the given profile.
+func tlsProfileSpec(profile *configv1.TLSSecurityProfile) *configv1.TLSProfileSpec {
+	if profile != nil {
+		if profile.Type == configv1.TLSProfileCustomType && profile.Custom != nil {
+			return &profile.Custom.TLSProfileSpec
+		}
+		if spec, ok := configv1.TLSProfiles[profile.Type]; ok {
+			return spec
+		}
+	}
+	return configv1.TLSProfiles[configv1.TLSProfileIntermediateType]
+}
+
+// supportedCipherSuites returns the IANA names of the cipher suites Go supports. TLS 1.3
+// suites are left out as they can't be configured.
+func supportedCipherSuites() sets.String {
+	names := sets.NewString()
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		for _, version := range suite.SupportedVersions {
+			if version < tls.VersionTLS13 {
+				names.Insert(suite.Name)
+				break
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,204 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mf "github.com/manifestival/manifestival"
+	configv1 "github.com/openshift/api/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestRbacProxyConfigFrom(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings RbacProxy
+		expected RbacProxyConfig
+		wantErr  bool
+	}{{
+		name:     "default",
+		expected: RbacProxyConfig{Resources: defaultRbacProxyResources},
+	}, {
+		name: "tuned",
+		settings: RbacProxy{
+			LogLevel: 2,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("30Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			},
+			TLS: RbacProxyTLS{
+				CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+				MinVersion:   "VersionTLS12",
+			},
+			AllowedServiceAccounts: []ServiceAccount{
+				{Namespace: "openshift-monitoring", Name: "prometheus-k8s"},
+				{Namespace: "observability", Name: "collector"},
+			},
+		},
+		expected: RbacProxyConfig{
+			LogLevel: 2,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10m"),
+					corev1.ResourceMemory: resource.MustParse("30Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("100Mi"),
+				},
+			},
+			CipherSuites:  []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+			MinTLSVersion: "VersionTLS12",
+			AllowedServiceAccounts: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Namespace: "openshift-monitoring", Name: "prometheus-k8s"},
+				{Kind: rbacv1.ServiceAccountKind, Namespace: "observability", Name: "collector"},
+			},
+		},
+	}, {
+		name:     "negative log level",
+		settings: RbacProxy{LogLevel: -1},
+		wantErr:  true,
+	}, {
+		name: "request exceeding limit",
+		settings: RbacProxy{Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("200Mi")},
+		}},
+		wantErr: true,
+	}, {
+		name:     "OpenSSL cipher name",
+		settings: RbacProxy{TLS: RbacProxyTLS{CipherSuites: []string{"ECDHE-RSA-AES128-GCM-SHA256"}}},
+		wantErr:  true,
+	}, {
+		name:     "TLS 1.3 cipher",
+		settings: RbacProxy{TLS: RbacProxyTLS{CipherSuites: []string{"TLS_AES_128_GCM_SHA256"}}},
+		wantErr:  true,
+	}, {
+		name:     "invalid TLS version",
+		settings: RbacProxy{TLS: RbacProxyTLS{MinVersion: "1.2"}},
+		wantErr:  true,
+	}, {
+		name:     "service account without namespace",
+		settings: RbacProxy{AllowedServiceAccounts: []ServiceAccount{{Name: "prometheus-k8s"}}},
+		wantErr:  true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			proxy, err := RbacProxyConfigFrom(Spec{RbacProxy: c.settings})
+			if (err != nil) != c.wantErr {
+				t.Fatalf("RbacProxyConfigFrom() = %v, wantErr: %v", err, c.wantErr)
+			}
+			if c.wantErr {
+				return
+			}
+			if !cmp.Equal(proxy, c.expected) {
+				t.Errorf("Got = %v, want: %v, diff: %s", proxy, c.expected, cmp.Diff(proxy, c.expected))
+			}
+		})
+	}
+}
+
+func TestRbacProxyDefaultTLS(t *testing.T) {
+	cases := []struct {
+		name        string
+		proxy       RbacProxyConfig
+		profile     *configv1.TLSSecurityProfile
+		wantCiphers []string
+		wantVersion string
+	}{{
+		name: "no profile",
+		wantCiphers: []string{
+			"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+			"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+			"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+			"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+			"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+			"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+		},
+		wantVersion: "VersionTLS12",
+	}, {
+		name:        "modern profile",
+		profile:     &configv1.TLSSecurityProfile{Type: configv1.TLSProfileModernType},
+		wantVersion: "VersionTLS13",
+	}, {
+		name: "custom profile",
+		profile: &configv1.TLSSecurityProfile{
+			Type: configv1.TLSProfileCustomType,
+			Custom: &configv1.CustomTLSProfile{TLSProfileSpec: configv1.TLSProfileSpec{
+				Ciphers:       []string{"ECDHE-RSA-AES256-GCM-SHA384", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "DHE-RSA-AES256-GCM-SHA384"},
+				MinTLSVersion: configv1.VersionTLS11,
+			}},
+		},
+		wantCiphers: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		wantVersion: "VersionTLS11",
+	}, {
+		name:        "configured",
+		proxy:       RbacProxyConfig{CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}, MinTLSVersion: "VersionTLS13"},
+		profile:     &configv1.TLSSecurityProfile{Type: configv1.TLSProfileOldType},
+		wantCiphers: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		wantVersion: "VersionTLS13",
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.proxy.DefaultTLS(c.profile)
+			if !cmp.Equal(c.proxy.CipherSuites, c.wantCiphers) {
+				t.Errorf("Got ciphers = %v, want: %v", c.proxy.CipherSuites, c.wantCiphers)
+			}
+			if c.proxy.MinTLSVersion != c.wantVersion {
+				t.Errorf("Got version = %q, want: %q", c.proxy.MinTLSVersion, c.wantVersion)
+			}
+		})
+	}
+}
+
+func TestRbacProxyAllowedServiceAccounts(t *testing.T) {
+	proxy, err := RbacProxyConfigFrom(Spec{RbacProxy: RbacProxy{
+		AllowedServiceAccounts: []ServiceAccount{{Namespace: "openshift-monitoring", Name: "prometheus-k8s"}},
+	}})
+	if err != nil {
+		t.Fatal("RbacProxyConfigFrom() =", err)
+	}
+
+	authorization, err := rbacProxyAuthorizationManifest(servingNamespace, proxy)
+	if err != nil {
+		t.Fatal("rbacProxyAuthorizationManifest() =", err)
+	}
+	binding := &rbacv1.RoleBinding{}
+	bindings := authorization.Filter(mf.ByKind("RoleBinding"))
+	if len(bindings.Resources()) != 1 {
+		t.Fatalf("Got %d RoleBindings, want 1", len(bindings.Resources()))
+	}
+	if err := scheme.Scheme.Convert(&bindings.Resources()[0], binding, nil); err != nil {
+		t.Fatal("Unable to convert to RoleBinding", err)
+	}
+	if binding.Namespace != servingNamespace || !cmp.Equal(binding.Subjects, proxy.AllowedServiceAccounts) {
+		t.Errorf("Got RoleBinding %v, want the allowed service accounts in %s", binding, servingNamespace)
+	}
+
+	manifest, err := mf.NewManifest("../testdata/serving-core-deployment.yaml")
+	if err != nil {
+		t.Fatal("Unable to load test manifest", err)
+	}
+	if manifest, err = manifest.Transform(InjectRbacProxyContainerToDeployments(Components{
+		"activator": {Name: "activator", MetricsPort: "9090"},
+	}, proxy)); err != nil {
+		t.Fatal("Unable to transform test manifest", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := scheme.Scheme.Convert(&manifest.Resources()[0], deployment, nil); err != nil {
+		t.Fatal("Unable to convert to Deployment", err)
+	}
+	rbacContainer := deployment.Spec.Template.Spec.Containers[1]
+	if !sets.NewString(rbacContainer.Args...).Has("--config-file=/etc/kube-rbac-proxy/config.yaml") {
+		t.Errorf("Got args %v, want the authorization config", rbacContainer.Args)
+	}
+	if len(deployment.Spec.Template.Spec.Volumes) != 3 {
+		t.Errorf("Got %d volumes, want 3", len(deployment.Spec.Template.Spec.Volumes))
+	}
+}
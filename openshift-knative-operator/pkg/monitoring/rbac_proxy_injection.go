@@ -8,10 +8,9 @@ import (
 	mf "github.com/manifestival/manifestival"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/pkg/ptr"
 )
 
 const (
@@ -19,57 +18,97 @@ const (
 	rbacProxyImageEnvVar = "IMAGE_KUBE_RBAC_PROXY"
 )
 
-func InjectRbacProxyContainerToDeployments(deployments sets.String) mf.Transformer {
+// InjectRbacProxyContainerToDeployments adds the rbac-proxy sidecar to the deployments of the
+// given components and makes the components export their metrics only locally. The sidecar,
+// its volumes and the env var are upserted by name, so applying the transformer again or to
+// a manifest that already carries any of them doesn't duplicate them.
+func InjectRbacProxyContainerToDeployments(components Components, proxy RbacProxyConfig) mf.Transformer {
 	return func(u *unstructured.Unstructured) error {
 		kind := strings.ToLower(u.GetKind())
 		// Only touch the related deployments
-		if kind == "deployment" && deployments.Has(u.GetName()) {
+		if component, ok := components[u.GetName()]; ok && kind == "deployment" {
 			var dep = &appsv1.Deployment{}
 			if err := scheme.Scheme.Convert(u, dep, nil); err != nil {
 				return err
 			}
 			depName := u.GetName()
-			firstContainer := &dep.Spec.Template.Spec.Containers[0]
+			container, err := component.container(dep)
+			if err != nil {
+				return err
+			}
 			// Make sure we export metrics only locally
-			firstContainer.Env = append(firstContainer.Env, corev1.EnvVar{Name: "METRICS_PROMETHEUS_HOST", Value: "127.0.0.1"})
-			// Order is important here as there is an assumption elsewhere about the first container being the component one
-			dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers, makeRbacProxyContainer(depName, getDefaultMetricsPort(u.GetName())))
-			dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, []corev1.Volume{{
+			container.Env = upsertEnv(container.Env, corev1.EnvVar{Name: "METRICS_PROMETHEUS_HOST", Value: "127.0.0.1"})
+
+			podSpec := &dep.Spec.Template.Spec
+			podSpec.Containers = upsertContainer(podSpec.Containers, makeRbacProxyContainer(depName, component.MetricsPort, proxy))
+			podSpec.Volumes = upsertVolume(podSpec.Volumes, corev1.Volume{
 				Name: fmt.Sprintf("secret-%s-sm-service-tls", depName),
 				VolumeSource: corev1.VolumeSource{
 					Secret: &corev1.SecretVolumeSource{
 						SecretName: fmt.Sprintf("%s-sm-service-tls", depName),
 					},
 				},
-			}}...)
+			})
+			if len(proxy.AllowedServiceAccounts) > 0 {
+				podSpec.Volumes = upsertVolume(podSpec.Volumes, corev1.Volume{
+					Name: rbacProxyConfigName,
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: rbacProxyConfigName},
+						},
+					},
+				})
+			} else {
+				podSpec.Volumes = removeVolume(podSpec.Volumes, rbacProxyConfigName)
+			}
 			return scheme.Scheme.Convert(dep, u, nil)
 		}
 		return nil
 	}
 }
 
-func makeRbacProxyContainer(depName string, prometheusPort string) corev1.Container {
-	return corev1.Container{
+func makeRbacProxyContainer(depName string, prometheusPort string, proxy RbacProxyConfig) corev1.Container {
+	container := corev1.Container{
 		Name:  rbacContainerName,
 		Image: getRbacProxyImage(),
 		VolumeMounts: []corev1.VolumeMount{{
 			Name:      fmt.Sprintf("secret-%s-sm-service-tls", depName),
 			MountPath: "/etc/tls/private",
 		}},
-		Resources: corev1.ResourceRequirements{
-			Requests: corev1.ResourceList{
-				"memory": resource.MustParse("20Mi"),
-				"cpu":    resource.MustParse("10m"),
-			}},
+		Resources: *proxy.Resources.DeepCopy(),
 		Args: []string{
 			"--secure-listen-address=0.0.0.0:8444",
 			fmt.Sprintf("--upstream=http://127.0.0.1:%s/", prometheusPort),
 			"--tls-cert-file=/etc/tls/private/tls.crt",
 			"--tls-private-key-file=/etc/tls/private/tls.key",
 			"--logtostderr=true",
-			"--v=10",
+			fmt.Sprintf("--v=%d", proxy.LogLevel),
+		},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.Bool(false),
+			ReadOnlyRootFilesystem:   ptr.Bool(true),
+			RunAsNonRoot:             ptr.Bool(true),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
 		},
 	}
+	if len(proxy.CipherSuites) > 0 {
+		container.Args = append(container.Args, "--tls-cipher-suites="+strings.Join(proxy.CipherSuites, ","))
+	}
+	if proxy.MinTLSVersion != "" {
+		container.Args = append(container.Args, "--tls-min-version="+proxy.MinTLSVersion)
+	}
+	// Only scrapes by the allowed service accounts are authorized, see rbacProxyAuthorizationManifest.
+	if len(proxy.AllowedServiceAccounts) > 0 {
+		container.Args = append(container.Args, fmt.Sprintf("--config-file=%s/%s", rbacProxyConfigMountPath, rbacProxyConfigFile))
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      rbacProxyConfigName,
+			MountPath: rbacProxyConfigMountPath,
+			ReadOnly:  true,
+		})
+	}
+	return container
 }
 
 func getRbacProxyImage() string {
@@ -78,3 +117,46 @@ func getRbacProxyImage() string {
 	image := os.Getenv(rbacProxyImageEnvVar)
 	return image
 }
+
+// upsertEnv replaces the env var with the same name or appends the given one.
+func upsertEnv(env []corev1.EnvVar, val corev1.EnvVar) []corev1.EnvVar {
+	for i := range env {
+		if env[i].Name == val.Name {
+			env[i] = val
+			return env
+		}
+	}
+	return append(env, val)
+}
+
+// upsertContainer replaces the container with the same name or appends the given one.
+func upsertContainer(containers []corev1.Container, container corev1.Container) []corev1.Container {
+	for i := range containers {
+		if containers[i].Name == container.Name {
+			containers[i] = container
+			return containers
+		}
+	}
+	return append(containers, container)
+}
+
+// upsertVolume replaces the volume with the same name or appends the given one.
+func upsertVolume(volumes []corev1.Volume, volume corev1.Volume) []corev1.Volume {
+	for i := range volumes {
+		if volumes[i].Name == volume.Name {
+			volumes[i] = volume
+			return volumes
+		}
+	}
+	return append(volumes, volume)
+}
+
+// removeVolume removes the volume with the given name, if any.
+func removeVolume(volumes []corev1.Volume, name string) []corev1.Volume {
+	for i := range volumes {
+		if volumes[i].Name == name {
+			return append(volumes[:i], volumes[i+1:]...)
+		}
+	}
+	return volumes
+}
@@ -5,9 +5,13 @@ import (
 	"os"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	mf "github.com/manifestival/manifestival"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
 )
@@ -17,18 +21,24 @@ func TestInjectRbacProxyContainerToDeployments(t *testing.T) {
 	rbacImage := "registry.ci.openshift.org/origin/4.7:kube-rbac-proxy"
 	os.Setenv(rbacProxyImageEnvVar, rbacImage)
 	if err != nil {
-		t.Errorf("Unable to load test manifest: %w", err)
+		t.Errorf("Unable to load test manifest: %v", err)
 	}
-	transforms := []mf.Transformer{InjectRbacProxyContainerToDeployments(servingDeployments)}
+	proxy, err := RbacProxyConfigFrom(Spec{})
+	if err != nil {
+		t.Fatal("RbacProxyConfigFrom() =", err)
+	}
+	transforms := []mf.Transformer{InjectRbacProxyContainerToDeployments(Components{
+		"activator": {Name: "activator", MetricsPort: "9090"},
+	}, proxy)}
 	if manifest, err = manifest.Transform(transforms...); err != nil {
-		t.Errorf("Unable to transform test manifest: %w", err)
+		t.Errorf("Unable to transform test manifest: %v", err)
 	}
 	if len(manifest.Resources()) != 1 {
 		t.Errorf("Got %d, want %d", len(manifest.Resources()), 1)
 	}
 	deployment := &appsv1.Deployment{}
 	if err := scheme.Scheme.Convert(&manifest.Resources()[0], deployment, nil); err != nil {
-		t.Errorf("Unable to convert to deployment %w", err)
+		t.Errorf("Unable to convert to deployment %v", err)
 	}
 	// Make sure we respect existing volumes (eg. controller gets extra volumes due to custom certs)
 	if len(deployment.Spec.Template.Spec.Volumes) != 2 {
@@ -49,7 +59,14 @@ func TestInjectRbacProxyContainerToDeployments(t *testing.T) {
 	if len(rbacContainer.Resources.Requests) != 2 {
 		t.Errorf("Got %q, want %q", len(rbacContainer.Resources.Requests), 2)
 	}
+	if !sets.NewString(rbacContainer.Args...).Has("--v=0") {
+		t.Errorf("Got args %v, want the default log level", rbacContainer.Args)
+	}
+	if sc := rbacContainer.SecurityContext; sc == nil || sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		t.Errorf("Got security context %v, want privilege escalation to be disallowed", sc)
+	}
 }
+
 func envToString(vars []v1.EnvVar) sets.String {
 	sVars := sets.String{}
 	for _, v := range vars {
@@ -57,3 +74,130 @@ func envToString(vars []v1.EnvVar) sets.String {
 	}
 	return sVars
 }
+
+func TestInjectRbacProxyContainerIsIdempotent(t *testing.T) {
+	component := Component{Name: "mt-broker-filter", MetricsPort: "9092", Container: "filter"}
+	tlsVolume := v1.Volume{
+		Name: "secret-mt-broker-filter-sm-service-tls",
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{SecretName: "mt-broker-filter-sm-service-tls"},
+		},
+	}
+	allowed := RbacProxyConfig{AllowedServiceAccounts: []rbacv1.Subject{{
+		Kind: rbacv1.ServiceAccountKind, Namespace: OpenshiftMonitoringNamespace, Name: "prometheus-k8s",
+	}}}
+
+	cases := []struct {
+		name        string
+		containers  []v1.Container
+		volumes     []v1.Volume
+		proxies     []RbacProxyConfig
+		wantVolumes []string
+		wantErr     bool
+	}{{
+		name:        "applied once",
+		containers:  []v1.Container{{Name: "filter"}},
+		proxies:     []RbacProxyConfig{{}},
+		wantVolumes: []string{tlsVolume.Name},
+	}, {
+		name:        "applied twice",
+		containers:  []v1.Container{{Name: "filter"}},
+		proxies:     []RbacProxyConfig{{}, {LogLevel: 4}},
+		wantVolumes: []string{tlsVolume.Name},
+	}, {
+		name: "already injected",
+		containers: []v1.Container{{
+			Name: "filter",
+			Env:  []v1.EnvVar{{Name: "METRICS_PROMETHEUS_HOST", Value: "0.0.0.0"}},
+		}, {
+			Name: rbacContainerName,
+			Args: []string{"--v=10"},
+		}},
+		volumes:     []v1.Volume{{Name: "config"}, tlsVolume},
+		proxies:     []RbacProxyConfig{{}},
+		wantVolumes: []string{"config", tlsVolume.Name},
+	}, {
+		name:        "component container is not the first",
+		containers:  []v1.Container{{Name: "istio-proxy"}, {Name: "filter"}},
+		proxies:     []RbacProxyConfig{{}},
+		wantVolumes: []string{tlsVolume.Name},
+	}, {
+		name:        "allowed service accounts",
+		containers:  []v1.Container{{Name: "filter"}},
+		proxies:     []RbacProxyConfig{allowed, allowed},
+		wantVolumes: []string{tlsVolume.Name, rbacProxyConfigName},
+	}, {
+		name:        "allowed service accounts removed",
+		containers:  []v1.Container{{Name: "filter"}},
+		proxies:     []RbacProxyConfig{allowed, {}},
+		wantVolumes: []string{tlsVolume.Name},
+	}, {
+		name:       "component container missing",
+		containers: []v1.Container{{Name: "ingress"}},
+		proxies:    []RbacProxyConfig{{}},
+		wantErr:    true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Name: component.Name},
+			}
+			dep.Spec.Template.Spec.Containers = c.containers
+			dep.Spec.Template.Spec.Volumes = c.volumes
+			u := &unstructured.Unstructured{}
+			if err := scheme.Scheme.Convert(dep, u, nil); err != nil {
+				t.Fatal("Unable to convert deployment", err)
+			}
+
+			var err error
+			for _, proxy := range c.proxies {
+				if err = InjectRbacProxyContainerToDeployments(Components{component.Name: component}, proxy)(u); err != nil {
+					break
+				}
+			}
+			if (err != nil) != c.wantErr {
+				t.Fatalf("InjectRbacProxyContainerToDeployments() = %v, wantErr: %v", err, c.wantErr)
+			}
+			if c.wantErr {
+				return
+			}
+
+			got := &appsv1.Deployment{}
+			if err := scheme.Scheme.Convert(u, got, nil); err != nil {
+				t.Fatal("Unable to convert to deployment", err)
+			}
+			podSpec := got.Spec.Template.Spec
+			proxies := 0
+			for _, container := range podSpec.Containers {
+				switch container.Name {
+				case rbacContainerName:
+					proxies++
+					last := c.proxies[len(c.proxies)-1]
+					if want := fmt.Sprintf("--v=%d", last.LogLevel); !sets.NewString(container.Args...).Has(want) {
+						t.Errorf("Got args %v, want %s", container.Args, want)
+					}
+				case component.Container:
+					if got := envToString(container.Env); got.Len() != 1 || !got.Has("METRICS_PROMETHEUS_HOST:127.0.0.1") {
+						t.Errorf("Got env %v, want METRICS_PROMETHEUS_HOST once", got.List())
+					}
+				default:
+					if len(container.Env) != 0 {
+						t.Errorf("Got env %v on container %q, want it untouched", container.Env, container.Name)
+					}
+				}
+			}
+			if proxies != 1 {
+				t.Errorf("Got %d %s containers, want 1", proxies, rbacContainerName)
+			}
+			var volumes []string
+			for _, v := range podSpec.Volumes {
+				volumes = append(volumes, v.Name)
+			}
+			if !cmp.Equal(volumes, c.wantVolumes) {
+				t.Errorf("Got volumes %v, want: %v", volumes, c.wantVolumes)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package monitoring
+
+import (
+	"fmt"
+
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+const (
+	// SourceMonitoringKey selects how the eventing sources outside of the control plane's
+	// namespace are monitored.
+	SourceMonitoringKey = "source-monitoring"
+	// SourceMonitoringNamespaceSelectorKey is a label selector limiting the namespaces whose
+	// sources are monitored. Namespaces are excluded with a negative selector like
+	// "monitoring!=off". All namespaces are monitored if it's empty.
+	SourceMonitoringNamespaceSelectorKey = "source-monitoring-namespace-selector"
+
+	// SourceMonitoringUserWorkload scrapes the sources with OpenShift's user-workload monitoring,
+	// which has to be enabled on the cluster. The sources' namespaces are left untouched.
+	SourceMonitoringUserWorkload = "user-workload"
+	// SourceMonitoringPlatform scrapes the sources with the platform's monitoring, which
+	// requires opting their namespaces into cluster monitoring.
+	SourceMonitoringPlatform = "platform"
+)
+
+// SourceMonitoring configures the monitoring of eventing sources.
+type SourceMonitoring struct {
+	// Mode is the monitoring used for sources outside of the control plane's namespace.
+	Mode string
+	// Namespaces selects the namespaces whose sources are monitored.
+	Namespaces labels.Selector
+}
+
+// SourceMonitoringFrom parses the source monitoring settings of the given config.
+func SourceMonitoringFrom(config v1alpha1.ConfigMapData) (SourceMonitoring, error) {
+	settings := config[common.ExtensionConfigName]
+	sm := SourceMonitoring{Mode: SourceMonitoringUserWorkload, Namespaces: labels.Everything()}
+
+	switch mode := settings[SourceMonitoringKey]; mode {
+	case "":
+	case SourceMonitoringUserWorkload, SourceMonitoringPlatform:
+		sm.Mode = mode
+	default:
+		return SourceMonitoring{}, fmt.Errorf("invalid value %q for %q, must be one of %q or %q",
+			mode, SourceMonitoringKey, SourceMonitoringUserWorkload, SourceMonitoringPlatform)
+	}
+	if selector := settings[SourceMonitoringNamespaceSelectorKey]; selector != "" {
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return SourceMonitoring{}, fmt.Errorf("invalid value %q for %q: %w", selector, SourceMonitoringNamespaceSelectorKey, err)
+		}
+		sm.Namespaces = s
+	}
+	return sm, nil
+}
+
+// Monitors returns true if the sources of the given namespace are monitored. The sources of
+// the control plane's namespace are always monitored.
+func (s SourceMonitoring) Monitors(ns *corev1.Namespace, controlPlane string) bool {
+	if ns.DeletionTimestamp != nil {
+		return false
+	}
+	return ns.Name == controlPlane || s.Namespaces.Matches(labels.Set(ns.Labels))
+}
+
+// UsesPlatform returns true if the sources of the given namespace are scraped by the
+// platform's monitoring. The control plane's namespace is always monitored by the platform.
+func (s SourceMonitoring) UsesPlatform(ns, controlPlane string) bool {
+	return ns == controlPlane || s.Mode == SourceMonitoringPlatform
+}
@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestSourceMonitoring(t *testing.T) {
+	cases := []struct {
+		name         string
+		settings     map[string]string
+		namespace    *corev1.Namespace
+		wantMonitors bool
+		wantPlatform bool
+		wantErr      bool
+	}{{
+		name:         "user-workload by default",
+		namespace:    namespace("tenant", nil),
+		wantMonitors: true,
+	}, {
+		name:         "platform",
+		settings:     map[string]string{SourceMonitoringKey: SourceMonitoringPlatform},
+		namespace:    namespace("tenant", nil),
+		wantMonitors: true,
+		wantPlatform: true,
+	}, {
+		name:         "control plane",
+		settings:     map[string]string{SourceMonitoringNamespaceSelectorKey: "team=a"},
+		namespace:    namespace(eventingNamespace, nil),
+		wantMonitors: true,
+		wantPlatform: true,
+	}, {
+		name:         "allowed namespace",
+		settings:     map[string]string{SourceMonitoringNamespaceSelectorKey: "team in (a, b)"},
+		namespace:    namespace("tenant", map[string]string{"team": "a"}),
+		wantMonitors: true,
+	}, {
+		name:      "denied namespace",
+		settings:  map[string]string{SourceMonitoringNamespaceSelectorKey: "monitoring!=off"},
+		namespace: namespace("tenant", map[string]string{"monitoring": "off"}),
+	}, {
+		name:     "invalid mode",
+		settings: map[string]string{SourceMonitoringKey: "cluster"},
+		wantErr:  true,
+	}, {
+		name:     "invalid selector",
+		settings: map[string]string{SourceMonitoringNamespaceSelectorKey: "team in a"},
+		wantErr:  true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sm, err := SourceMonitoringFrom(v1alpha1.ConfigMapData{common.ExtensionConfigName: c.settings})
+			if (err != nil) != c.wantErr {
+				t.Fatalf("SourceMonitoringFrom() = %v, wantErr: %v", err, c.wantErr)
+			}
+			if c.wantErr {
+				return
+			}
+			if got := sm.Monitors(c.namespace, eventingNamespace); got != c.wantMonitors {
+				t.Errorf("Monitors() = %v, want: %v", got, c.wantMonitors)
+			}
+			if got := sm.UsesPlatform(c.namespace.Name, eventingNamespace); got != c.wantPlatform {
+				t.Errorf("UsesPlatform() = %v, want: %v", got, c.wantPlatform)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package monitoring
+
+import (
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Spec are the monitoring settings in spec.openshift.monitoring of KnativeServing and
+// KnativeEventing.
+type Spec struct {
+	// Alerts tunes the alerts, keyed by the name of the alert.
+	Alerts map[string]Alert `json:"alerts,omitempty"`
+	// Metrics selects where the metrics of the components are sent to.
+	Metrics Metrics `json:"metrics,omitempty"`
+	// RbacProxy configures the rbac-proxy sidecars exposing the scraped metrics.
+	RbacProxy RbacProxy `json:"rbacProxy,omitempty"`
+}
+
+// Metrics selects the metrics backends of the components.
+type Metrics struct {
+	// Backend is the metrics backend of all components. It takes precedence over the
+	// backend configured in the observability ConfigMap.
+	Backend string `json:"backend,omitempty"`
+	// DeploymentBackends overrides the metrics backend of single deployments, keyed by the
+	// name of the deployment.
+	DeploymentBackends map[string]string `json:"deploymentBackends,omitempty"`
+	// RequestBackend is the backend of Serving's request metrics. Pushing them to a
+	// collector while the other metrics are scraped lets both paths coexist.
+	RequestBackend string `json:"requestBackend,omitempty"`
+	// Collector is where the metrics are pushed to.
+	Collector Collector `json:"collector,omitempty"`
+}
+
+// Collector is a collector metrics are pushed to. Any collector accepting the OpenCensus
+// protocol works, including the OpenTelemetry collector with its opencensus receiver.
+type Collector struct {
+	// Address is the address of the collector.
+	Address string `json:"address,omitempty"`
+	// RequireTLS enables TLS towards the collector. The client certificates are read from
+	// the "opencensus" Secret in the component's namespace.
+	RequireTLS *bool `json:"requireTLS,omitempty"`
+}
+
+// Alert tunes a single alert.
+type Alert struct {
+	// Disabled disables the alert. Alerts based on an external exporter are disabled by
+	// default, as the operator can't tell whether the exporter is deployed.
+	Disabled *bool `json:"disabled,omitempty"`
+	// Threshold overrides the threshold of the alert.
+	Threshold *float64 `json:"threshold,omitempty"`
+	// For overrides how long the alert's condition must hold before it fires.
+	For string `json:"for,omitempty"`
+	// Severity overrides the severity label of the alert.
+	Severity string `json:"severity,omitempty"`
+}
+
+// RbacProxy configures the rbac-proxy sidecars.
+type RbacProxy struct {
+	// LogLevel is the log verbosity.
+	LogLevel int `json:"logLevel,omitempty"`
+	// Resources override the default resources of the sidecar containers.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// TLS overrides the TLS settings, which follow the cluster's APIServer TLS profile by
+	// default.
+	TLS RbacProxyTLS `json:"tls,omitempty"`
+	// AllowedServiceAccounts restricts scraping the metrics to the given service accounts.
+	// By default any service account allowed to get "/metrics" can scrape them, so
+	// Prometheus' own service account has to be listed too.
+	AllowedServiceAccounts []ServiceAccount `json:"allowedServiceAccounts,omitempty"`
+}
+
+// RbacProxyTLS are the TLS settings of the rbac-proxy sidecars.
+type RbacProxyTLS struct {
+	// CipherSuites are the IANA names of the accepted cipher suites.
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+	// MinVersion is the minimal TLS version, one of VersionTLS10, VersionTLS11, VersionTLS12
+	// or VersionTLS13.
+	MinVersion string `json:"minVersion,omitempty"`
+}
+
+// ServiceAccount references a service account.
+type ServiceAccount struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// SpecFrom reads the monitoring settings from the given unstructured KnativeServing or
+// KnativeEventing.
+func SpecFrom(u *unstructured.Unstructured) (Spec, error) {
+	ext := struct {
+		Monitoring Spec `json:"monitoring,omitempty"`
+	}{}
+	err := common.SpecExtensionFrom(u, &ext)
+	return ext.Monitoring, err
+}
@@ -0,0 +1,39 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/pkg/ptr"
+)
+
+func TestSpecFrom(t *testing.T) {
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON([]byte(`{
+		"apiVersion": "operator.knative.dev/v1alpha1",
+		"kind": "KnativeEventing",
+		"metadata": {"name": "knative-eventing"},
+		"spec": {"openshift": {"monitoring": {
+			"alerts": {"KnativeKafkaDispatcherConsumerLagHigh": {"disabled": false, "threshold": 5000}},
+			"rbacProxy": {"logLevel": 2}
+		}}}
+	}`)); err != nil {
+		t.Fatal("Unable to decode KnativeEventing", err)
+	}
+
+	spec, err := SpecFrom(u)
+	if err != nil {
+		t.Fatal("SpecFrom() =", err)
+	}
+	expected := Spec{
+		Alerts: map[string]Alert{"KnativeKafkaDispatcherConsumerLagHigh": {
+			Disabled:  ptr.Bool(false),
+			Threshold: ptr.Float64(5000),
+		}},
+		RbacProxy: RbacProxy{LogLevel: 2},
+	}
+	if !cmp.Equal(spec, expected) {
+		t.Errorf("Got = %v, want: %v, diff: %s", spec, expected, cmp.Diff(spec, expected))
+	}
+}
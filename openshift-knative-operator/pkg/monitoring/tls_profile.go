@@ -0,0 +1,113 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/logging"
+)
+
+// apiServers is the resource carrying the cluster's TLS profile.
+var apiServers = schema.GroupVersionResource{
+	Group:    "config.openshift.io",
+	Version:  "v1",
+	Resource: "apiservers",
+}
+
+func init() {
+	injection.Default.RegisterInformer(WithTLSProfile)
+}
+
+// tlsProfileKey is used as the key for associating the TLSProfile with a context.
+type tlsProfileKey struct{}
+
+// WithTLSProfile adds the TLSProfile, shared by all controllers, to the context. It's an
+// informer itself and is started along with the other informers.
+func WithTLSProfile(ctx context.Context) (context.Context, controller.Informer) {
+	p := NewTLSProfile(ctx, dynamicclient.Get(ctx))
+	return context.WithValue(ctx, tlsProfileKey{}, p), p
+}
+
+// GetTLSProfile extracts the TLSProfile from the context.
+func GetTLSProfile(ctx context.Context) *TLSProfile {
+	untyped := ctx.Value(tlsProfileKey{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic("Unable to fetch *monitoring.TLSProfile from context.")
+	}
+	return untyped.(*TLSProfile)
+}
+
+// TLSProfile serves the TLS profile of the cluster's APIServer out of an informer's cache.
+type TLSProfile struct {
+	informer cache.SharedIndexInformer
+}
+
+// NewTLSProfile creates a TLSProfile, whose informer lists and watches with the given
+// context and client. Clusters without an APIServer config have no TLS profile.
+func NewTLSProfile(ctx context.Context, dyn dynamic.Interface) *TLSProfile {
+	resource := dyn.Resource(apiServers)
+	selector := fields.OneTermEqualSelector("metadata.name", "cluster").String()
+	return &TLSProfile{
+		informer: cache.NewSharedIndexInformer(&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				opts.FieldSelector = selector
+				list, err := resource.List(ctx, opts)
+				if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+					return &unstructured.UnstructuredList{}, nil
+				}
+				return list, err
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				opts.FieldSelector = selector
+				return resource.Watch(ctx, opts)
+			},
+		}, &unstructured.Unstructured{}, 0, cache.Indexers{}),
+	}
+}
+
+// Run starts the informer until the given channel is closed.
+func (p *TLSProfile) Run(stopCh <-chan struct{}) {
+	p.informer.Run(stopCh)
+}
+
+// HasSynced returns true once the informer has synced.
+func (p *TLSProfile) HasSynced() bool {
+	return p.informer.HasSynced()
+}
+
+// Get returns the TLS profile of the cluster's APIServer, waiting for the informer to sync
+// until the context is done. It's nil if it's not set or the cluster doesn't have one.
+func (p *TLSProfile) Get(ctx context.Context) (*configv1.TLSSecurityProfile, error) {
+	if !cache.WaitForCacheSync(ctx.Done(), p.HasSynced) {
+		return nil, fmt.Errorf("failed to wait for the APIServer config cache to sync")
+	}
+	for _, obj := range p.informer.GetStore().List() {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			return TLSProfileFrom(u)
+		}
+	}
+	return nil, nil
+}
+
+// TLSProfileFrom returns the TLS profile of the given APIServer config.
+func TLSProfileFrom(u *unstructured.Unstructured) (*configv1.TLSSecurityProfile, error) {
+	apiServer := &configv1.APIServer{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, apiServer); err != nil {
+		return nil, fmt.Errorf("failed to convert the APIServer config: %w", err)
+	}
+	return apiServer.Spec.TLSSecurityProfile, nil
+}
@@ -0,0 +1,60 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestTLSProfile(t *testing.T) {
+	apiServer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "config.openshift.io/v1",
+		"kind":       "APIServer",
+		"metadata": map[string]interface{}{
+			"name": "cluster",
+		},
+		"spec": map[string]interface{}{
+			"tlsSecurityProfile": map[string]interface{}{
+				"type":   "Modern",
+				"modern": map[string]interface{}{},
+			},
+		},
+	}}
+
+	cases := []struct {
+		name     string
+		objs     []runtime.Object
+		expected *configv1.TLSSecurityProfile
+	}{{
+		name: "no APIServer config",
+	}, {
+		name: "profile set",
+		objs: []runtime.Object{apiServer},
+		expected: &configv1.TLSSecurityProfile{
+			Type:   configv1.TLSProfileModernType,
+			Modern: &configv1.ModernTLSProfile{},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			profile := NewTLSProfile(ctx, dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), c.objs...))
+			go profile.Run(ctx.Done())
+
+			got, err := profile.Get(ctx)
+			if err != nil {
+				t.Fatal("Get() =", err)
+			}
+			if !cmp.Equal(got, c.expected) {
+				t.Errorf("Got = %v, want: %v, diff: %s", got, c.expected, cmp.Diff(got, c.expected))
+			}
+		})
+	}
+}
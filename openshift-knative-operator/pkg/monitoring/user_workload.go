@@ -0,0 +1,145 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mf "github.com/manifestival/manifestival"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+const (
+	// UserWorkloadMonitoringKey enables monitoring the Knative Services of tenant namespaces
+	// with OpenShift's user-workload monitoring, which has to be enabled on the cluster.
+	UserWorkloadMonitoringKey = "user-workload-monitoring"
+	// UserWorkloadMonitoringNamespaceSelectorKey is a label selector limiting the monitored
+	// namespaces. All namespaces are monitored if it's empty.
+	UserWorkloadMonitoringNamespaceSelectorKey = "user-workload-monitoring-namespace-selector"
+
+	// UserWorkloadMonitorLabel marks the monitors created for user-workload monitoring, so only
+	// those are removed again.
+	UserWorkloadMonitorLabel = "serving.knative.openshift.io/user-workload-monitor"
+
+	// QueueProxyMonitorName is the name of the PodMonitor scraping the request metrics of the
+	// revisions' queue-proxies.
+	QueueProxyMonitorName = "knative-queue-proxy"
+	// UserContainerMonitorName is the name of the PodMonitor scraping the revisions' user
+	// containers, which opt in with the "prometheus.io/scrape" annotation. The port and path
+	// default to the user port and "/metrics" and can be changed with the "prometheus.io/port"
+	// and "prometheus.io/path" annotations.
+	UserContainerMonitorName = "knative-user-container"
+)
+
+// UserWorkloadMonitoring configures the monitoring of Knative Services in tenant namespaces.
+type UserWorkloadMonitoring struct {
+	// Enabled is true if the Knative Services are monitored.
+	Enabled bool
+	// Namespaces selects the monitored namespaces.
+	Namespaces labels.Selector
+}
+
+// UserWorkloadMonitoringFrom parses the user-workload monitoring settings of the given config.
+func UserWorkloadMonitoringFrom(config v1alpha1.ConfigMapData) (UserWorkloadMonitoring, error) {
+	settings := config[common.ExtensionConfigName]
+	uwm := UserWorkloadMonitoring{Namespaces: labels.Everything()}
+
+	if enabled, ok := settings[UserWorkloadMonitoringKey]; ok {
+		v, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return UserWorkloadMonitoring{}, fmt.Errorf("invalid value %q for %q: %w", enabled, UserWorkloadMonitoringKey, err)
+		}
+		uwm.Enabled = v
+	}
+	if selector := settings[UserWorkloadMonitoringNamespaceSelectorKey]; selector != "" {
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return UserWorkloadMonitoring{}, fmt.Errorf("invalid value %q for %q: %w", selector, UserWorkloadMonitoringNamespaceSelectorKey, err)
+		}
+		uwm.Namespaces = s
+	}
+	return uwm, nil
+}
+
+// Monitors returns true if the Knative Services of the given namespace are monitored.
+// Namespaces monitored by the platform's monitoring are left out, as user-workload
+// monitoring ignores them.
+func (u UserWorkloadMonitoring) Monitors(ns *corev1.Namespace) bool {
+	if !u.Enabled || ns.DeletionTimestamp != nil {
+		return false
+	}
+	if ns.Labels[EnableMonitoringLabel] == "true" || strings.HasPrefix(ns.Name, "openshift-") || strings.HasPrefix(ns.Name, "kube-") {
+		return false
+	}
+	return u.Namespaces.Matches(labels.Set(ns.Labels))
+}
+
+// UserWorkloadMonitorsManifest returns the PodMonitors scraping the queue-proxies and the
+// opted-in user containers of the revisions in the given namespace.
+func UserWorkloadMonitorsManifest(ns string) (mf.Manifest, error) {
+	revisionPods := metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      serving.RevisionLabelKey,
+			Operator: metav1.LabelSelectorOpExists,
+		}},
+	}
+	queueProxy := userWorkloadMonitor(QueueProxyMonitorName, ns, revisionPods, monitoringv1.PodMetricsEndpoint{
+		Port: servingv1.UserQueueMetricsPortName,
+	})
+	userContainer := userWorkloadMonitor(UserContainerMonitorName, ns, revisionPods, monitoringv1.PodMetricsEndpoint{
+		Port: servingv1.UserPortName,
+		Path: "/metrics",
+		RelabelConfigs: []*monitoringv1.RelabelConfig{{
+			SourceLabels: []string{"__meta_kubernetes_pod_annotation_prometheus_io_scrape"},
+			Regex:        "true",
+			Action:       "keep",
+		}, {
+			SourceLabels: []string{"__meta_kubernetes_pod_annotation_prometheus_io_path"},
+			Regex:        "(.+)",
+			TargetLabel:  "__metrics_path__",
+		}, {
+			SourceLabels: []string{"__address__", "__meta_kubernetes_pod_annotation_prometheus_io_port"},
+			Regex:        `([^:]+)(?::\d+)?;(\d+)`,
+			Replacement:  "$1:$2",
+			TargetLabel:  "__address__",
+		}},
+	})
+
+	resources := make([]unstructured.Unstructured, 0, 2)
+	for _, monitor := range []*monitoringv1.PodMonitor{queueProxy, userContainer} {
+		u := unstructured.Unstructured{}
+		if err := scheme.Scheme.Convert(monitor, &u, nil); err != nil {
+			return mf.Manifest{}, err
+		}
+		resources = append(resources, u)
+	}
+	return mf.ManifestFrom(mf.Slice(resources))
+}
+
+func userWorkloadMonitor(name, ns string, selector metav1.LabelSelector, endpoint monitoringv1.PodMetricsEndpoint) *monitoringv1.PodMonitor {
+	return &monitoringv1.PodMonitor{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: monitoringv1.SchemeGroupVersion.String(),
+			Kind:       monitoringv1.PodMonitorsKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    map[string]string{UserWorkloadMonitorLabel: "true"},
+		},
+		Spec: monitoringv1.PodMonitorSpec{
+			Selector:            selector,
+			NamespaceSelector:   monitoringv1.NamespaceSelector{MatchNames: []string{ns}},
+			PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{endpoint},
+		},
+	}
+}
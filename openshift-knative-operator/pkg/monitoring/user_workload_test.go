@@ -0,0 +1,109 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestUserWorkloadMonitoring(t *testing.T) {
+	cases := []struct {
+		name      string
+		settings  map[string]string
+		namespace *corev1.Namespace
+		expected  bool
+		wantErr   bool
+	}{{
+		name:      "disabled by default",
+		namespace: namespace("tenant", nil),
+	}, {
+		name:      "enabled",
+		settings:  map[string]string{UserWorkloadMonitoringKey: "true"},
+		namespace: namespace("tenant", nil),
+		expected:  true,
+	}, {
+		name: "matching selector",
+		settings: map[string]string{
+			UserWorkloadMonitoringKey:                  "true",
+			UserWorkloadMonitoringNamespaceSelectorKey: "team in (a, b)",
+		},
+		namespace: namespace("tenant", map[string]string{"team": "a"}),
+		expected:  true,
+	}, {
+		name: "selector not matching",
+		settings: map[string]string{
+			UserWorkloadMonitoringKey:                  "true",
+			UserWorkloadMonitoringNamespaceSelectorKey: "team in (a, b)",
+		},
+		namespace: namespace("tenant", map[string]string{"team": "c"}),
+	}, {
+		name:      "platform monitored namespace",
+		settings:  map[string]string{UserWorkloadMonitoringKey: "true"},
+		namespace: namespace("tenant", map[string]string{EnableMonitoringLabel: "true"}),
+	}, {
+		name:      "openshift namespace",
+		settings:  map[string]string{UserWorkloadMonitoringKey: "true"},
+		namespace: namespace("openshift-console", nil),
+	}, {
+		name:     "invalid toggle",
+		settings: map[string]string{UserWorkloadMonitoringKey: "sometimes"},
+		wantErr:  true,
+	}, {
+		name: "invalid selector",
+		settings: map[string]string{
+			UserWorkloadMonitoringKey:                  "true",
+			UserWorkloadMonitoringNamespaceSelectorKey: "team in a",
+		},
+		wantErr: true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			uwm, err := UserWorkloadMonitoringFrom(v1alpha1.ConfigMapData{common.ExtensionConfigName: c.settings})
+			if (err != nil) != c.wantErr {
+				t.Fatalf("UserWorkloadMonitoringFrom() = %v, wantErr: %v", err, c.wantErr)
+			}
+			if c.wantErr {
+				return
+			}
+			if got := uwm.Monitors(c.namespace); got != c.expected {
+				t.Errorf("Monitors() = %v, want: %v", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestUserWorkloadMonitorsManifest(t *testing.T) {
+	manifest, err := UserWorkloadMonitorsManifest("tenant")
+	if err != nil {
+		t.Fatal("UserWorkloadMonitorsManifest() =", err)
+	}
+	ports := map[string]string{
+		QueueProxyMonitorName:    "http-usermetric",
+		UserContainerMonitorName: "user-port",
+	}
+	if len(manifest.Resources()) != len(ports) {
+		t.Fatalf("Got %d resources, want %d", len(manifest.Resources()), len(ports))
+	}
+	for i := range manifest.Resources() {
+		monitor := &monitoringv1.PodMonitor{}
+		if err := scheme.Scheme.Convert(&manifest.Resources()[i], monitor, nil); err != nil {
+			t.Fatal("Unable to convert to PodMonitor", err)
+		}
+		if monitor.Namespace != "tenant" || monitor.Labels[UserWorkloadMonitorLabel] != "true" {
+			t.Errorf("Got PodMonitor %s/%s with labels %v, want it in tenant and labeled", monitor.Namespace, monitor.Name, monitor.Labels)
+		}
+		if got := monitor.Spec.PodMetricsEndpoints[0].Port; got != ports[monitor.Name] {
+			t.Errorf("Got port %q for %s, want %q", got, monitor.Name, ports[monitor.Name])
+		}
+	}
+}
+
+func namespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
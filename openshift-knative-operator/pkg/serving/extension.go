@@ -6,19 +6,23 @@ import (
 	"os"
 
 	mf "github.com/manifestival/manifestival"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/capabilities"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
 	"github.com/openshift-knative/serverless-operator/pkg/client/clientset/versioned"
 	ocpclient "github.com/openshift-knative/serverless-operator/pkg/client/injection/client"
+	"github.com/openshift-knative/serverless-operator/pkg/managed"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
 	operator "knative.dev/operator/pkg/reconciler/common"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/clients/dynamicclient"
 )
 
 const (
@@ -31,21 +35,39 @@ const (
 // NewExtension creates a new extension for a Knative Serving controller.
 func NewExtension(ctx context.Context) operator.Extension {
 	return &extension{
-		ocpclient:  ocpclient.Get(ctx),
-		kubeclient: kubeclient.Get(ctx),
+		ocpclient:    ocpclient.Get(ctx),
+		kubeclient:   kubeclient.Get(ctx),
+		capabilities: capabilities.Get(ctx),
+		mirrors:      common.GetImageMirrors(ctx),
+		tlsProfile:   monitoring.GetTLSProfile(ctx),
+		specs:        common.NewSpecExtensions(ctx, dynamicclient.Get(ctx), knativeServingGVR),
 	}
 }
 
 type extension struct {
-	ocpclient  versioned.Interface
-	kubeclient kubernetes.Interface
+	ocpclient    versioned.Interface
+	kubeclient   kubernetes.Interface
+	capabilities *capabilities.Detector
+	mirrors      *common.ImageMirrors
+	tlsProfile   *monitoring.TLSProfile
+	specs        *common.SpecExtensions
 }
 
 func (e *extension) Manifests(ks v1alpha1.KComponent) ([]mf.Manifest, error) {
-	return monitoring.GetServingMonitoringPlatformManifests(ks)
+	spec, err := e.spec(context.TODO(), ks)
+	if err != nil {
+		return nil, err
+	}
+	return monitoring.GetServingMonitoringPlatformManifests(ks, spec.Monitoring)
 }
 
 func (e *extension) Transformers(ks v1alpha1.KComponent) []mf.Transformer {
+	spec, err := e.spec(context.TODO(), ks)
+	if err != nil {
+		return []mf.Transformer{func(*unstructured.Unstructured) error {
+			return err
+		}}
+	}
 	return append([]mf.Transformer{
 		common.InjectEnvironmentIntoDeployment("controller", "controller",
 			corev1.EnvVar{Name: "HTTP_PROXY", Value: os.Getenv("HTTP_PROXY")},
@@ -53,11 +75,17 @@ func (e *extension) Transformers(ks v1alpha1.KComponent) []mf.Transformer {
 			corev1.EnvVar{Name: "NO_PROXY", Value: os.Getenv("NO_PROXY")},
 		),
 		overrideKourierNamespace(kourierNamespace(ks.GetNamespace())),
-	}, monitoring.GetServingTransformers(ks)...)
+		e.mirrors.Transform(ks),
+	}, monitoring.GetServingTransformers(context.TODO(), e.tlsProfile, ks, spec.Monitoring)...)
 }
 
 func (e *extension) Reconcile(ctx context.Context, comp v1alpha1.KComponent) error {
 	ks := comp.(*v1alpha1.KnativeServing)
+	user := ks.Spec.CommonSpec.DeepCopy()
+
+	// Surface optional cluster features so it's visible why a feature is missing.
+	caps := e.capabilities.Get(ctx)
+	caps.MarkConditions(&ks.Status, capabilities.Serving...)
 
 	// Make sure Knative Serving is always installed in the defined namespace.
 	requiredNs := os.Getenv(requiredNsEnvName)
@@ -66,6 +94,11 @@ func (e *extension) Reconcile(ctx context.Context, comp v1alpha1.KComponent) err
 		return controller.NewPermanentError(fmt.Errorf("deployed Knative Serving into unsupported namespace %q", ks.Namespace))
 	}
 
+	spec, err := e.spec(ctx, ks)
+	if err != nil {
+		return err
+	}
+
 	// Mark failed dependencies as succeeded since we're no longer using that mechanism anyway.
 	if ks.Status.GetCondition(v1alpha1.DependenciesInstalled).IsFalse() {
 		ks.Status.MarkDependenciesInstalled()
@@ -85,11 +118,25 @@ func (e *extension) Reconcile(ctx context.Context, comp v1alpha1.KComponent) err
 	}
 
 	// Override images.
-	// TODO(SRVCOM-1069): Rethink overriding behavior and/or error surfacing.
-	images := common.ImageMapFromEnvironment(os.Environ())
-	ks.Spec.Registry.Override = images
-	ks.Spec.Registry.Default = images["default"]
-	common.Configure(&ks.Spec.CommonSpec, "deployment", "queueSidecarImage", images["queue-proxy"])
+	images, err := common.ReconcileImages(ctx, e.kubeclient, ks, &ks.Spec.CommonSpec)
+	if err != nil {
+		return err
+	}
+	if qp := images["queue-proxy"]; qp != "" {
+		// The queue-proxy isn't part of the manifests, so it's not rewritten by the mirror
+		// transformer.
+		mirrors, err := e.mirrors.Get(ctx)
+		if err != nil {
+			return err
+		}
+		if mirrored, ok := mirrors.Rewrite(qp); ok {
+			qp = mirrored
+		}
+		common.Configure(&ks.Spec.CommonSpec, "deployment", "queueSidecarImage", qp)
+	}
+
+	// Keep the user's values of the managed fields they opted out of.
+	managed.Restore(ks, user, &ks.Spec.CommonSpec, managed.ServingFields)
 
 	// Default to 2 replicas.
 	if ks.Spec.HighAvailability == nil {
@@ -116,7 +163,7 @@ func (e *extension) Reconcile(ctx context.Context, comp v1alpha1.KComponent) err
 			Type: "ConfigMap",
 		}
 	}
-	return monitoring.ReconcileMonitoringForServing(ctx, e.kubeclient, ks)
+	return monitoring.ReconcileMonitoringForServing(ctx, e.kubeclient, ks, spec.Monitoring)
 }
 
 func (e *extension) Finalize(ctx context.Context, comp v1alpha1.KComponent) error {
@@ -132,7 +179,15 @@ func (e *extension) Finalize(ctx context.Context, comp v1alpha1.KComponent) erro
 	// Also default to Kourier here to pick the right manifest to uninstall.
 	defaultToKourier(ks)
 
-	return nil
+	// Remove cluster-scoped monitoring resources that aren't part of the installed manifest.
+	return monitoring.FinalizeMonitoringForServing(ctx, e.kubeclient, ks)
+}
+
+// spec returns the OpenShift specific settings of the given KnativeServing.
+func (e *extension) spec(ctx context.Context, ks v1alpha1.KComponent) (SpecExtension, error) {
+	spec := SpecExtension{}
+	err := e.specs.Get(ctx, ks, &spec)
+	return spec, err
 }
 
 // fetchClusterHost fetches the cluster's hostname from the cluster's ingress config.
@@ -8,18 +8,25 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/capabilities"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/common"
 	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
 	ocpfake "github.com/openshift-knative/serverless-operator/pkg/client/injection/client/fake"
+	"github.com/openshift-knative/serverless-operator/pkg/images"
+	"github.com/openshift-knative/serverless-operator/pkg/managed"
 	configv1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+	operator "knative.dev/operator/pkg/reconciler/common"
 	"knative.dev/pkg/apis"
+	apiextfake "knative.dev/pkg/client/injection/apiextensions/client/fake"
 	kubefake "knative.dev/pkg/client/injection/kube/client/fake"
+	dynamicfake "knative.dev/pkg/injection/clients/dynamicclient/fake"
 )
 
 var (
@@ -44,6 +51,7 @@ func init() {
 	os.Setenv("IMAGE_default", "bar2")
 	os.Setenv("IMAGE_queue-proxy", "baz")
 	os.Setenv(requiredNsEnvName, servingNamespace.Name)
+	os.Setenv(operator.KoEnvKey, "../../cmd/operator/kodata")
 }
 
 func TestReconcile(t *testing.T) {
@@ -113,6 +121,9 @@ func TestReconcile(t *testing.T) {
 		expected: ks(func(ks *v1alpha1.KnativeServing) {
 			common.Configure(&ks.Spec.CommonSpec, monitoring.ObservabilityCMName, "logging.revision-url-template",
 				fmt.Sprintf(loggingURLTemplate, "logging.example.com"))
+			capabilities.Set{
+				capabilities.LoggingAvailable: {Status: corev1.ConditionTrue},
+			}.MarkConditions(&ks.Status, capabilities.LoggingAvailable)
 		}),
 	}, {
 		name: "override image settings",
@@ -129,6 +140,37 @@ func TestReconcile(t *testing.T) {
 			},
 		},
 		expected: ks(),
+	}, {
+		name: "unmanaged image settings",
+		in: &v1alpha1.KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					managed.UnmanagedFieldsAnnotation: "spec.registry.override.foo, spec.config.deployment.queueSidecarImage",
+				},
+			},
+			Spec: v1alpha1.KnativeServingSpec{
+				CommonSpec: v1alpha1.CommonSpec{
+					Registry: v1alpha1.Registry{
+						Override: map[string]string{
+							"foo":         "mine",
+							"queue-proxy": "not",
+						},
+					},
+					Config: v1alpha1.ConfigMapData{
+						"deployment": map[string]string{
+							"queueSidecarImage": "mine",
+						},
+					},
+				},
+			},
+		},
+		expected: ks(func(ks *v1alpha1.KnativeServing) {
+			ks.Annotations = map[string]string{
+				managed.UnmanagedFieldsAnnotation: "spec.registry.override.foo, spec.config.deployment.queueSidecarImage",
+			}
+			ks.Spec.Registry.Override["foo"] = "mine"
+			common.Configure(&ks.Spec.CommonSpec, "deployment", "queueSidecarImage", "mine")
+		}),
 	}, {
 		name: "override ingress class",
 		in: &v1alpha1.KnativeServing{
@@ -241,6 +283,13 @@ func TestReconcile(t *testing.T) {
 			ks := c.in.DeepCopy()
 			ctx, _ := ocpfake.With(context.Background(), objs...)
 			ctx, _ = kubefake.With(ctx, &servingNamespace)
+			ctx, _ = apiextfake.With(ctx)
+			ctx, _ = dynamicfake.With(ctx, runtime.NewScheme())
+			ctx, _ = capabilities.With(ctx)
+			ctx, mirrors := common.WithImageMirrors(ctx)
+			go mirrors.Run(ctx.Done())
+			ctx, profile := monitoring.WithTLSProfile(ctx)
+			go profile.Run(ctx.Done())
 			ext := NewExtension(ctx)
 			ext.Reconcile(context.Background(), ks)
 			// Ignore time differences.
@@ -254,6 +303,51 @@ func TestReconcile(t *testing.T) {
 	}
 }
 
+func TestQueueProxyMirrored(t *testing.T) {
+	const (
+		image    = "registry.redhat.io/serving/queue@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+		mirrored = "mirror.example.com/redhat/serving/queue@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	)
+	os.Setenv("IMAGE_queue-proxy", image)
+	defer os.Setenv("IMAGE_queue-proxy", "baz")
+
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "operator.openshift.io/v1alpha1",
+		"kind":       "ImageContentSourcePolicy",
+		"metadata": map[string]interface{}{
+			"name": "mirrors",
+		},
+		"spec": map[string]interface{}{
+			"repositoryDigestMirrors": []interface{}{
+				map[string]interface{}{
+					"source":  "registry.redhat.io",
+					"mirrors": []interface{}{"mirror.example.com/redhat"},
+				},
+			},
+		},
+	}}
+
+	ks := &v1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: servingNamespace.Name},
+	}
+	ctx, _ := ocpfake.With(context.Background(), defaultIngress)
+	ctx, _ = kubefake.With(ctx, &servingNamespace)
+	ctx, _ = apiextfake.With(ctx)
+	ctx, _ = dynamicfake.With(ctx, runtime.NewScheme(), policy)
+	ctx, _ = capabilities.With(ctx)
+	ctx, mirrors := common.WithImageMirrors(ctx)
+	go mirrors.Run(ctx.Done())
+	ctx, profile := monitoring.WithTLSProfile(ctx)
+	go profile.Run(ctx.Done())
+	if err := NewExtension(ctx).Reconcile(context.Background(), ks); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+
+	if got := ks.Spec.Config["deployment"]["queueSidecarImage"]; got != mirrored {
+		t.Errorf("queueSidecarImage = %q, want %q", got, mirrored)
+	}
+}
+
 func TestMonitoring(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -370,11 +464,18 @@ func TestMonitoring(t *testing.T) {
 			c.expected.Namespace = ks.Namespace
 			ctx, _ := ocpfake.With(context.Background(), objs...)
 			ctx, kube := kubefake.With(ctx, &servingNamespace)
+			ctx, _ = apiextfake.With(ctx)
+			ctx, _ = dynamicfake.With(ctx, runtime.NewScheme())
+			ctx, _ = capabilities.With(ctx)
+			ctx, mirrors := common.WithImageMirrors(ctx)
+			go mirrors.Run(ctx.Done())
+			ctx, profile := monitoring.WithTLSProfile(ctx)
+			go profile.Run(ctx.Done())
 			ext := NewExtension(ctx)
 			shouldEnableMonitoring, err := c.setupMonitoringToggle()
 
 			if err != nil {
-				t.Errorf("Failed to setup the monitoring toggle %w", err)
+				t.Errorf("Failed to setup the monitoring toggle %v", err)
 			}
 			ext.Reconcile(context.Background(), ks)
 
@@ -396,6 +497,60 @@ func TestMonitoring(t *testing.T) {
 	}
 }
 
+func TestMonitoringSpecExtension(t *testing.T) {
+	ks := &v1alpha1.KnativeServing{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "KnativeServing"},
+		ObjectMeta: metav1.ObjectMeta{Name: "knative-serving", Namespace: servingNamespace.Name},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ks)
+	if err != nil {
+		t.Fatal("Failed to convert KnativeServing:", err)
+	}
+	stored := &unstructured.Unstructured{Object: obj}
+	if err := unstructured.SetNestedMap(stored.Object, map[string]interface{}{
+		"monitoring": map[string]interface{}{
+			"metrics": map[string]interface{}{
+				"backend": monitoring.BackendOpenCensus,
+				"collector": map[string]interface{}{
+					"address": "otel-collector.observability:55678",
+				},
+			},
+		},
+	}, "spec", common.SpecExtensionField); err != nil {
+		t.Fatal("Failed to set spec extension:", err)
+	}
+
+	ctx, _ := ocpfake.With(context.Background(), defaultIngress)
+	ctx, kube := kubefake.With(ctx, &servingNamespace)
+	ctx, _ = apiextfake.With(ctx)
+	ctx, _ = dynamicfake.With(ctx, runtime.NewScheme(), stored)
+	ctx, _ = capabilities.With(ctx)
+	ctx, mirrors := common.WithImageMirrors(ctx)
+	go mirrors.Run(ctx.Done())
+	ctx, profile := monitoring.WithTLSProfile(ctx)
+	go profile.Run(ctx.Done())
+	if err := NewExtension(ctx).Reconcile(context.Background(), ks); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+
+	want := map[string]string{
+		monitoring.ObservabilityBackendKey: monitoring.BackendOpenCensus,
+		"metrics.opencensus-address":       "otel-collector.observability:55678",
+	}
+	for key, value := range want {
+		if got := ks.Spec.Config[monitoring.ObservabilityCMName][key]; got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	ns, err := kube.CoreV1().Namespaces().Get(context.Background(), ks.Namespace, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get namespace:", err)
+	}
+	if got := ns.Labels[monitoring.EnableMonitoringLabel]; got != "false" {
+		t.Errorf("Got monitoring label %q, want %q", got, "false")
+	}
+}
+
 func ks(mods ...func(*v1alpha1.KnativeServing)) *v1alpha1.KnativeServing {
 	base := &v1alpha1.KnativeServing{
 		ObjectMeta: metav1.ObjectMeta{
@@ -447,6 +602,33 @@ func ks(mods ...func(*v1alpha1.KnativeServing)) *v1alpha1.KnativeServing {
 		},
 	}
 
+	// None of the optional capabilities are available in the fake cluster by default.
+	capabilities.Set{
+		capabilities.LoggingAvailable: {
+			Status:  corev1.ConditionFalse,
+			Reason:  "RouteNotFound",
+			Message: "Route openshift-logging/kibana not found",
+		},
+		capabilities.DashboardsAvailable: {
+			Status:  corev1.ConditionFalse,
+			Reason:  "NamespaceNotFound",
+			Message: `Namespace "openshift-config-managed" not found`,
+		},
+		capabilities.QuickStartsAvailable: {
+			Status:  corev1.ConditionFalse,
+			Reason:  "CRDNotFound",
+			Message: `CustomResourceDefinition "consolequickstarts.console.openshift.io" not found`,
+		},
+		capabilities.MonitoringAvailable: {
+			Status:  corev1.ConditionFalse,
+			Reason:  "CRDNotFound",
+			Message: `CustomResourceDefinition "servicemonitors.monitoring.coreos.com" not found`,
+		},
+	}.MarkConditions(&base.Status, capabilities.Serving...)
+	// The kodata manifests have no image named foo.
+	images.MarkCondition(&base.Status, nil, []string{"foo"})
+	monitoring.MarkComponentsDiscovered(&base.Status, nil)
+
 	for _, mod := range mods {
 		mod(base)
 	}
@@ -0,0 +1,19 @@
+package serving
+
+import (
+	"github.com/openshift-knative/serverless-operator/openshift-knative-operator/pkg/monitoring"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var knativeServingGVR = schema.GroupVersionResource{
+	Group:    "operator.knative.dev",
+	Version:  "v1alpha1",
+	Resource: "knativeservings",
+}
+
+// SpecExtension are the settings in spec.openshift of KnativeServing. They're not part
+// of the upstream type, so they are read from the unstructured resource.
+type SpecExtension struct {
+	// Monitoring configures the monitoring of the Serving components.
+	Monitoring monitoring.Spec `json:"monitoring,omitempty"`
+}
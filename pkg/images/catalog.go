@@ -0,0 +1,113 @@
+// Package images contains the catalog of image overrides shared by the operators. The
+// catalog is read from the operator's environment and can be amended by a ConfigMap.
+package images
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// Prefix is the prefix of the keys carrying Serving and Eventing images.
+	Prefix = "IMAGE_"
+	// KafkaPrefix is the prefix of the keys carrying KnativeKafka images.
+	KafkaPrefix = "KAFKA_IMAGE_"
+
+	// ConfigMapName is the name of the optional ConfigMap in the operator's namespace that
+	// amends the images from the environment. Its keys follow the format of the
+	// environment variables and take precedence over them.
+	ConfigMapName = "serverless-operator-images"
+
+	// DefaultKey is the key of the image used for all containers without an override.
+	DefaultKey = "default"
+)
+
+// Catalog maps containers and environment variables to images. Keys are either the name
+// of a container or environment variable, or prefixed with the name of the deployment
+// separated by a slash.
+type Catalog map[string]string
+
+// FromEnviron generates a catalog from the passed environment variables with the given
+// prefix.
+func FromEnviron(environ []string, prefix string) Catalog {
+	catalog := Catalog{}
+
+	for _, e := range environ {
+		pair := strings.SplitN(e, "=", 2)
+		if len(pair) != 2 || !strings.HasPrefix(pair[0], prefix) || pair[1] == "" {
+			continue
+		}
+
+		/*
+			converts:
+			IMAGE_container=foo             -> container: foo
+			IMAGE_deployment__container=foo -> deployment/container: foo
+			IMAGE_env_var=foo               -> env_var: foo
+			IMAGE_deployment__env_var=foo   -> deployment/env_var: foo
+		*/
+		name := strings.TrimPrefix(pair[0], prefix)
+		name = strings.Replace(name, "__", "/", 1)
+		catalog[name] = pair[1]
+	}
+	return catalog
+}
+
+// FromConfigMap generates a catalog from the keys with the given prefix in the passed
+// ConfigMap, which might be nil.
+func FromConfigMap(cm *corev1.ConfigMap, prefix string) Catalog {
+	if cm == nil {
+		return Catalog{}
+	}
+
+	environ := make([]string, 0, len(cm.Data))
+	for key, value := range cm.Data {
+		environ = append(environ, key+"="+value)
+	}
+	return FromEnviron(environ, prefix)
+}
+
+// Merge returns a new catalog with the overrides of all passed catalogs. Later catalogs
+// take precedence.
+func Merge(catalogs ...Catalog) Catalog {
+	merged := Catalog{}
+	for _, catalog := range catalogs {
+		for key, image := range catalog {
+			merged[key] = image
+		}
+	}
+	return merged
+}
+
+// Without returns a new catalog without the given keys.
+func (c Catalog) Without(keys []string) Catalog {
+	filtered := make(Catalog, len(c))
+	for key, image := range c {
+		filtered[key] = image
+	}
+	for _, key := range keys {
+		delete(filtered, key)
+	}
+	return filtered
+}
+
+// keys returns the sorted keys of the catalog.
+func (c Catalog) keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Resolve merges the catalog from the environment with the one from the ConfigMap, which
+// might be nil, and drops all overrides violating the ConfigMap's policy. The dropped
+// overrides are returned as problems. Installing without them falls back to the
+// manifest's images, so callers must fail on them, see PolicyError.
+func Resolve(environ []string, cm *corev1.ConfigMap, prefix string) (Catalog, []Problem) {
+	catalog := Merge(FromEnviron(environ, prefix), FromConfigMap(cm, prefix))
+	problems := catalog.Validate(PolicyFromConfigMap(cm))
+	return catalog.Without(Keys(problems)), problems
+}
@@ -0,0 +1,145 @@
+package images
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFromEnviron(t *testing.T) {
+	cases := []struct {
+		name     string
+		envMap   map[string]string
+		prefix   string
+		expected Catalog
+	}{{
+		name: "Simple container name",
+		envMap: map[string]string{
+			"IMAGE_foo": "quay.io/myimage",
+		},
+		prefix: Prefix,
+		expected: Catalog{
+			"foo": "quay.io/myimage",
+		},
+	}, {
+		name: "Simple env var",
+		envMap: map[string]string{
+			"IMAGE_CRONJOB_RA_IMAGE": "quay.io/myimage",
+		},
+		prefix: Prefix,
+		expected: Catalog{
+			"CRONJOB_RA_IMAGE": "quay.io/myimage",
+		},
+	}, {
+		name: "Simple env var with deployment name",
+		envMap: map[string]string{
+			"IMAGE_eventing-controller__CRONJOB_RA_IMAGE": "quay.io/myimage",
+		},
+		prefix: Prefix,
+		expected: Catalog{
+			"eventing-controller/CRONJOB_RA_IMAGE": "quay.io/myimage",
+		},
+	}, {
+		name: "Deployment+container name",
+		envMap: map[string]string{
+			"IMAGE_foo__bar": "quay.io/myimage",
+		},
+		prefix: Prefix,
+		expected: Catalog{
+			"foo/bar": "quay.io/myimage",
+		},
+	}, {
+		name: "Deployment+container and container name",
+		envMap: map[string]string{
+			"IMAGE_foo__bar": "quay.io/myimage1",
+			"IMAGE_bar":      "quay.io/myimage2",
+		},
+		prefix: Prefix,
+		expected: Catalog{
+			"foo/bar": "quay.io/myimage1",
+			"bar":     "quay.io/myimage2",
+		},
+	}, {
+		name: "Different prefix",
+		envMap: map[string]string{
+			"X_foo": "quay.io/myimage",
+		},
+		prefix:   Prefix,
+		expected: Catalog{},
+	}, {
+		name: "Kafka prefix",
+		envMap: map[string]string{
+			"IMAGE_foo":       "quay.io/myimage1",
+			"KAFKA_IMAGE_bar": "quay.io/myimage2",
+		},
+		prefix: KafkaPrefix,
+		expected: Catalog{
+			"bar": "quay.io/myimage2",
+		},
+	}, {
+		name: "No env var value",
+		envMap: map[string]string{
+			"IMAGE_foo": "",
+		},
+		prefix:   Prefix,
+		expected: Catalog{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			catalog := FromEnviron(environFromMap(c.envMap), c.prefix)
+
+			if !cmp.Equal(catalog, c.expected) {
+				t.Errorf("Got = %v, want: %v, diff:\n%s", catalog, c.expected, cmp.Diff(catalog, c.expected))
+			}
+		})
+	}
+}
+
+func TestFromConfigMap(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		Data: map[string]string{
+			"IMAGE_foo__bar":     "quay.io/myimage1",
+			"KAFKA_IMAGE_bar":    "quay.io/myimage2",
+			AllowedRegistriesKey: "quay.io",
+		},
+	}
+
+	if got, want := FromConfigMap(cm, Prefix), (Catalog{"foo/bar": "quay.io/myimage1"}); !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+	if got, want := FromConfigMap(cm, KafkaPrefix), (Catalog{"bar": "quay.io/myimage2"}); !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+	if got := FromConfigMap(nil, Prefix); len(got) != 0 {
+		t.Errorf("Got = %v, want an empty catalog", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	env := Catalog{"foo": "quay.io/env1", "bar": "quay.io/env2"}
+	cm := Catalog{"bar": "quay.io/cm"}
+
+	want := Catalog{"foo": "quay.io/env1", "bar": "quay.io/cm"}
+	if got := Merge(env, cm); !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+	if env["bar"] != "quay.io/env2" {
+		t.Error("Merge must not modify its inputs")
+	}
+
+	if got, want := Merge(env, cm).Without([]string{"foo"}), (Catalog{"bar": "quay.io/cm"}); !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+}
+
+func environFromMap(envMap map[string]string) []string {
+	e := make([]string, 0, len(envMap))
+	for k, v := range envMap {
+		e = append(e, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	return e
+}
@@ -0,0 +1,215 @@
+package images
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	mf "github.com/manifestival/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MirrorsKey is the key in the catalog's ConfigMap carrying mirror rules in the format
+	// of an ImageContentSourcePolicy's repositoryDigestMirrors.
+	MirrorsKey = "mirrors"
+
+	// Mirrored is a Condition listing the images that were rewritten to mirrors.
+	Mirrored apis.ConditionType = "ImagesMirrored"
+
+	// imageEnvSuffix is the suffix of environment variables carrying images.
+	imageEnvSuffix = "_IMAGE"
+)
+
+// Mirror maps a source repository to the repositories it's mirrored to.
+type Mirror struct {
+	Source  string   `json:"source"`
+	Mirrors []string `json:"mirrors,omitempty"`
+}
+
+// Mirrors are the rules to rewrite images to mirror registries. Like the container
+// runtime's handling of ImageContentSourcePolicies, only images pinned by digest are
+// rewritten, as only those are guaranteed to be identical in the mirror.
+type Mirrors []Mirror
+
+// MirrorsFromConfigMap reads the mirror rules from the passed ConfigMap, which might be nil.
+func MirrorsFromConfigMap(cm *corev1.ConfigMap) (Mirrors, error) {
+	if cm == nil || cm.Data[MirrorsKey] == "" {
+		return nil, nil
+	}
+
+	var mirrors Mirrors
+	if err := yaml.Unmarshal([]byte(cm.Data[MirrorsKey]), &mirrors); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", MirrorsKey, err)
+	}
+	return mirrors, nil
+}
+
+// MirrorsFromPolicies reads the mirror rules from the passed ImageContentSourcePolicies.
+func MirrorsFromPolicies(policies []unstructured.Unstructured) Mirrors {
+	var mirrors Mirrors
+	for _, policy := range policies {
+		rules, _, _ := unstructured.NestedSlice(policy.Object, "spec", "repositoryDigestMirrors")
+		for _, r := range rules {
+			rule, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			source, _, _ := unstructured.NestedString(rule, "source")
+			targets, _, _ := unstructured.NestedStringSlice(rule, "mirrors")
+			mirrors = append(mirrors, Mirror{Source: source, Mirrors: targets})
+		}
+	}
+	return mirrors
+}
+
+// Rewrite returns the image pulled from the first mirror of the most specific rule
+// matching the image's repository. Unlike the container runtime, which tries the mirrors
+// of a rule in order and falls back to the source, a rewritten image is only ever pulled
+// from the first mirror, so it must be the one that's always available.
+func (m Mirrors) Rewrite(image string) (string, bool) {
+	ref, err := name.NewDigest(image)
+	if err != nil {
+		return "", false
+	}
+	repository := ref.Context().Name()
+
+	var match *Mirror
+	for i := range m {
+		source := strings.TrimSuffix(m[i].Source, "/")
+		if len(m[i].Mirrors) == 0 || (repository != source && !strings.HasPrefix(repository, source+"/")) {
+			continue
+		}
+		if match == nil || len(source) > len(strings.TrimSuffix(match.Source, "/")) {
+			match = &m[i]
+		}
+	}
+	if match == nil {
+		return "", false
+	}
+
+	rest := strings.TrimPrefix(repository, strings.TrimSuffix(match.Source, "/"))
+	return strings.TrimSuffix(match.Mirrors[0], "/") + rest + "@" + ref.DigestStr(), true
+}
+
+// Transform returns a transformer rewriting the images of all containers and all
+// environment variables carrying images to their mirrors. The rewritten images are
+// reported on the given status.
+func (m Mirrors) Transform(status apis.ConditionsAccessor) mf.Transformer {
+	if len(m) == 0 {
+		markNoMirrors(status)
+		return func(*unstructured.Unstructured) error { return nil }
+	}
+
+	rewritten := map[string]string{}
+	MarkMirrored(status, rewritten)
+
+	return func(u *unstructured.Unstructured) error {
+		changed, err := rewritePodSpecs(u, func(image string) (string, bool) {
+			mirrored, ok := m.Rewrite(image)
+			if ok {
+				rewritten[image] = mirrored
+			}
+			return mirrored, ok
+		})
+		if changed {
+			MarkMirrored(status, rewritten)
+		}
+		return err
+	}
+}
+
+// MarkMirrored reports the given rewritten images on the status. The condition is
+// informational only and never affects readiness.
+func MarkMirrored(status apis.ConditionsAccessor, rewritten map[string]string) {
+	cond := apis.Condition{
+		Type:     Mirrored,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	}
+	if len(rewritten) > 0 {
+		sources := make([]string, 0, len(rewritten))
+		for source := range rewritten {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+
+		pairs := make([]string, 0, len(sources))
+		for _, source := range sources {
+			pairs = append(pairs, source+" -> "+rewritten[source])
+		}
+		cond.Reason = "Rewritten"
+		cond.Message = "images rewritten to mirrors: " + strings.Join(pairs, ", ")
+	}
+	condSet.Manage(status).SetCondition(cond)
+}
+
+// markNoMirrors reports on the status that there are no mirrors to rewrite images to.
+func markNoMirrors(status apis.ConditionsAccessor) {
+	condSet.Manage(status).SetCondition(apis.Condition{
+		Type:     Mirrored,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityInfo,
+		Reason:   "NoMirrorsConfigured",
+		Message:  "no image mirrors are configured",
+	})
+}
+
+// rewritePodSpecs rewrites the images of all containers and init containers in the
+// resource's PodSpec, as well as the values of their environment variables carrying
+// images. It returns whether anything was rewritten.
+func rewritePodSpecs(u *unstructured.Unstructured, rewrite func(string) (string, bool)) (bool, error) {
+	changed := false
+	for _, path := range podSpecPaths {
+		for _, field := range []string{"containers", "initContainers"} {
+			fieldPath := append(append([]string{}, path...), field)
+			containers, found, _ := unstructured.NestedSlice(u.Object, fieldPath...)
+			if !found {
+				continue
+			}
+
+			containersChanged := false
+			for _, c := range containers {
+				container, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if image, ok := container["image"].(string); ok {
+					if mirrored, ok := rewrite(image); ok {
+						container["image"] = mirrored
+						containersChanged = true
+					}
+				}
+
+				env, _ := container["env"].([]interface{})
+				for _, e := range env {
+					envVar, ok := e.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					name, _ := envVar["name"].(string)
+					value, _ := envVar["value"].(string)
+					if !strings.HasSuffix(name, imageEnvSuffix) || value == "" {
+						continue
+					}
+					if mirrored, ok := rewrite(value); ok {
+						envVar["value"] = mirrored
+						containersChanged = true
+					}
+				}
+			}
+
+			if containersChanged {
+				if err := unstructured.SetNestedSlice(u.Object, containers, fieldPath...); err != nil {
+					return changed, fmt.Errorf("failed to set %s: %w", strings.Join(fieldPath, "."), err)
+				}
+				changed = true
+			}
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,171 @@
+package images
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+var mirrors = Mirrors{{
+	Source:  "registry.redhat.io",
+	Mirrors: []string{"mirror.example.com/redhat"},
+}, {
+	Source:  "registry.redhat.io/openshift-serverless-1",
+	Mirrors: []string{"mirror.example.com/serverless", "other.example.com/serverless"},
+}, {
+	Source: "quay.io/no-mirrors",
+}}
+
+func TestMirrorsFromConfigMap(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		Data: map[string]string{
+			MirrorsKey: `
+- source: registry.redhat.io
+  mirrors:
+  - mirror.example.com/redhat
+`,
+		},
+	}
+
+	got, err := MirrorsFromConfigMap(cm)
+	if err != nil {
+		t.Fatal("MirrorsFromConfigMap() =", err)
+	}
+	want := Mirrors{{Source: "registry.redhat.io", Mirrors: []string{"mirror.example.com/redhat"}}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+
+	if _, err := MirrorsFromConfigMap(&corev1.ConfigMap{Data: map[string]string{MirrorsKey: "foo"}}); err == nil {
+		t.Error("Expected an error for an invalid value")
+	}
+	if got, err := MirrorsFromConfigMap(nil); err != nil || got != nil {
+		t.Errorf("Got = %v, %v, want no mirrors", got, err)
+	}
+}
+
+func TestMirrorsFromPolicies(t *testing.T) {
+	policy := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"repositoryDigestMirrors": []interface{}{
+				map[string]interface{}{
+					"source":  "registry.redhat.io",
+					"mirrors": []interface{}{"mirror.example.com/redhat"},
+				},
+			},
+		},
+	}}
+
+	want := Mirrors{{Source: "registry.redhat.io", Mirrors: []string{"mirror.example.com/redhat"}}}
+	if got := MirrorsFromPolicies([]unstructured.Unstructured{policy}); !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	cases := []struct {
+		name     string
+		image    string
+		expected string
+	}{{
+		name:     "registry match",
+		image:    "registry.redhat.io/other/foo@" + digest,
+		expected: "mirror.example.com/redhat/other/foo@" + digest,
+	}, {
+		name:     "most specific match",
+		image:    "registry.redhat.io/openshift-serverless-1/foo@" + digest,
+		expected: "mirror.example.com/serverless/foo@" + digest,
+	}, {
+		name:  "not pinned by digest",
+		image: "registry.redhat.io/openshift-serverless-1/foo:latest",
+	}, {
+		name:  "no match",
+		image: "quay.io/foo@" + digest,
+	}, {
+		name:  "partial name match",
+		image: "registry.redhat.io.example.com/foo@" + digest,
+	}, {
+		name:  "no mirrors",
+		image: "quay.io/no-mirrors/foo@" + digest,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := mirrors.Rewrite(c.image)
+			if got != c.expected || ok != (c.expected != "") {
+				t.Errorf("Rewrite() = %q, %v, want: %q", got, ok, c.expected)
+			}
+		})
+	}
+}
+
+func TestMirrorsTransform(t *testing.T) {
+	image := "registry.redhat.io/foo@" + digest
+	mirrored := "mirror.example.com/redhat/foo@" + digest
+
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"initContainers": []interface{}{
+						map[string]interface{}{"name": "init", "image": image},
+					},
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "controller",
+							"image": "quay.io/foo:latest",
+							"env": []interface{}{
+								map[string]interface{}{"name": "DISPATCHER_IMAGE", "value": image},
+								map[string]interface{}{"name": "OTHER", "value": image},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	status := &duckv1.Status{}
+	if err := mirrors.Transform(status)(u); err != nil {
+		t.Fatal("Transform() =", err)
+	}
+
+	initContainers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "initContainers")
+	if got := initContainers[0].(map[string]interface{})["image"]; got != mirrored {
+		t.Errorf("Init container image = %v, want: %v", got, mirrored)
+	}
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	container := containers[0].(map[string]interface{})
+	if got := container["image"]; got != "quay.io/foo:latest" {
+		t.Errorf("Container image = %v, want it unchanged", got)
+	}
+	env := container["env"].([]interface{})
+	if got := env[0].(map[string]interface{})["value"]; got != mirrored {
+		t.Errorf("Image env var = %v, want: %v", got, mirrored)
+	}
+	if got := env[1].(map[string]interface{})["value"]; got != image {
+		t.Errorf("Other env var = %v, want it unchanged", got)
+	}
+
+	cond := status.GetCondition(Mirrored)
+	want := "images rewritten to mirrors: " + image + " -> " + mirrored
+	if cond == nil || !cond.IsTrue() || cond.Message != want {
+		t.Errorf("Condition = %v, want a message %q", cond, want)
+	}
+}
+
+func TestMirrorsTransformWithoutMirrors(t *testing.T) {
+	status := &duckv1.Status{}
+	if err := Mirrors(nil).Transform(status)(&unstructured.Unstructured{}); err != nil {
+		t.Fatal("Transform() =", err)
+	}
+
+	cond := status.GetCondition(Mirrored)
+	if cond == nil || !cond.IsFalse() || cond.Reason != "NoMirrorsConfigured" {
+		t.Errorf("Condition = %v, want it to be false with reason NoMirrorsConfigured", cond)
+	}
+}
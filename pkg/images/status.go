@@ -0,0 +1,48 @@
+package images
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+// OverridesValid is a Condition indicating whether all image overrides are valid and
+// used by at least one container or environment variable.
+const OverridesValid apis.ConditionType = "ImageOverridesValid"
+
+// condSet has no dependents, so the condition never affects readiness.
+var condSet = apis.NewLivingConditionSet()
+
+// MarkCondition reports the invalid and unused overrides on the given status. Unused
+// overrides have no effect, invalid ones fail the installation, see PolicyError.
+func MarkCondition(status apis.ConditionsAccessor, problems []Problem, unused []string) {
+	if len(problems) == 0 && len(unused) == 0 {
+		condSet.Manage(status).SetCondition(apis.Condition{
+			Type:     OverridesValid,
+			Status:   corev1.ConditionTrue,
+			Severity: apis.ConditionSeverityInfo,
+		})
+		return
+	}
+
+	var msgs []string
+	reason := "UnusedOverrides"
+	if len(problems) > 0 {
+		reason = "InvalidOverrides"
+		for _, problem := range problems {
+			msgs = append(msgs, problem.String())
+		}
+	}
+	if len(unused) > 0 {
+		msgs = append(msgs, fmt.Sprintf("unused overrides: %s", strings.Join(unused, ", ")))
+	}
+	condSet.Manage(status).SetCondition(apis.Condition{
+		Type:     OverridesValid,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   reason,
+		Message:  strings.Join(msgs, "; "),
+	})
+}
@@ -0,0 +1,64 @@
+package images
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// podSpecPaths are the paths to the PodSpec of the resources that carry one.
+var podSpecPaths = [][]string{
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// Unused returns the sorted keys of all overrides that match no container or
+// environment variable in the given resources. Known keys are consumed elsewhere and
+// thus never reported.
+func (c Catalog) Unused(resources []unstructured.Unstructured, known ...string) []string {
+	used := sets.NewString(known...)
+	used.Insert(DefaultKey)
+	for i := range resources {
+		addTargets(used, &resources[i])
+	}
+
+	var unused []string
+	for _, key := range c.keys() {
+		if !used.Has(key) {
+			unused = append(unused, key)
+		}
+	}
+	return unused
+}
+
+// addTargets adds all keys that would match a container or environment variable in the
+// given resource to the set.
+func addTargets(targets sets.String, u *unstructured.Unstructured) {
+	parent := u.GetName()
+	for _, path := range podSpecPaths {
+		spec, found, _ := unstructured.NestedMap(u.Object, path...)
+		if !found {
+			continue
+		}
+		for _, field := range []string{"containers", "initContainers"} {
+			containers, _, _ := unstructured.NestedSlice(spec, field)
+			for _, c := range containers {
+				container, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _, _ := unstructured.NestedString(container, "name")
+				targets.Insert(name, parent+"/"+name)
+
+				// Environment variables are only overridden by their name, the transformers
+				// ignore the parent for them.
+				env, _, _ := unstructured.NestedSlice(container, "env")
+				for _, e := range env {
+					if envVar, ok := e.(map[string]interface{}); ok {
+						name, _, _ := unstructured.NestedString(envVar, "name")
+						targets.Insert(name)
+					}
+				}
+			}
+		}
+	}
+}
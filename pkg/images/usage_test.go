@@ -0,0 +1,75 @@
+package images
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestUnused(t *testing.T) {
+	resources := []unstructured.Unstructured{{
+		Object: map[string]interface{}{
+			"kind":     "Deployment",
+			"metadata": map[string]interface{}{"name": "controller"},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"initContainers": []interface{}{
+							map[string]interface{}{"name": "init"},
+						},
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name": "controller",
+								"env": []interface{}{
+									map[string]interface{}{"name": "DISPATCHER_IMAGE"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, {
+		Object: map[string]interface{}{
+			"kind":     "CronJob",
+			"metadata": map[string]interface{}{"name": "cleanup"},
+			"spec": map[string]interface{}{
+				"jobTemplate": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"template": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"containers": []interface{}{
+									map[string]interface{}{"name": "cleanup"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, {
+		Object: map[string]interface{}{
+			"kind":     "ConfigMap",
+			"metadata": map[string]interface{}{"name": "config"},
+		},
+	}}
+
+	catalog := Catalog{
+		"default":                     "quay.io/${NAME}",
+		"controller":                  "quay.io/controller",
+		"controller/init":             "quay.io/init",
+		"DISPATCHER_IMAGE":            "quay.io/dispatcher",
+		"controller/DISPATCHER_IMAGE": "quay.io/dispatcher",
+		"cleanup/cleanup":             "quay.io/cleanup",
+		"queue-proxy":                 "quay.io/queue",
+		"controller/webhook":          "quay.io/webhook",
+		"webhook":                     "quay.io/webhook",
+		"config":                      "quay.io/config",
+	}
+
+	want := []string{"config", "controller/DISPATCHER_IMAGE", "controller/webhook", "webhook"}
+	if got := catalog.Unused(resources, "queue-proxy"); !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+}
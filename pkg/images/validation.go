@@ -0,0 +1,114 @@
+package images
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RequireDigestKey is the key in the catalog's ConfigMap that, if "true", rejects
+	// overrides that aren't pinned by digest.
+	RequireDigestKey = "require-digest"
+	// AllowedRegistriesKey is the key in the catalog's ConfigMap listing the registries or
+	// repository prefixes overrides may point to, separated by commas.
+	AllowedRegistriesKey = "allowed-registries"
+
+	// nameTemplate is the placeholder for the container name upstream allows in the
+	// default image.
+	nameTemplate = "${NAME}"
+)
+
+// Policy restricts which images may be used as overrides.
+type Policy struct {
+	// RequireDigest rejects images that aren't pinned by digest.
+	RequireDigest bool
+	// AllowedRegistries are the registries or repository prefixes images may be pulled
+	// from. Empty allows all registries.
+	AllowedRegistries []string
+}
+
+// PolicyFromConfigMap reads the policy from the passed ConfigMap, which might be nil.
+func PolicyFromConfigMap(cm *corev1.ConfigMap) Policy {
+	if cm == nil {
+		return Policy{}
+	}
+
+	policy := Policy{RequireDigest: strings.EqualFold(cm.Data[RequireDigestKey], "true")}
+	for _, registry := range strings.Split(cm.Data[AllowedRegistriesKey], ",") {
+		if registry = strings.TrimSpace(registry); registry != "" {
+			policy.AllowedRegistries = append(policy.AllowedRegistries, strings.TrimSuffix(registry, "/"))
+		}
+	}
+	return policy
+}
+
+// Problem describes an override that can't be used.
+type Problem struct {
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Key, p.Message)
+}
+
+// Validate checks all overrides in the catalog against the policy and returns the
+// problems found, sorted by key.
+func (c Catalog) Validate(policy Policy) []Problem {
+	var problems []Problem
+	for _, key := range c.keys() {
+		if err := policy.check(c[key]); err != nil {
+			problems = append(problems, Problem{Key: key, Message: err.Error()})
+		}
+	}
+	return problems
+}
+
+// check returns an error if the image doesn't satisfy the policy.
+func (p Policy) check(image string) error {
+	ref, err := name.ParseReference(strings.ReplaceAll(image, nameTemplate, "name"))
+	if err != nil {
+		return fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+
+	if _, pinned := ref.(name.Digest); p.RequireDigest && !pinned {
+		return fmt.Errorf("image %q is not pinned by digest", image)
+	}
+
+	if len(p.AllowedRegistries) == 0 {
+		return nil
+	}
+	repository := ref.Context().Name()
+	for _, allowed := range p.AllowedRegistries {
+		if ref.Context().RegistryStr() == allowed || strings.HasPrefix(repository, allowed+"/") || repository == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("image %q is not from an allowed registry", image)
+}
+
+// PolicyError returns an error listing the given problems or nil if there are none. The
+// installation must fail on such an error rather than fall back to the manifest's images,
+// which the policy is meant to forbid.
+func PolicyError(problems []Problem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		msgs = append(msgs, problem.String())
+	}
+	return fmt.Errorf("image overrides violate the image policy: %s", strings.Join(msgs, "; "))
+}
+
+// Keys returns the keys of the problematic overrides.
+func Keys(problems []Problem) []string {
+	keys := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		keys = append(keys, problem.Key)
+	}
+	return keys
+}
@@ -0,0 +1,90 @@
+package images
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const digest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+func TestPolicyFromConfigMap(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		Data: map[string]string{
+			RequireDigestKey:     "True",
+			AllowedRegistriesKey: "quay.io/openshift-knative/, registry.redhat.io,",
+		},
+	}
+
+	want := Policy{
+		RequireDigest:     true,
+		AllowedRegistries: []string{"quay.io/openshift-knative", "registry.redhat.io"},
+	}
+	if got := PolicyFromConfigMap(cm); !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+	if got := PolicyFromConfigMap(nil); !cmp.Equal(got, Policy{}) {
+		t.Errorf("Got = %v, want an empty policy", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		catalog  Catalog
+		policy   Policy
+		expected []Problem
+	}{{
+		name: "no policy",
+		catalog: Catalog{
+			"foo":     "quay.io/foo:latest",
+			"default": "quay.io/knative/${NAME}:v0.22",
+		},
+	}, {
+		name: "invalid reference",
+		catalog: Catalog{
+			"foo": "quay.io/Foo:latest",
+		},
+		expected: []Problem{{
+			Key:     "foo",
+			Message: `invalid image reference "quay.io/Foo:latest": could not parse reference: quay.io/Foo:latest`,
+		}},
+	}, {
+		name: "digest required",
+		catalog: Catalog{
+			"foo": "quay.io/foo:latest",
+			"bar": "quay.io/bar@" + digest,
+		},
+		policy: Policy{RequireDigest: true},
+		expected: []Problem{{
+			Key:     "foo",
+			Message: `image "quay.io/foo:latest" is not pinned by digest`,
+		}},
+	}, {
+		name: "allowed registries",
+		catalog: Catalog{
+			"a": "registry.redhat.io/foo@" + digest,
+			"b": "quay.io/openshift-knative/bar:v1",
+			"c": "quay.io/other/baz:v1",
+			"d": "busybox",
+		},
+		policy: Policy{AllowedRegistries: []string{"registry.redhat.io", "quay.io/openshift-knative"}},
+		expected: []Problem{{
+			Key:     "c",
+			Message: `image "quay.io/other/baz:v1" is not from an allowed registry`,
+		}, {
+			Key:     "d",
+			Message: `image "busybox" is not from an allowed registry`,
+		}},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.catalog.Validate(c.policy)
+			if !cmp.Equal(got, c.expected) {
+				t.Errorf("Got = %v, want: %v, diff:\n%s", got, c.expected, cmp.Diff(got, c.expected))
+			}
+		})
+	}
+}
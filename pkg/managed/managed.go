@@ -0,0 +1,151 @@
+package managed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+const (
+	// UnmanagedFieldsAnnotation lists the managed fields, separated by commas, the
+	// operators leave to the user. The fields are given by their path, like
+	// "spec.config.deployment.queueSidecarImage" or "spec.registry.override.*".
+	UnmanagedFieldsAnnotation = "operator.knative.openshift.io/unmanaged-fields"
+
+	// wildcard as the last element of a path matches all keys of a map.
+	wildcard = "*"
+
+	registryDefault  = "spec.registry.default"
+	registryOverride = "spec.registry.override."
+	config           = "spec.config."
+)
+
+var (
+	// ServingFields are the fields of KnativeServing the operators set on every reconcile.
+	ServingFields = []string{
+		registryDefault,
+		registryOverride + wildcard,
+		config + "domain." + wildcard,
+		config + "deployment.queueSidecarImage",
+		config + "observability.logging.revision-url-template",
+	}
+
+	// EventingFields are the fields of KnativeEventing the operators set on every reconcile.
+	EventingFields = []string{
+		registryDefault,
+		registryOverride + wildcard,
+	}
+)
+
+// Conflict is a value set by the user that the operator overwrote.
+type Conflict struct {
+	// Path is the path of the field.
+	Path string
+	// Value is the value set by the user.
+	Value string
+	// Managed is the value set by the operator.
+	Managed string
+	// Removed is true if the operator removed the field.
+	Removed bool
+}
+
+func (c Conflict) String() string {
+	if c.Removed {
+		return fmt.Sprintf("%s is managed by the operator, the value %q is removed", c.Path, c.Value)
+	}
+	return fmt.Sprintf("%s is managed by the operator, the value %q is replaced by %q", c.Path, c.Value, c.Managed)
+}
+
+// Unmanaged returns the fields the user opted out of through UnmanagedFieldsAnnotation.
+func Unmanaged(obj metav1.Object) sets.String {
+	unmanaged := sets.NewString()
+	for _, path := range strings.Split(obj.GetAnnotations()[UnmanagedFieldsAnnotation], ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			unmanaged.Insert(path)
+		}
+	}
+	return unmanaged
+}
+
+// Restore compares the spec set by the user to the spec after the operator set its managed
+// fields. It restores the user's values of the fields the user opted out of in the latter
+// and returns the conflicts of all others, sorted by path.
+func Restore(obj metav1.Object, user, spec *v1alpha1.CommonSpec, fields []string) []Conflict {
+	unmanaged := Unmanaged(obj)
+	managed := Values(spec, fields)
+
+	var conflicts []Conflict
+	for path, value := range Values(user, fields) {
+		current, found := managed[path]
+		if found && current == value {
+			continue
+		}
+		if matchesAny(unmanaged.List(), path) {
+			set(spec, path, value)
+			continue
+		}
+		conflicts = append(conflicts, Conflict{Path: path, Value: value, Managed: current, Removed: !found})
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+	return conflicts
+}
+
+// Values returns the values of the given fields in the spec, keyed by path.
+func Values(spec *v1alpha1.CommonSpec, fields []string) map[string]string {
+	values := make(map[string]string)
+	add := func(path, value string) {
+		if matchesAny(fields, path) {
+			values[path] = value
+		}
+	}
+
+	if spec.Registry.Default != "" {
+		add(registryDefault, spec.Registry.Default)
+	}
+	for container, image := range spec.Registry.Override {
+		add(registryOverride+container, image)
+	}
+	for cm, data := range spec.Config {
+		for key, value := range data {
+			add(config+cm+"."+key, value)
+		}
+	}
+	return values
+}
+
+// set sets the field of the given path in the spec.
+func set(spec *v1alpha1.CommonSpec, path, value string) {
+	switch {
+	case path == registryDefault:
+		spec.Registry.Default = value
+	case strings.HasPrefix(path, registryOverride):
+		if spec.Registry.Override == nil {
+			spec.Registry.Override = make(map[string]string, 1)
+		}
+		spec.Registry.Override[strings.TrimPrefix(path, registryOverride)] = value
+	case strings.HasPrefix(path, config):
+		// ConfigMap names don't contain dots, but their keys might.
+		parts := strings.SplitN(strings.TrimPrefix(path, config), ".", 2)
+		if spec.Config == nil {
+			spec.Config = make(map[string]map[string]string, 1)
+		}
+		if spec.Config[parts[0]] == nil {
+			spec.Config[parts[0]] = make(map[string]string, 1)
+		}
+		spec.Config[parts[0]][parts[1]] = value
+	}
+}
+
+// matchesAny returns true if any of the patterns matches the given path.
+func matchesAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if pattern == path || (strings.HasSuffix(pattern, "."+wildcard) && strings.HasPrefix(path, strings.TrimSuffix(pattern, wildcard))) {
+			return true
+		}
+	}
+	return false
+}
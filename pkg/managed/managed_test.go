@@ -0,0 +1,110 @@
+package managed
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestRestore(t *testing.T) {
+	// mutate sets the managed fields like the operators do.
+	mutate := func(spec *v1alpha1.CommonSpec) {
+		spec.Registry.Override = map[string]string{"queue-proxy": "operator"}
+		set(spec, "spec.config.domain.apps.example.com", "")
+		set(spec, "spec.config.deployment.queueSidecarImage", "operator")
+	}
+
+	cases := []struct {
+		name      string
+		unmanaged string
+		user      v1alpha1.CommonSpec
+		want      []Conflict
+		wantSpec  v1alpha1.CommonSpec
+	}{{
+		name: "nothing set",
+		wantSpec: v1alpha1.CommonSpec{
+			Registry: v1alpha1.Registry{Override: map[string]string{"queue-proxy": "operator"}},
+			Config: v1alpha1.ConfigMapData{
+				"domain":     {"apps.example.com": ""},
+				"deployment": {"queueSidecarImage": "operator"},
+			},
+		},
+	}, {
+		name: "conflicts",
+		user: v1alpha1.CommonSpec{
+			Registry: v1alpha1.Registry{Override: map[string]string{"activator": "mine"}},
+			Config: v1alpha1.ConfigMapData{
+				"domain":     {"apps.example.com": "selector:\n  app: foo", "other.example.com": ""},
+				"deployment": {"queueSidecarImage": "operator"},
+			},
+		},
+		want: []Conflict{{
+			Path:    "spec.config.domain.apps.example.com",
+			Value:   "selector:\n  app: foo",
+			Managed: "",
+		}, {
+			Path:    "spec.registry.override.activator",
+			Value:   "mine",
+			Removed: true,
+		}},
+		wantSpec: v1alpha1.CommonSpec{
+			Registry: v1alpha1.Registry{Override: map[string]string{"queue-proxy": "operator"}},
+			Config: v1alpha1.ConfigMapData{
+				"domain":     {"apps.example.com": "", "other.example.com": ""},
+				"deployment": {"queueSidecarImage": "operator"},
+			},
+		},
+	}, {
+		name:      "unmanaged",
+		unmanaged: "spec.registry.override.*, spec.config.domain.apps.example.com",
+		user: v1alpha1.CommonSpec{
+			Registry: v1alpha1.Registry{Override: map[string]string{"activator": "mine"}},
+			Config: v1alpha1.ConfigMapData{
+				"domain":     {"apps.example.com": "selector:\n  app: foo"},
+				"deployment": {"queueSidecarImage": "mine"},
+			},
+		},
+		want: []Conflict{{
+			Path:    "spec.config.deployment.queueSidecarImage",
+			Value:   "mine",
+			Managed: "operator",
+		}},
+		wantSpec: v1alpha1.CommonSpec{
+			Registry: v1alpha1.Registry{Override: map[string]string{"queue-proxy": "operator", "activator": "mine"}},
+			Config: v1alpha1.ConfigMapData{
+				"domain":     {"apps.example.com": "selector:\n  app: foo"},
+				"deployment": {"queueSidecarImage": "operator"},
+			},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			obj := &v1alpha1.KnativeServing{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{UnmanagedFieldsAnnotation: c.unmanaged}},
+				Spec:       v1alpha1.KnativeServingSpec{CommonSpec: *c.user.DeepCopy()},
+			}
+			mutate(&obj.Spec.CommonSpec)
+
+			got := Restore(obj, &c.user, &obj.Spec.CommonSpec, ServingFields)
+			if !cmp.Equal(got, c.want) {
+				t.Errorf("Got = %v, want: %v, diff:\n%s", got, c.want, cmp.Diff(got, c.want))
+			}
+			if !cmp.Equal(obj.Spec.CommonSpec, c.wantSpec) {
+				t.Errorf("Got spec = %v, want: %v, diff:\n%s", obj.Spec.CommonSpec, c.wantSpec, cmp.Diff(obj.Spec.CommonSpec, c.wantSpec))
+			}
+		})
+	}
+}
+
+func TestUnmanaged(t *testing.T) {
+	obj := &metav1.ObjectMeta{Annotations: map[string]string{
+		UnmanagedFieldsAnnotation: " spec.registry.default,,spec.config.domain.* ",
+	}}
+	want := []string{"spec.config.domain.*", "spec.registry.default"}
+	if got := Unmanaged(obj).List(); !cmp.Equal(got, want) {
+		t.Errorf("Got = %v, want: %v", got, want)
+	}
+}